@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/Francouer/proto-sync/internal/app"
+	"github.com/Francouer/proto-sync/internal/domain"
 	"github.com/Francouer/proto-sync/internal/infrastructure"
 	interfaces "github.com/Francouer/proto-sync/internal/interface"
 )
@@ -24,23 +26,74 @@ func main() {
 		cancel()
 	}()
 
+	// Switch directories before any relative path is resolved
+	workDir, err := interfaces.ChangeWorkDir(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(interfaces.ExitUsage)
+	}
+
 	// Initialize dependencies
-	logger := infrastructure.NewColorLogger()
+	logFormat, err := interfaces.LogFormat(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(interfaces.ExitUsage)
+	}
+	logLevel, err := interfaces.LogLevel(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(interfaces.ExitUsage)
+	}
+	logger := infrastructure.NewColorLogger(logLevel)
+	if logFormat == interfaces.LogFormatJSON {
+		logger = infrastructure.NewJSONLogger(os.Stderr, logLevel)
+	}
+
+	// Tee logs into --log-file; a log file that can't be opened is reported
+	// but doesn't stop the sync
+	var logFile *os.File
+	if path := interfaces.LogFile(os.Args[1:]); path != "" {
+		logFile, err = infrastructure.OpenLogFile(path, infrastructure.NewFileRepository(logger))
+		if err != nil {
+			logger.Warning("%v", err)
+		} else {
+			defer logFile.Close()
+			fileLogger := infrastructure.NewPlainLogger(logFile, logLevel)
+			if logFormat == interfaces.LogFormatJSON {
+				fileLogger = infrastructure.NewJSONLogger(logFile, logLevel)
+			}
+			logger = infrastructure.NewMultiLogger(logger, fileLogger)
+		}
+	}
+
+	if workDir != "" {
+		logger.Info("Working directory: %s", workDir)
+	}
+
 	fileRepo := infrastructure.NewFileRepository(logger)
-	goModRepo := infrastructure.NewGoModRepository(logger)
+	goModRepo := infrastructure.NewGoModRepository(logger, infrastructure.NewCommandRunner(logger), nil)
 	bufRepo := infrastructure.NewBufRepository(logger, fileRepo)
+	shellRunner := infrastructure.NewShellRunner(logger)
 
 	// Initialize application service
-	protoSyncService := app.NewProtoSyncService(logger, fileRepo, goModRepo, bufRepo)
+	protoSyncService := app.NewProtoSyncService(logger, fileRepo, goModRepo, bufRepo, shellRunner)
 
 	// Initialize CLI handler
 	cliHandler := interfaces.NewCLIHandler(protoSyncService, logger)
+	if logFile != nil {
+		cliHandler.SetLogFile(logFile)
+	}
+	if infrastructure.IsTerminal(os.Stderr) && logFormat == interfaces.LogFormatText && logLevel.Enables(domain.LogLevelInfo) {
+		cliHandler.SetTerminalProgress(infrastructure.NewTerminalProgress(os.Stderr))
+	}
 
 	// Create root command and execute
 	rootCmd := cliHandler.CreateRootCommand()
 
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
-		logger.Error("Application failed: %v", err)
-		os.Exit(1)
+		if !interfaces.IsSilentExit(err) {
+			logger.Error("Application failed: %v", err)
+		}
+		os.Exit(interfaces.ExitCode(err))
 	}
 }
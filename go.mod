@@ -5,7 +5,9 @@ go 1.21
 require (
 	github.com/fatih/color v1.16.0
 	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/mod v0.14.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -15,6 +17,5 @@ require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 )
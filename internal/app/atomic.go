@@ -0,0 +1,197 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// stagedTarget is the directory --atomic copies into instead of a target
+type stagedTarget struct {
+	// target is the target as configured, dir the directory swapped, which
+	// differs when the target is a symlink
+	target  string
+	dir     string
+	staging string
+}
+
+// stageTargets creates a staging directory beside every target of
+// repositories, seeded with the target's current content so files no
+// repository provides survive the swap
+func (p *ProtoSyncServiceImpl) stageTargets(run *syncRun, repositories []domain.Repository) error {
+	var targets []string
+	seen := make(map[string]bool)
+	for _, repo := range repositories {
+		for _, target := range targetPaths(run.config, repo) {
+			target = filepath.Clean(target)
+			if !seen[target] {
+				seen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+
+	// Swapping a target would move the staged content of any target
+	// inside it
+	for i, a := range targets {
+		for _, b := range targets[i+1:] {
+			if isWithin(a, b) || isWithin(b, a) {
+				return domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("--atomic can't swap nested targets %s and %s", a, b))
+			}
+		}
+	}
+
+	for _, target := range targets {
+		staged, err := p.stageTarget(target)
+		if err != nil {
+			p.discardStaging(run)
+			return err
+		}
+		run.staged = append(run.staged, staged)
+	}
+	return nil
+}
+
+func (p *ProtoSyncServiceImpl) stageTarget(target string) (stagedTarget, error) {
+	staged := stagedTarget{target: target, dir: target}
+	exists := p.fileRepo.FileExists(target)
+	if exists {
+		dir, err := p.fileRepo.ResolvePath(target)
+		if err != nil {
+			return staged, err
+		}
+		staged.dir = dir
+	}
+
+	parent := filepath.Dir(staged.dir)
+	if err := p.fileRepo.CreateDir(parent); err != nil {
+		return staged, fmt.Errorf("failed to create directory %s: %w", parent, err)
+	}
+	staging, err := p.fileRepo.TempDir(parent, "."+filepath.Base(staged.dir)+".proto-sync-")
+	if err != nil {
+		return staged, err
+	}
+	staged.staging = staging
+
+	if exists {
+		if err := p.fileRepo.CopyDir(staged.dir, staging); err != nil {
+			_ = p.fileRepo.RemoveAll(staging)
+			return staged, fmt.Errorf("failed to stage %s: %w", target, err)
+		}
+	}
+	p.logger.Debug("Staging %s in %s", target, staging)
+	return staged, nil
+}
+
+// finishAtomic swaps the staged targets into place when every repository
+// synced. Otherwise the staging directories are removed and every result
+// is marked as not applied, since no target changed.
+func (p *ProtoSyncServiceImpl) finishAtomic(run *syncRun, results []domain.SyncResult) error {
+	failed := false
+	for i := range results {
+		failed = failed || !results[i].Success
+		for j := range results[i].FilesUpdated {
+			file := &results[i].FilesUpdated[j]
+			file.Path = run.realPath(file.Path)
+			for k, target := range file.Targets {
+				file.Targets[k] = run.realPath(target)
+			}
+		}
+	}
+
+	if !failed {
+		return p.swapStaging(run)
+	}
+
+	p.discardStaging(run)
+	p.logger.Warning("Leaving every target unchanged because not every repository synced (--atomic)")
+	for i := range results {
+		results[i].FilesUpdated = nil
+		if results[i].Success {
+			results[i].Success = false
+			results[i].Error = domain.WithCode(domain.ErrorCodeCancelled, errors.New("not applied: --atomic leaves every target unchanged when a repository fails"))
+		}
+	}
+	// Nothing was written, so neither the recorded hashes nor the backups
+	// of this run exist on disk
+	run.editState = nil
+	run.backupDirs = make(map[string]struct{})
+	return nil
+}
+
+// swapStaging renames every staging directory over its target, moving the
+// old target aside first. A failed rename puts back the targets already
+// swapped, so the targets change together or not at all.
+func (p *ProtoSyncServiceImpl) swapStaging(run *syncRun) error {
+	type swap struct {
+		staged stagedTarget
+		old    string
+	}
+	var done []swap
+	undo := func() {
+		for i := len(done) - 1; i >= 0; i-- {
+			if err := p.restoreTarget(done[i].staged, done[i].old); err != nil {
+				p.logger.Warning("%v", err)
+			}
+		}
+		p.discardStaging(run)
+	}
+
+	for _, staged := range run.staged {
+		old := ""
+		if p.fileRepo.FileExists(staged.dir) {
+			old = staged.staging + ".old"
+			if err := p.fileRepo.MoveDir(staged.dir, old); err != nil {
+				undo()
+				return fmt.Errorf("failed to move %s aside: %w", staged.target, err)
+			}
+		}
+		if err := p.fileRepo.MoveDir(staged.staging, staged.dir); err != nil {
+			if old != "" {
+				if err := p.fileRepo.MoveDir(old, staged.dir); err != nil {
+					p.logger.Warning("Failed to restore %s, its previous content is in %s: %v", staged.target, old, err)
+				}
+			}
+			undo()
+			return fmt.Errorf("failed to swap %s into place: %w", staged.target, err)
+		}
+		done = append(done, swap{staged: staged, old: old})
+	}
+
+	for _, s := range done {
+		if s.old == "" {
+			continue
+		}
+		if err := p.fileRepo.RemoveAll(s.old); err != nil {
+			p.logger.Warning("Failed to remove previous content of %s: %v", s.staged.target, err)
+		}
+	}
+	p.logger.Info("Swapped %d staged target(s) into place", len(done))
+	return nil
+}
+
+// restoreTarget undoes the swap of staged, moving the staged content back
+// and the old content, when there was any, into place
+func (p *ProtoSyncServiceImpl) restoreTarget(staged stagedTarget, old string) error {
+	if err := p.fileRepo.MoveDir(staged.dir, staged.staging); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", staged.target, err)
+	}
+	if old == "" {
+		return nil
+	}
+	if err := p.fileRepo.MoveDir(old, staged.dir); err != nil {
+		return fmt.Errorf("failed to restore %s, its previous content is in %s: %w", staged.target, old, err)
+	}
+	return nil
+}
+
+// discardStaging removes the staging directories of run
+func (p *ProtoSyncServiceImpl) discardStaging(run *syncRun) {
+	for _, staged := range run.staged {
+		if err := p.fileRepo.RemoveAll(staged.staging); err != nil {
+			p.logger.Warning("Failed to remove staging directory: %v", err)
+		}
+	}
+}
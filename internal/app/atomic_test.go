@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicSync(t *testing.T) {
+	dir := t.TempDir()
+	localDir := filepath.Join(dir, "api")
+	writeFile(t, filepath.Join(localDir, "schemas", "a.proto"), "new")
+	target := filepath.Join(dir, "proto")
+	writeFile(t, filepath.Join(target, "a.proto"), "old")
+	writeFile(t, filepath.Join(target, "keep.txt"), "local")
+
+	service := newDiskService(&fakeGoModRepo{})
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: target, Atomic: true}
+	ok := domain.Repository{Name: "github.com/example/api", Version: "v1.0.0", LocalPath: localDir}
+	broken := domain.Repository{Name: "github.com/example/broken", Version: "v1.0.0", LocalPath: filepath.Join(dir, "missing")}
+
+	sync := func(repositories ...domain.Repository) []domain.SyncResult {
+		run := newSyncRun(config)
+		require.NoError(t, service.stageTargets(run, repositories))
+		results := service.processRepositories(context.Background(), run, repositories)
+		require.NoError(t, service.finishAtomic(run, results))
+
+		staging, err := filepath.Glob(filepath.Join(dir, ".proto.proto-sync-*"))
+		require.NoError(t, err)
+		assert.Empty(t, staging)
+		return results
+	}
+
+	results := sync(ok)
+	require.True(t, results[0].Success)
+	require.Len(t, results[0].FilesUpdated, 1)
+	assert.Equal(t, filepath.Join(target, "a.proto"), results[0].FilesUpdated[0].Path)
+	assert.Equal(t, []string{target}, results[0].FilesUpdated[0].Targets)
+	assert.Equal(t, "new", readFile(t, filepath.Join(target, "a.proto")))
+	assert.Equal(t, "local", readFile(t, filepath.Join(target, "keep.txt")))
+
+	// A failing repository leaves the target as it was
+	writeFile(t, filepath.Join(localDir, "schemas", "a.proto"), "newer")
+	results = sync(ok, broken)
+	assert.False(t, results[0].Success)
+	assert.Empty(t, results[0].FilesUpdated)
+	assert.ErrorContains(t, results[0].Error, "not applied: --atomic")
+	assert.Equal(t, "new", readFile(t, filepath.Join(target, "a.proto")))
+}
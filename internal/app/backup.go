@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// backupDirName is the directory below each target that holds backups
+const backupDirName = ".proto-sync-backup"
+
+// backupSuffix is appended to backed up files so buf, which builds every
+// .proto below the module root, doesn't see duplicate definitions
+const backupSuffix = ".bak"
+
+// backupStampLayout names backup directories. Its fixed-width nanoseconds
+// keep runs in the same second apart and sort lexically by time.
+const backupStampLayout = "20060102-150405.000000000"
+
+// backupFile copies the current target/name into this run's backup
+// directory when data is about to replace different content
+func (p *ProtoSyncServiceImpl) backupFile(run *syncRun, target, name string, data []byte) error {
+	targetFile := filepath.Join(target, name)
+	if p.classifyContent(data, targetFile) != domain.ChangeModified {
+		return nil
+	}
+
+	backupDir := filepath.Join(target, backupDirName, run.backupStamp)
+	if err := p.fileRepo.CreateDir(backupDir); err != nil {
+		return fmt.Errorf("failed to create backup directory %s: %w", backupDir, err)
+	}
+	if err := p.fileRepo.CopyFile(targetFile, filepath.Join(backupDir, name+backupSuffix)); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", targetFile, err)
+	}
+
+	p.logger.Debug("Backed up %s to %s", targetFile, backupDir)
+	run.recordBackup(backupDir)
+	return nil
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupReplacedFiles(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src")
+	target := filepath.Join(dir, "dst")
+	writeFile(t, filepath.Join(source, "changed.proto"), "package new;")
+	writeFile(t, filepath.Join(source, "same.proto"), "package same;")
+	writeFile(t, filepath.Join(source, "added.proto"), "package added;")
+	writeFile(t, filepath.Join(target, "changed.proto"), "package old;")
+	writeFile(t, filepath.Join(target, "same.proto"), "package same;")
+
+	service := newDiskService(nil)
+	run := newSyncRun(&domain.SyncConfig{Backup: true})
+
+	_, err := service.copyAllProtoFiles(context.Background(), run, source, target)
+	require.NoError(t, err)
+
+	backupDir := filepath.Join(target, backupDirName, run.backupStamp)
+	assert.Equal(t, []string{backupDir}, run.backups())
+
+	data, err := os.ReadFile(filepath.Join(backupDir, "changed.proto"+backupSuffix))
+	require.NoError(t, err)
+	assert.Equal(t, "package old;", string(data))
+	assert.NoFileExists(t, filepath.Join(backupDir, "changed.proto"))
+	assert.NoFileExists(t, filepath.Join(backupDir, "same.proto"+backupSuffix))
+	assert.NoFileExists(t, filepath.Join(backupDir, "added.proto"+backupSuffix))
+}
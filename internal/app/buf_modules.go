@@ -0,0 +1,161 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// majorVersionElement matches the /vN suffix of a Go module path
+var majorVersionElement = regexp.MustCompile(`^v[0-9]+$`)
+
+// applyDefaultModulePath fills in the path of modules from buf v1 files,
+// which live at the root of the module they describe. That directory is
+// usually the repository root, so commands that delete files from the
+// target (deletes) require --default-module-path instead.
+func (p *ProtoSyncServiceImpl) applyDefaultModulePath(modules []domain.ModuleInfo, config *domain.SyncConfig, deletes bool) error {
+	for i := range modules {
+		if modules[i].Path != "" {
+			continue
+		}
+		modules[i].Path = config.DefaultModulePath
+		if modules[i].Path == "" {
+			if deletes {
+				return domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("%s has no module path (buf v1); refusing to delete files from %s, pass --target or --default-module-path", config.BufYamlPath, filepath.Dir(config.BufYamlPath)))
+			}
+			modules[i].Path = filepath.Dir(config.BufYamlPath)
+		}
+		p.logger.Info("%s has no module path (buf v1), using %s", config.BufYamlPath, modules[i].Path)
+	}
+	return nil
+}
+
+// selectConfiguredModule returns the buf.yaml module every repository syncs
+// into: the one named by --module, or the only module declared. It returns
+// nil when buf.yaml has several modules and each repository must be matched
+// on its own.
+func selectConfiguredModule(modules []domain.ModuleInfo, config *domain.SyncConfig) (*domain.ModuleInfo, int, error) {
+	if config.Module != "" {
+		for i := range modules {
+			if modules[i].Name == config.Module || filepath.Clean(modules[i].Path) == filepath.Clean(config.Module) {
+				return &modules[i], i, nil
+			}
+		}
+		return nil, -1, fmt.Errorf("module %q not found in %s (available: %s)", config.Module, config.BufYamlPath, describeModules(modules))
+	}
+
+	if len(modules) == 1 {
+		return &modules[0], 0, nil
+	}
+	return nil, -1, nil
+}
+
+// assignModules sets the target path of every repository without its own
+// target to the buf.yaml module that best matches its module path
+func (p *ProtoSyncServiceImpl) assignModules(repositories []domain.Repository, modules []domain.ModuleInfo, bufYamlPath string) error {
+	validated := make(map[int]bool)
+	for i := range repositories {
+		if repositories[i].TargetPath != "" {
+			p.logger.Info("Target path for %s: %s", repositories[i].Name, repositories[i].TargetPath)
+			continue
+		}
+
+		index, err := matchModule(repositories[i].Name, modules)
+		if err != nil {
+			return fmt.Errorf("%w in %s; use --module to choose one", err, bufYamlPath)
+		}
+
+		if !validated[index] {
+			if err := p.validateTargetPath(modules[index].Path, bufYamlPath, index); err != nil {
+				return err
+			}
+			validated[index] = true
+		}
+
+		repositories[i].TargetPath = modules[index].Path
+		p.logger.Info("Target path for %s from %s: %s", repositories[i].Name, bufYamlPath, modules[index].Path)
+	}
+	return nil
+}
+
+// matchModule returns the index of the module whose name or path shares
+// the longest element with the last element of repo, e.g. proto/product
+// for github.com/org/product-api
+func matchModule(repo string, modules []domain.ModuleInfo) (int, error) {
+	repoKey := normalizeModuleKey(repoBaseName(repo))
+
+	best, bestScore, tied := -1, 0, false
+	for i, module := range modules {
+		score := 0
+		for _, key := range moduleKeys(module) {
+			if key != "" && strings.Contains(repoKey, key) && len(key) > score {
+				score = len(key)
+			}
+		}
+
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = i, score, false
+		case score == bestScore && score > 0:
+			tied = true
+		}
+	}
+
+	if best < 0 || tied {
+		return -1, fmt.Errorf("cannot tell which module %s belongs to (available: %s)", repo, describeModules(modules))
+	}
+	return best, nil
+}
+
+// repoBaseName returns the last element of a module path, skipping a
+// major version suffix
+func repoBaseName(repo string) string {
+	base := path.Base(repo)
+	if majorVersionElement.MatchString(base) {
+		base = path.Base(path.Dir(repo))
+	}
+	return base
+}
+
+// moduleKeys returns the normalized elements of a module's path and the
+// last element of its name
+func moduleKeys(module domain.ModuleInfo) []string {
+	var keys []string
+	for _, element := range strings.Split(filepath.ToSlash(module.Path), "/") {
+		if !majorVersionElement.MatchString(element) {
+			keys = append(keys, normalizeModuleKey(element))
+		}
+	}
+	if module.Name != "" {
+		keys = append(keys, normalizeModuleKey(path.Base(module.Name)))
+	}
+	return keys
+}
+
+// normalizeModuleKey lowercases s and drops everything but letters and
+// digits, so product-api and product_api compare equal
+func normalizeModuleKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func describeModules(modules []domain.ModuleInfo) string {
+	descriptions := make([]string, 0, len(modules))
+	for _, module := range modules {
+		if module.Name != "" {
+			descriptions = append(descriptions, fmt.Sprintf("%s (%s)", module.Path, module.Name))
+		} else {
+			descriptions = append(descriptions, module.Path)
+		}
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchModule(t *testing.T) {
+	modules := []domain.ModuleInfo{
+		{Path: "proto/product"},
+		{Path: "proto/user", Name: "buf.build/example/user-api"},
+		{Path: "third_party/orders"},
+	}
+
+	tests := []struct {
+		repo string
+		want int
+	}{
+		{repo: "github.com/example/product-api", want: 0},
+		{repo: "github.com/example/user-api/v2", want: 1},
+		{repo: "github.com/example/orders_protos", want: 2},
+	}
+	for _, tt := range tests {
+		got, err := matchModule(tt.repo, modules)
+		require.NoError(t, err, tt.repo)
+		assert.Equal(t, tt.want, got, tt.repo)
+	}
+
+	_, err := matchModule("github.com/example/billing-api", modules)
+	assert.Error(t, err)
+
+	// Both modules share the same element, so the match is ambiguous
+	_, err = matchModule("github.com/example/api", []domain.ModuleInfo{{Path: "a/api"}, {Path: "b/api"}})
+	assert.Error(t, err)
+}
+
+func TestSelectConfiguredModule(t *testing.T) {
+	modules := []domain.ModuleInfo{
+		{Path: "proto/product", Name: "buf.build/example/product-api"},
+		{Path: "proto/user"},
+	}
+
+	module, index, err := selectConfiguredModule(modules, &domain.SyncConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, module)
+	assert.Equal(t, -1, index)
+
+	module, index, err = selectConfiguredModule(modules, &domain.SyncConfig{Module: "proto/user/"})
+	require.NoError(t, err)
+	assert.Equal(t, "proto/user", module.Path)
+	assert.Equal(t, 1, index)
+
+	module, _, err = selectConfiguredModule(modules, &domain.SyncConfig{Module: "buf.build/example/product-api"})
+	require.NoError(t, err)
+	assert.Equal(t, "proto/product", module.Path)
+
+	_, _, err = selectConfiguredModule(modules, &domain.SyncConfig{Module: "proto/missing"})
+	assert.Error(t, err)
+
+	module, index, err = selectConfiguredModule(modules[1:], &domain.SyncConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "proto/user", module.Path)
+	assert.Equal(t, 0, index)
+}
+
+func TestApplyDefaultModulePath(t *testing.T) {
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}}
+
+	modules := []domain.ModuleInfo{{Name: "buf.build/example/api"}}
+	require.NoError(t, service.applyDefaultModulePath(modules, &domain.SyncConfig{BufYamlPath: filepath.Join("api", "buf.yaml")}, false))
+	assert.Equal(t, "api", modules[0].Path)
+
+	modules = []domain.ModuleInfo{{Name: "buf.build/example/api"}, {Path: "proto"}}
+	require.NoError(t, service.applyDefaultModulePath(modules, &domain.SyncConfig{BufYamlPath: "buf.yaml", DefaultModulePath: "third_party"}, true))
+	assert.Equal(t, "third_party", modules[0].Path)
+	assert.Equal(t, "proto", modules[1].Path)
+
+	// The buf.yaml directory is never a default target for deleting files
+	modules = []domain.ModuleInfo{{Name: "buf.build/example/api"}}
+	err := service.applyDefaultModulePath(modules, &domain.SyncConfig{BufYamlPath: "buf.yaml"}, true)
+	assert.ErrorIs(t, err, domain.ErrInvalidConfig)
+	assert.ErrorContains(t, err, "--default-module-path")
+}
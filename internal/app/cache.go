@@ -0,0 +1,149 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// CacheInfo reports the size and module@version entries of the file cache
+func (p *ProtoSyncServiceImpl) CacheInfo(cacheDir string) (*domain.CacheInfo, error) {
+	if err := p.checkOwnCacheDir(cacheDir); err != nil {
+		return nil, err
+	}
+
+	info := &domain.CacheInfo{Path: cacheDir}
+	if !p.fileRepo.FileExists(cacheDir) {
+		return info, nil
+	}
+
+	size, count, err := p.fileRepo.DirSize(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure cache %s: %w", cacheDir, err)
+	}
+	info.SizeBytes = size
+	info.FileCount = count
+
+	markers, err := p.fileRepo.ListFiles(cacheDir, "*"+fileCacheMarker, domain.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache %s: %w", cacheDir, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, marker := range markers {
+		rel, err := filepath.Rel(cacheDir, strings.TrimSuffix(marker.Path, fileCacheMarker))
+		if err != nil {
+			continue
+		}
+		if entry := moduleVersionPrefix(rel); entry != "" && !seen[entry] {
+			seen[entry] = true
+			info.Entries = append(info.Entries, entry)
+		}
+	}
+	sort.Strings(info.Entries)
+
+	return info, nil
+}
+
+// CleanCache removes the file cache directory, refusing directories that
+// don't look like a proto-sync cache
+func (p *ProtoSyncServiceImpl) CleanCache(cacheDir string) error {
+	if err := p.checkOwnCacheDir(cacheDir); err != nil {
+		return err
+	}
+
+	if !p.fileRepo.FileExists(cacheDir) {
+		p.logger.Info("Cache %s does not exist, nothing to clean", cacheDir)
+		return nil
+	}
+
+	if err := p.checkIsFileCache(cacheDir); err != nil {
+		return err
+	}
+
+	if err := p.fileRepo.RemoveAll(cacheDir); err != nil {
+		return err
+	}
+
+	p.logger.Success("Removed cache %s", cacheDir)
+	return nil
+}
+
+// checkOwnCacheDir refuses to operate on GOMODCACHE, or on any directory that
+// contains it or is contained by it, so cache commands never touch Go's
+// shared module cache
+func (p *ProtoSyncServiceImpl) checkOwnCacheDir(cacheDir string) error {
+	if cacheDir == "" {
+		return fmt.Errorf("no cache directory configured, use --file-cache-dir or PROTO_SYNC_FILE_CACHE_DIR")
+	}
+
+	modCache, err := p.goModRepo.GetModCacheDir()
+	if err != nil {
+		return fmt.Errorf("failed to resolve GOMODCACHE: %w", err)
+	}
+
+	cachePath := cacheDir
+	if resolved, err := p.fileRepo.ResolvePath(cacheDir); err == nil {
+		cachePath = resolved
+	}
+	if resolved, err := p.fileRepo.ResolvePath(modCache); err == nil {
+		modCache = resolved
+	}
+
+	if isWithin(modCache, cachePath) || isWithin(cachePath, modCache) {
+		return fmt.Errorf("refusing to use %s as proto-sync cache: it overlaps GOMODCACHE %s", cacheDir, modCache)
+	}
+
+	return nil
+}
+
+// checkIsFileCache fails unless cacheDir holds the cache sentinel or, for
+// caches written before the sentinel existed, only module@version entries
+// with at least one completion marker, so a mistyped --file-cache-dir such
+// as . or ~ is never removed
+func (p *ProtoSyncServiceImpl) checkIsFileCache(cacheDir string) error {
+	if p.fileRepo.FileExists(filepath.Join(cacheDir, fileCacheSentinel)) {
+		return nil
+	}
+	if !p.fileRepo.IsDir(cacheDir) {
+		return fmt.Errorf("refusing to clean %s: not a directory", cacheDir)
+	}
+
+	files, err := p.fileRepo.ListFiles(cacheDir, "*", domain.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list cache %s: %w", cacheDir, err)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	markers := 0
+	for _, file := range files {
+		rel, err := filepath.Rel(cacheDir, file.Path)
+		if err != nil || moduleVersionPrefix(rel) == "" {
+			return fmt.Errorf("refusing to clean %s: it doesn't look like a proto-sync cache (%s is not a module@version entry)", cacheDir, file.Path)
+		}
+		if strings.HasSuffix(rel, fileCacheMarker) {
+			markers++
+		}
+	}
+	if markers == 0 {
+		return fmt.Errorf("refusing to clean %s: it doesn't look like a proto-sync cache (no %s markers)", cacheDir, fileCacheMarker)
+	}
+	return nil
+}
+
+// moduleVersionPrefix returns the leading path segments of rel up to and
+// including the first one carrying an @version suffix
+func moduleVersionPrefix(rel string) string {
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		if strings.Contains(segment, "@") {
+			return strings.Join(segments[:i+1], "/")
+		}
+	}
+	return ""
+}
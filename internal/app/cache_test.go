@@ -0,0 +1,72 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheInfoAndClean(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "proto-cache")
+	writeFile(t, filepath.Join(cacheDir, "github.com/example/api@v1.0.0", "schemas", "a.proto"), "abc")
+	writeFile(t, filepath.Join(cacheDir, "github.com/example/api@v1.0.0", "schemas"+fileCacheMarker), "")
+	writeFile(t, filepath.Join(cacheDir, "github.com/example/root@v2.0.0"+fileCacheMarker), "")
+	writeFile(t, filepath.Join(cacheDir, "github.com/example/partial@v0.1.0", "b.proto"), "de")
+
+	service := newDiskService(&fakeGoModRepo{moduleDir: filepath.Join(dir, "gomodcache", "mod@v1")})
+
+	info, err := service.CacheInfo(cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), info.SizeBytes)
+	assert.Equal(t, 4, info.FileCount)
+	assert.Equal(t, []string{"github.com/example/api@v1.0.0", "github.com/example/root@v2.0.0"}, info.Entries)
+
+	require.NoError(t, service.CleanCache(cacheDir))
+	assert.NoDirExists(t, cacheDir)
+}
+
+func TestCleanCacheRefusesGoModCache(t *testing.T) {
+	dir := t.TempDir()
+	modCache := filepath.Join(dir, "gomodcache")
+	writeFile(t, filepath.Join(modCache, "keep.txt"), "x")
+
+	service := newDiskService(&fakeGoModRepo{moduleDir: filepath.Join(modCache, "mod@v1")})
+
+	assert.Error(t, service.CleanCache(modCache))
+	assert.Error(t, service.CleanCache(dir))
+	assert.Error(t, service.CleanCache(filepath.Join(modCache, "cache")))
+	assert.FileExists(t, filepath.Join(modCache, "keep.txt"))
+}
+
+func TestCleanCacheRefusesOtherDirectories(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	writeFile(t, filepath.Join(project, "go.mod"), "module example.com/project\n")
+	writeFile(t, filepath.Join(project, "github.com/example/api@v1.0.0", "a.proto"), "a")
+
+	service := newDiskService(&fakeGoModRepo{moduleDir: filepath.Join(dir, "gomodcache", "mod@v1")})
+
+	err := service.CleanCache(project)
+	assert.ErrorContains(t, err, "doesn't look like a proto-sync cache")
+	assert.FileExists(t, filepath.Join(project, "go.mod"))
+	assert.FileExists(t, filepath.Join(project, "github.com/example/api@v1.0.0", "a.proto"))
+
+	// module@version entries without any completion marker aren't enough
+	entriesOnly := filepath.Join(dir, "entries")
+	writeFile(t, filepath.Join(entriesOnly, "github.com/example/api@v1.0.0", "a.proto"), "a")
+	assert.Error(t, service.CleanCache(entriesOnly))
+	assert.DirExists(t, entriesOnly)
+
+	// A cache written by populateFileCache carries the sentinel
+	cacheDir := filepath.Join(dir, "cache")
+	writeFile(t, filepath.Join(dir, "src", "a.proto"), "a")
+	config := &domain.SyncConfig{FileCacheDir: cacheDir}
+	service.populateFileCache(config, domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"}, "schemas", filepath.Join(dir, "src"))
+	assert.FileExists(t, filepath.Join(cacheDir, fileCacheSentinel))
+	require.NoError(t, service.CleanCache(cacheDir))
+	assert.NoDirExists(t, cacheDir)
+}
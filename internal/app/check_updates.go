@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"golang.org/x/mod/semver"
+)
+
+// CheckUpdates resolves the newest version of every repository and compares
+// it with the pinned one. Lookup failures are reported per repository.
+func (p *ProtoSyncServiceImpl) CheckUpdates(ctx context.Context, config *domain.SyncConfig) ([]domain.UpdateCheck, error) {
+	repositories := append([]domain.Repository(nil), config.Repositories...)
+	if len(repositories) == 0 {
+		detected, err := p.DetectRepositories(config)
+		if err != nil {
+			return nil, err
+		}
+		repositories = detected
+	}
+	if err := p.applyVersionsFile(config, repositories); err != nil {
+		return nil, err
+	}
+
+	checks := make([]domain.UpdateCheck, 0, len(repositories))
+	for _, repo := range repositories {
+		if err := ctx.Err(); err != nil {
+			return checks, err
+		}
+
+		check := domain.UpdateCheck{Repository: repo, Current: repo.Version}
+		if repo.LocalPath != "" {
+			checks = append(checks, check)
+			continue
+		}
+
+		latest, err := p.latestVersion(repo.Name, config.StableOnly)
+		if err != nil {
+			check.Error = fmt.Errorf("failed to resolve latest version of %s: %w", repo.Name, err)
+			checks = append(checks, check)
+			continue
+		}
+		check.Latest = latest
+		check.UpdateAvailable = isNewerVersion(latest, repo.Version)
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// isNewerVersion reports whether latest is newer than current. Versions
+// that aren't semantic versions, such as branches, only count as outdated
+// when they differ.
+func isNewerVersion(latest, current string) bool {
+	latestCanonical, currentCanonical := canonicalVersion(latest), canonicalVersion(current)
+	if semver.IsValid(latestCanonical) && semver.IsValid(currentCanonical) {
+		return semver.Compare(latestCanonical, currentCanonical) > 0
+	}
+	return latest != current
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUpdates(t *testing.T) {
+	goMod := &fakeGoModRepo{versions: []string{"v1.0.0", "v1.1.0", "v1.2.0-rc.1"}}
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, goModRepo: goMod}
+	config := &domain.SyncConfig{Repositories: []domain.Repository{
+		{Name: "github.com/example/old", Version: "v1.0.0"},
+		{Name: "github.com/example/current", Version: "v1.1.0"},
+		{Name: "github.com/example/local", Version: "v1.0.0", LocalPath: "../local"},
+	}, StableOnly: true}
+
+	checks, err := service.CheckUpdates(context.Background(), config)
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+	assert.Equal(t, "v1.1.0", checks[0].Latest)
+	assert.True(t, checks[0].UpdateAvailable)
+	assert.Equal(t, "v1.1.0", checks[1].Latest)
+	assert.False(t, checks[1].UpdateAvailable)
+	assert.Empty(t, checks[2].Latest)
+	assert.False(t, checks[2].UpdateAvailable)
+
+	config.StableOnly = false
+	checks, err = service.CheckUpdates(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.0-rc.1", checks[1].Latest)
+	assert.True(t, checks[1].UpdateAvailable)
+
+	goMod.versions = nil
+	checks, err = service.CheckUpdates(context.Background(), config)
+	require.NoError(t, err)
+	assert.ErrorContains(t, checks[0].Error, "failed to resolve latest version of github.com/example/old")
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	assert.True(t, isNewerVersion("v1.10.0", "v1.9.0"))
+	assert.True(t, isNewerVersion("1.2.0", "v1.1.0"))
+	assert.False(t, isNewerVersion("v1.0.0", "v1.0.0"))
+	assert.False(t, isNewerVersion("v1.0.0-rc.1", "v1.0.0"))
+	assert.True(t, isNewerVersion("v1.0.0", "main"))
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// Clean deletes every .proto file below the targets, or only lists them in
+// a dry run. Protected files, files matched by .protosyncignore and backups
+// are kept, and symlinks are never followed, so nothing outside the targets
+// is deleted.
+func (p *ProtoSyncServiceImpl) Clean(ctx context.Context, config *domain.SyncConfig) ([]domain.ProtoFile, error) {
+	if err := validateProtectPatterns(config.Protect); err != nil {
+		return nil, domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("invalid configuration: %w", err))
+	}
+
+	targets, err := p.resolveTargets(config, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []domain.ProtoFile
+	for _, target := range targets {
+		files, err := p.cleanableFiles(target, config)
+		if err != nil {
+			return removed, err
+		}
+
+		for _, file := range files {
+			if err := ctx.Err(); err != nil {
+				return removed, err
+			}
+			if !config.DryRun {
+				if err := p.fileRepo.DeleteFile(file.Path); err != nil {
+					return removed, err
+				}
+				p.logger.Debug("Deleted %s", file.Path)
+			}
+			removed = append(removed, file)
+		}
+	}
+	return removed, nil
+}
+
+// cleanableFiles lists the .proto files below target that clean deletes,
+// named by their path relative to target
+func (p *ProtoSyncServiceImpl) cleanableFiles(target string, config *domain.SyncConfig) ([]domain.ProtoFile, error) {
+	if !p.fileRepo.IsDir(target) {
+		p.logger.Debug("Skipping %s: not a directory", target)
+		return nil, nil
+	}
+
+	files, err := p.fileRepo.ListFiles(target, "", domain.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proto files in %s: %w", target, err)
+	}
+	ignores, err := p.loadTargetIgnores([]string{target})
+	if err != nil {
+		return nil, err
+	}
+
+	var cleanable []domain.ProtoFile
+	for _, file := range files {
+		rel, err := filepath.Rel(target, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(rel, backupDirName+string(filepath.Separator)) {
+			continue
+		}
+		if isProtected(config, rel) {
+			p.logger.Info("Keeping %s: protected", file.Path)
+			continue
+		}
+		if pattern := ignores.ignoredBy(target, rel); pattern != "" {
+			p.logger.Info("Keeping %s: matches %q in %s", file.Path, pattern, filepath.Join(target, ignoreFileName))
+			continue
+		}
+		file.Name = rel
+		cleanable = append(cleanable, file)
+	}
+	return cleanable, nil
+}
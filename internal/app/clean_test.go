@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClean(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/proto/orders.proto", []byte("a"))
+	fileRepo.AddFile("/proto/nested/users.proto", []byte("b"))
+	fileRepo.AddFile("/proto/local.proto", []byte("c"))
+	fileRepo.AddFile("/proto/buf.yaml", []byte("version: v2\n"))
+	fileRepo.AddFile("/proto/"+ignoreFileName, []byte("local.proto\n"))
+	fileRepo.AddFile("/proto/"+backupDirName+"/20240101-000000/orders.proto", []byte("old"))
+	require.NoError(t, fileRepo.SetReadOnly("/proto/orders.proto"))
+
+	config := &domain.SyncConfig{Targets: []string{"/proto", "/missing"}, DryRun: true}
+	files, err := service.Clean(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nested/users.proto", "orders.proto"}, fileNames(files))
+	assert.True(t, fileRepo.FileExists("/proto/orders.proto"))
+
+	config.DryRun = false
+	files, err = service.Clean(context.Background(), config)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, []string{
+		"/proto/" + backupDirName + "/20240101-000000/orders.proto",
+		"/proto/" + ignoreFileName,
+		"/proto/buf.yaml",
+		"/proto/local.proto",
+	}, fileRepo.Files())
+}
+
+func TestCleanKeepsProtectedFiles(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/proto/orders.proto", []byte("a"))
+	fileRepo.AddFile("/proto/vendor/google.proto", []byte("b"))
+	fileRepo.AddFile("/proto/buf.yaml", []byte("version: v2\n"))
+	fileRepo.AddFile("/proto/buf.lock", []byte("version: v2\n"))
+
+	config := &domain.SyncConfig{Targets: []string{"/proto"}, Protect: []string{"vendor/*"}}
+	files, err := service.Clean(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders.proto"}, fileNames(files))
+	assert.Equal(t, []string{"/proto/buf.lock", "/proto/buf.yaml", "/proto/vendor/google.proto"}, fileRepo.Files())
+
+	_, err = service.Clean(context.Background(), &domain.SyncConfig{Targets: []string{"/proto"}, Protect: []string{"["}})
+	assert.ErrorIs(t, err, domain.ErrInvalidConfig)
+}
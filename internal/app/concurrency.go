@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// processRepositories processes repositories with up to config.Concurrency
+// workers. Results keep the order of repositories regardless of completion
+// order, and repositories not yet started when ctx is cancelled are reported
+// as failed. Dry runs always run sequentially so their preview stays
+// readable.
+//
+// With FailFast the first failure cancels the context: no further
+// repository is started and those already running with --concurrency are
+// interrupted.
+func (p *ProtoSyncServiceImpl) processRepositories(ctx context.Context, run *syncRun, repositories []domain.Repository) []domain.SyncResult {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	workers := run.config.Concurrency
+	if workers < 1 || run.config.DryRun {
+		workers = 1
+	}
+	if workers > len(repositories) {
+		workers = len(repositories)
+	}
+
+	results := make([]domain.SyncResult, len(repositories))
+	started := make([]bool, len(repositories))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// The feeder may hand out one more index after cancellation
+				if ctx.Err() != nil {
+					continue
+				}
+				started[i] = true
+				results[i] = p.processRepositoryWithProgress(ctx, run, repositories[i])
+				if run.config.FailFast && results[i].Error != nil {
+					cancel(fmt.Errorf("--fail-fast after %s failed", repositories[i].Name))
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range repositories {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, repo := range repositories {
+		if !started[i] {
+			results[i] = domain.SyncResult{
+				Repository: repo,
+				Error:      domain.WithCode(domain.ErrorCodeCancelled, fmt.Errorf("not processed: %w", context.Cause(ctx))),
+			}
+		}
+	}
+
+	return results
+}
+
+// processRepositoryWithProgress wraps processRepository with progress events
+// and failure logging
+func (p *ProtoSyncServiceImpl) processRepositoryWithProgress(ctx context.Context, run *syncRun, repo domain.Repository) domain.SyncResult {
+	run.report(domain.ProgressEvent{Event: domain.ProgressRepoStart, Repo: repo.Name, Version: repo.Version})
+	result := p.processRepository(ctx, run, repo)
+	run.reportRepoDone(result)
+
+	if !run.config.DryRun && result.Error != nil {
+		p.logger.Error("Failed to process repository %s: %v", repo.Name, result.Error)
+	}
+
+	return result
+}
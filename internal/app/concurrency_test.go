@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessRepositoriesConcurrentKeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "a")
+
+	goMod := &fakeGoModRepo{moduleDir: moduleDir}
+	service := newDiskService(goMod)
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto"), Concurrency: 3}
+
+	var repositories []domain.Repository
+	for i := 0; i < 8; i++ {
+		repositories = append(repositories, domain.Repository{Name: fmt.Sprintf("github.com/example/api%d", i), Version: "v1.0.0"})
+	}
+
+	results := service.processRepositories(context.Background(), newSyncRun(config), repositories)
+	require.Len(t, results, len(repositories))
+	for i, result := range results {
+		assert.Equal(t, repositories[i].Name, result.Repository.Name)
+		assert.True(t, result.Success, "repository %d: %v", i, result.Error)
+	}
+	assert.Equal(t, 8, goMod.downloads)
+}
+
+func TestProcessRepositoriesFailFast(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "a")
+
+	repositories := []domain.Repository{
+		{Name: "github.com/example/api0", Version: "v1.0.0"},
+		{Name: "github.com/example/broken", Version: "v1.0.0"},
+		{Name: "github.com/example/api2", Version: "v1.0.0"},
+	}
+
+	for _, failFast := range []bool{false, true} {
+		goMod := &fakeGoModRepo{moduleDir: moduleDir, failing: map[string]bool{"github.com/example/broken": true}}
+		service := newDiskService(goMod)
+		config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto"), FailFast: failFast}
+
+		results := service.processRepositories(context.Background(), newSyncRun(config), repositories)
+		require.Len(t, results, 3)
+		assert.True(t, results[0].Success)
+		assert.Equal(t, domain.ErrorCodeDownloadFailed, domain.CodeOf(results[1].Error))
+
+		if !failFast {
+			assert.True(t, results[2].Success)
+			assert.Equal(t, 3, goMod.downloads)
+			continue
+		}
+		assert.Equal(t, domain.ErrorCodeCancelled, domain.CodeOf(results[2].Error))
+		assert.ErrorContains(t, results[2].Error, "--fail-fast after github.com/example/broken failed")
+		assert.Equal(t, 2, goMod.downloads)
+	}
+}
+
+func TestProcessRepositoriesTimeoutKeepsFinishedResults(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "a")
+
+	repositories := []domain.Repository{
+		{Name: "github.com/example/api0", Version: "v1.0.0"},
+		{Name: "github.com/example/hanging", Version: "v1.0.0"},
+		{Name: "github.com/example/api2", Version: "v1.0.0"},
+	}
+	goMod := &fakeGoModRepo{moduleDir: moduleDir, hanging: map[string]bool{"github.com/example/hanging": true}}
+	service := newDiskService(goMod)
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto")}
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 100*time.Millisecond, errors.New("--timeout of 100ms exceeded"))
+	defer cancel()
+
+	results := service.processRepositories(ctx, newSyncRun(config), repositories)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	assert.Len(t, results[0].FilesUpdated, 1)
+	assert.ErrorIs(t, results[1].Error, context.DeadlineExceeded)
+	assert.Equal(t, domain.ErrorCodeCancelled, domain.CodeOf(results[2].Error))
+	assert.ErrorContains(t, results[2].Error, "--timeout of 100ms exceeded")
+}
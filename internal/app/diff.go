@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// Diff downloads every repository and compares the content each file would
+// be synced with against the current target files, without writing anything
+func (p *ProtoSyncServiceImpl) Diff(ctx context.Context, config *domain.SyncConfig) ([]domain.FileDiff, error) {
+	if err := p.ValidateConfig(config); err != nil {
+		return nil, domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("invalid configuration: %w", err))
+	}
+
+	repositories, err := p.prepareRepositories(config)
+	if err != nil {
+		return nil, err
+	}
+
+	run := newSyncRun(config)
+	var diffs []domain.FileDiff
+	for _, repo := range repositories {
+		repoDiffs, err := p.diffRepository(ctx, run, repo)
+		if err != nil {
+			return diffs, fmt.Errorf("failed to diff %s@%s: %w", repo.Name, repo.Version, err)
+		}
+		diffs = append(diffs, repoDiffs...)
+	}
+
+	return diffs, nil
+}
+
+func (p *ProtoSyncServiceImpl) diffRepository(ctx context.Context, run *syncRun, repo domain.Repository) ([]domain.FileDiff, error) {
+	config := run.config
+
+	sourcePath, err := p.resolveModuleSource(ctx, config, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceFiles []domain.ProtoFile
+	if len(config.SpecificFiles) > 0 {
+		sourceFiles, err = p.specificSourceFiles(sourcePath, config)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		sourceFiles, err = p.listSourceFiles(sourcePath, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list proto files: %w", err)
+		}
+	}
+
+	targets := targetPaths(config, repo)
+	ignores, err := p.loadTargetIgnores(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []domain.FileDiff
+	for _, sourceFile := range sourceFiles {
+		name := targetName(sourcePath, sourceFile, config)
+		data, err := p.fileContent(ctx, run, sourceFile.Path)
+		if err != nil {
+			return diffs, err
+		}
+
+		for _, target := range p.allowedTargets(ignores, name, targets) {
+			diffs = append(diffs, p.diffFile(repo, name, filepath.Join(target, name), data))
+		}
+	}
+
+	return diffs, nil
+}
+
+// diffFile compares data with the current content of targetFile
+func (p *ProtoSyncServiceImpl) diffFile(repo domain.Repository, name, targetFile string, data []byte) domain.FileDiff {
+	diff := domain.FileDiff{Repository: repo, Name: name, Path: targetFile}
+
+	if !p.fileRepo.FileExists(targetFile) {
+		diff.Change = domain.ChangeAdded
+		diff.Diff = unifiedDiff("/dev/null", "b/"+filepath.ToSlash(targetFile), nil, data)
+		return diff
+	}
+
+	current, err := p.fileRepo.ReadFile(targetFile)
+	if err != nil {
+		// Unreadable targets would be overwritten, so report them as modified
+		diff.Change = domain.ChangeModified
+		return diff
+	}
+
+	if bytes.Equal(current, data) {
+		diff.Change = domain.ChangeUnchanged
+		return diff
+	}
+	diff.Change = domain.ChangeModified
+	diff.Diff = unifiedDiff("a/"+filepath.ToSlash(targetFile), "b/"+filepath.ToSlash(targetFile), current, data)
+	return diff
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRepository(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	target := filepath.Join(dir, "proto")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "new.proto"), "package new;\n")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "same.proto"), "package same;\n")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "changed.proto"), "package changed;\n")
+	writeFile(t, filepath.Join(target, "same.proto"), "package same;\n")
+	writeFile(t, filepath.Join(target, "changed.proto"), "package old;\n")
+
+	service := newDiskService(&fakeGoModRepo{moduleDir: moduleDir})
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: target}
+
+	diffs, err := service.diffRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"})
+	require.NoError(t, err)
+
+	changes := make(map[string]domain.ChangeType)
+	for _, diff := range diffs {
+		changes[diff.Name] = diff.Change
+	}
+	assert.Equal(t, map[string]domain.ChangeType{
+		"new.proto":     domain.ChangeAdded,
+		"same.proto":    domain.ChangeUnchanged,
+		"changed.proto": domain.ChangeModified,
+	}, changes)
+
+	// Nothing is written by a diff
+	assert.NoFileExists(t, filepath.Join(target, "new.proto"))
+}
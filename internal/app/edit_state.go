@@ -0,0 +1,90 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// editState is the persisted record of what proto-sync last wrote to each
+// target file, used to detect local edits before they are overwritten
+type editState struct {
+	Files map[string]string `json:"files"`
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadEditState reads the state file, treating a missing file as empty
+func (p *ProtoSyncServiceImpl) loadEditState(path string) (*editState, error) {
+	state := &editState{Files: make(map[string]string)}
+	if !p.fileRepo.FileExists(path) {
+		return state, nil
+	}
+
+	data, err := p.fileRepo.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]string)
+	}
+
+	return state, nil
+}
+
+func (p *ProtoSyncServiceImpl) saveEditState(path string, state *editState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state file: %w", err)
+	}
+
+	if err := p.fileRepo.CreateDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create state file directory: %w", err)
+	}
+	if err := p.fileRepo.WriteFile(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// checkLocalEdits detects a target that differs from both what proto-sync
+// last wrote and the incoming content, meaning someone edited it by hand
+func (p *ProtoSyncServiceImpl) checkLocalEdits(run *syncRun, targetFile string, incoming []byte) error {
+	if run.editState == nil || !p.fileRepo.FileExists(targetFile) {
+		return nil
+	}
+
+	run.mu.Lock()
+	recorded, ok := run.editState.Files[filepath.Clean(run.realPath(targetFile))]
+	run.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	current, err := p.fileRepo.ReadFile(targetFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", targetFile, err)
+	}
+
+	currentHash := hashContent(current)
+	if currentHash == recorded || currentHash == hashContent(incoming) {
+		return nil
+	}
+
+	if run.config.ProtectEdits {
+		return domain.WithCode(domain.ErrorCodeLocalEdits, fmt.Errorf("%s has local edits since the last sync (use a different target or drop --protect-edits to overwrite)", targetFile))
+	}
+	p.logger.Warning("%s has local edits since the last sync that will be overwritten", targetFile)
+	return nil
+}
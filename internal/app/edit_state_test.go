@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallToTargetsDetectsLocalEdits(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src", "orders.proto")
+	target := filepath.Join(dir, "dst", "orders.proto")
+	stateFile := filepath.Join(dir, "state.json")
+	writeFile(t, source, "v1")
+
+	service := newDiskService(nil)
+	config := &domain.SyncConfig{StateFile: stateFile, ProtectEdits: true}
+
+	run := newSyncRun(config)
+	run.editState = &editState{Files: map[string]string{}}
+	_, err := service.installToTargets(context.Background(), run, source, "orders.proto", []string{filepath.Dir(target)})
+	require.NoError(t, err)
+	require.NoError(t, service.saveEditState(stateFile, run.editState))
+
+	// A hand edit followed by a new upstream version must not be clobbered
+	writeFile(t, target, "v1 with local tweak")
+	writeFile(t, source, "v2")
+
+	state, err := service.loadEditState(stateFile)
+	require.NoError(t, err)
+	run = newSyncRun(config)
+	run.editState = state
+
+	_, err = service.installToTargets(context.Background(), run, source, "orders.proto", []string{filepath.Dir(target)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "local edits")
+
+	// Without --protect-edits the edit is only reported
+	config.ProtectEdits = false
+	_, err = service.installToTargets(context.Background(), run, source, "orders.proto", []string{filepath.Dir(target)})
+	require.NoError(t, err)
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(data))
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"golang.org/x/mod/semver"
+)
+
+// fileCacheMarker is appended to an entry's path to name the file written
+// once the entry is fully populated, so a half-written entry from an
+// interrupted run is never used. It lives beside the entry rather than inside
+// it so broad --pattern globs can't pick it up.
+const fileCacheMarker = ".proto-sync-complete"
+
+// fileCacheSentinel is written at the root of the file cache so cache clean
+// can tell a proto-sync cache from any other directory before removing it
+const fileCacheSentinel = ".proto-sync-cache"
+
+func fileCacheMarkerPath(entry string) string {
+	return filepath.Clean(entry) + fileCacheMarker
+}
+
+// fileCacheEntry returns the cache directory for repo's protos under
+// sourceSubPath. Only semantic versions (including pseudo-versions) are
+// cached because branch names and "latest" are mutable, and local replaces
+// never are.
+func fileCacheEntry(config *domain.SyncConfig, repo domain.Repository, sourceSubPath string) (string, bool) {
+	if config.FileCacheDir == "" || !semver.IsValid(repo.Version) || repo.LocalPath != "" {
+		return "", false
+	}
+	return filepath.Join(config.FileCacheDir, repo.Name+"@"+repo.Version, sourceSubPath), true
+}
+
+// cachedSourcePath returns the cached proto directory for repo if a complete
+// entry exists
+func (p *ProtoSyncServiceImpl) cachedSourcePath(config *domain.SyncConfig, repo domain.Repository, sourceSubPath string) (string, bool) {
+	entry, ok := fileCacheEntry(config, repo, sourceSubPath)
+	if !ok || !p.fileRepo.FileExists(fileCacheMarkerPath(entry)) {
+		return "", false
+	}
+	return entry, true
+}
+
+// populateFileCache copies the downloaded protos into the file cache. Cache
+// failures only cost a future download, so they are logged, not returned.
+func (p *ProtoSyncServiceImpl) populateFileCache(config *domain.SyncConfig, repo domain.Repository, sourceSubPath, sourcePath string) {
+	entry, ok := fileCacheEntry(config, repo, sourceSubPath)
+	if !ok {
+		return
+	}
+
+	sentinel := filepath.Join(config.FileCacheDir, fileCacheSentinel)
+	if !p.fileRepo.FileExists(sentinel) {
+		err := p.fileRepo.CreateDir(config.FileCacheDir)
+		if err == nil {
+			err = p.fileRepo.WriteFile(sentinel, nil)
+		}
+		if err != nil {
+			p.logger.Warning("Failed to create file cache %s: %v", config.FileCacheDir, err)
+			return
+		}
+	}
+
+	if err := p.copyTree(sourcePath, entry, config.SourceListOptions()); err != nil {
+		p.logger.Warning("Failed to populate file cache for %s@%s: %v", repo.Name, repo.Version, err)
+		return
+	}
+
+	// Entries cached before the marker moved beside the entry still carry
+	// it inside, where --pattern globs would select it
+	if legacy := filepath.Join(entry, fileCacheMarker); p.fileRepo.FileExists(legacy) {
+		if err := p.fileRepo.DeleteFile(legacy); err != nil {
+			p.logger.Warning("Failed to remove old cache marker %s: %v", legacy, err)
+			return
+		}
+	}
+
+	if err := p.fileRepo.WriteFile(fileCacheMarkerPath(entry), nil); err != nil {
+		p.logger.Warning("Failed to finalize file cache for %s@%s: %v", repo.Name, repo.Version, err)
+		return
+	}
+
+	p.logger.Debug("Cached protos for %s@%s in %s", repo.Name, repo.Version, entry)
+}
+
+// copyTree copies every file below sourceDir into targetDir, keeping the
+// relative layout. Everything is cached, not just the selected patterns, so a
+// later run with different --pattern values can still use the entry.
+func (p *ProtoSyncServiceImpl) copyTree(sourceDir, targetDir string, opts domain.ListOptions) error {
+	files, err := p.fileRepo.ListFiles(sourceDir, "*", opts)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", sourceDir, err)
+	}
+
+	if err := p.fileRepo.CreateDir(targetDir); err != nil {
+		return fmt.Errorf("failed to create %s: %w", targetDir, err)
+	}
+
+	for _, file := range files {
+		rel, err := filepath.Rel(sourceDir, file.Path)
+		if err != nil {
+			return err
+		}
+		if err := p.fileRepo.CopyFile(file.Path, filepath.Join(targetDir, rel)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveModuleSourceFileCache(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "v1", "a.proto"), "a")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "v1", "nested", "b.proto"), "b")
+
+	goModRepo := &fakeGoModRepo{moduleDir: moduleDir}
+	service := newDiskService(goModRepo)
+	config := &domain.SyncConfig{SourcePath: "schemas/v1", FileCacheDir: filepath.Join(dir, "cache")}
+	repo := domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"}
+
+	sourcePath, err := service.resolveModuleSource(context.Background(), config, repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(moduleDir, "schemas", "v1"), sourcePath)
+	assert.Equal(t, 1, goModRepo.downloads)
+
+	cachedPath, err := service.resolveModuleSource(context.Background(), config, repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cache", "github.com/example/api@v1.0.0", "schemas", "v1"), cachedPath)
+	assert.FileExists(t, filepath.Join(cachedPath, "nested", "b.proto"))
+	assert.NoFileExists(t, filepath.Join(cachedPath, fileCacheMarker))
+	assert.Equal(t, 1, goModRepo.downloads)
+
+	// Mutable versions are never cached
+	_, err = service.resolveModuleSource(context.Background(), config, domain.Repository{Name: repo.Name, Version: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, goModRepo.downloads)
+}
+
+func TestResolveModuleSourceFileCacheOldMarker(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "a")
+
+	// An entry completed by a release that kept the marker inside it
+	entry := filepath.Join(dir, "cache", "github.com/example/api@v1.0.0", "schemas")
+	writeFile(t, filepath.Join(entry, "a.proto"), "a")
+	writeFile(t, filepath.Join(entry, fileCacheMarker), "")
+
+	goModRepo := &fakeGoModRepo{moduleDir: moduleDir}
+	service := newDiskService(goModRepo)
+	config := &domain.SyncConfig{SourcePath: "schemas", FileCacheDir: filepath.Join(dir, "cache")}
+	repo := domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"}
+
+	// The old entry is a miss and gets repopulated in the new layout
+	sourcePath, err := service.resolveModuleSource(context.Background(), config, repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(moduleDir, "schemas"), sourcePath)
+	assert.Equal(t, 1, goModRepo.downloads)
+	assert.NoFileExists(t, filepath.Join(entry, fileCacheMarker))
+	assert.FileExists(t, fileCacheMarkerPath(entry))
+}
+
+func TestResolveModuleSourceReadonlyCheckSkipsFileCache(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "a")
+
+	goModRepo := &fakeGoModRepo{moduleDir: moduleDir}
+	service := newDiskService(goModRepo)
+	config := &domain.SyncConfig{SourcePath: "schemas", FileCacheDir: filepath.Join(dir, "cache")}
+	repo := domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"}
+
+	// Warm the cache
+	_, err := service.resolveModuleSource(context.Background(), config, repo)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, "cache", "github.com/example/api@v1.0.0", "schemas"+fileCacheMarker))
+
+	// The check runs on the downloaded module instead of the cache hit
+	config.SourceReadonlyCheck = true
+	sourcePath, err := service.resolveModuleSource(context.Background(), config, repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(moduleDir, "schemas"), sourcePath)
+	assert.Equal(t, 2, goModRepo.downloads)
+
+	// A module resolving outside GOMODCACHE still fails with a warm cache
+	goModRepo.moduleDir = filepath.Join(dir, "elsewhere", "api@v1.0.0")
+	writeFile(t, filepath.Join(goModRepo.moduleDir, "schemas", "a.proto"), "a")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "gomodcache"), 0o755))
+	service.goModRepo = &outsideCacheGoModRepo{fakeGoModRepo: goModRepo, modCache: filepath.Join(dir, "gomodcache")}
+	_, err = service.resolveModuleSource(context.Background(), config, repo)
+	assert.Equal(t, domain.ErrorCodeSourceOutsideCache, domain.CodeOf(err))
+}
+
+// outsideCacheGoModRepo reports a GOMODCACHE that doesn't contain the
+// downloaded modules
+type outsideCacheGoModRepo struct {
+	*fakeGoModRepo
+	modCache string
+}
+
+func (f *outsideCacheGoModRepo) GetModCacheDir() (string, error) {
+	return f.modCache, nil
+}
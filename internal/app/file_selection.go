@@ -0,0 +1,273 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// filePatterns returns the configured --pattern globs or the default
+func filePatterns(config *domain.SyncConfig) []string {
+	if len(config.FilePatterns) == 0 {
+		return domain.DefaultFilePatterns
+	}
+	return config.FilePatterns
+}
+
+func validateFilePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return fmt.Errorf("empty file pattern, e.g. from a stray comma in --pattern")
+		}
+		if err := domain.ValidateGlob(pattern); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func validateIncludePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if err := domain.ValidateGlob(pattern); err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func validateExcludePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if err := domain.ValidateGlob(pattern); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// listMatchingFiles lists the files below dir selected by the configured
+// patterns, matched against paths relative to dir
+func (p *ProtoSyncServiceImpl) listMatchingFiles(dir string, config *domain.SyncConfig) ([]domain.ProtoFile, error) {
+	patterns := filePatterns(config)
+
+	// A single positive pattern can be matched while walking
+	if len(patterns) == 1 && !strings.HasPrefix(patterns[0], "!") {
+		return p.fileRepo.ListFiles(dir, patterns[0], config.SourceListOptions())
+	}
+
+	all, err := p.fileRepo.ListFiles(dir, "*", config.SourceListOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	var files []domain.ProtoFile
+	for _, file := range all {
+		rel, err := filepath.Rel(dir, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		matched, err := domain.MatchPatterns(patterns, filepath.ToSlash(rel))
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// listSourceFiles lists the files to sync from sourcePath: those selected
+// by the patterns and any --include glob, minus any matching an --exclude
+// glob
+func (p *ProtoSyncServiceImpl) listSourceFiles(sourcePath string, config *domain.SyncConfig) ([]domain.ProtoFile, error) {
+	files, err := p.listMatchingFiles(sourcePath, config)
+	if err != nil || (len(config.Include) == 0 && len(config.Exclude) == 0) {
+		return files, err
+	}
+
+	kept := files[:0]
+	for _, file := range files {
+		rel, err := filepath.Rel(sourcePath, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		slashRel := filepath.ToSlash(rel)
+		if len(config.Include) > 0 {
+			if _, included := matchFirst(config.Include, slashRel); !included {
+				p.logger.Debug("Skipping %s (matches no include pattern)", rel)
+				continue
+			}
+		}
+		if pattern, excluded := matchFirst(config.Exclude, slashRel); excluded {
+			p.logger.Debug("Excluding %s (matches %s)", rel, pattern)
+			continue
+		}
+		kept = append(kept, file)
+	}
+
+	return kept, nil
+}
+
+// matchFirst returns the first pattern matching rel
+func matchFirst(patterns []string, rel string) (string, bool) {
+	for _, pattern := range patterns {
+		if matched, _ := domain.MatchGlob(pattern, rel); matched {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// targetName returns the name file is installed under in the target: its
+// path relative to sourcePath in --recursive mode, otherwise its base name
+func targetName(sourcePath string, file domain.ProtoFile, config *domain.SyncConfig) string {
+	if !config.Recursive {
+		return file.Name
+	}
+	rel, err := filepath.Rel(sourcePath, file.Path)
+	if err != nil {
+		return file.Name
+	}
+	return rel
+}
+
+// targetPaths returns the directories files of repo are synced into: the
+// repository's own target, every --target, or the buf.yaml module path,
+// with their placeholders expanded. Module targets are only assigned when
+// there is no --target.
+func targetPaths(config *domain.SyncConfig, repo domain.Repository) []string {
+	targets := []string{config.TargetPath}
+	if repo.TargetPath != "" {
+		targets = []string{repo.TargetPath}
+	} else if len(config.Targets) > 0 {
+		targets = config.Targets
+	}
+
+	expanded := make([]string, len(targets))
+	for i, target := range targets {
+		expanded[i] = expandTargetPath(target, repo)
+	}
+	return expanded
+}
+
+// modifiedSince reports whether file was modified after the --since cutoff,
+// or true when no cutoff is set
+func modifiedSince(config *domain.SyncConfig, file domain.ProtoFile) bool {
+	return config.Since.IsZero() || file.ModifiedTime.After(config.Since)
+}
+
+// filterSince drops the source files not modified after --since, logging
+// each one it skips
+func (p *ProtoSyncServiceImpl) filterSince(config *domain.SyncConfig, sourcePath string, files []domain.ProtoFile) []domain.ProtoFile {
+	if config.Since.IsZero() {
+		return files
+	}
+
+	var kept []domain.ProtoFile
+	for _, file := range files {
+		if modifiedSince(config, file) {
+			kept = append(kept, file)
+			continue
+		}
+		p.logger.Debug("Skipping %s: last modified %s", targetName(sourcePath, file, config), file.ModifiedTime.Format(time.RFC3339))
+	}
+
+	if skipped := len(files) - len(kept); skipped > 0 {
+		p.logger.Info("Skipped %d file(s) in %s not modified since %s", skipped, sourcePath, config.Since.Format(time.RFC3339))
+	}
+	return kept
+}
+
+// isFileGlob reports whether a --proto-file value is a glob such as
+// product_*.proto rather than a file name
+func isFileGlob(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// specificSourceFiles returns the files --proto-file selects, named by
+// their path relative to sourcePath: each named file, and every listed
+// source file matching a glob. Names that don't exist and globs matching
+// nothing are reported together in one error, along with the files found.
+func (p *ProtoSyncServiceImpl) specificSourceFiles(sourcePath string, config *domain.SyncConfig) ([]domain.ProtoFile, error) {
+	var (
+		files   []domain.ProtoFile
+		listed  bool
+		missing []string
+		matches []domain.ProtoFile
+		seen    = make(map[string]bool)
+	)
+	add := func(file domain.ProtoFile) {
+		if !seen[file.Name] {
+			seen[file.Name] = true
+			matches = append(matches, file)
+		}
+	}
+
+	for _, name := range config.SpecificFiles {
+		if !isFileGlob(name) {
+			// --proto-file names a file below the source; anything else
+			// could read from, and write to, outside the source and targets
+			if !filepath.IsLocal(name) {
+				return nil, domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("proto file %q must be a relative path inside the source directory", name))
+			}
+			path := filepath.Join(sourcePath, name)
+			if !p.fileRepo.FileExists(path) {
+				missing = append(missing, name)
+				continue
+			}
+			add(domain.ProtoFile{Name: name, Path: path})
+			continue
+		}
+
+		if !listed {
+			var err error
+			if files, err = p.listSourceFiles(sourcePath, config); err != nil {
+				return nil, fmt.Errorf("failed to list proto files: %w", err)
+			}
+			listed = true
+		}
+		found := false
+		for _, file := range files {
+			rel, err := filepath.Rel(sourcePath, file.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s relative to %s: %w", file.Path, sourcePath, err)
+			}
+			matched, err := domain.MatchGlob(name, filepath.ToSlash(rel))
+			if err != nil {
+				return nil, domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("invalid proto file pattern %q: %w", name, err))
+			}
+			if matched {
+				found = true
+				file.Name = rel
+				add(file)
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		if !listed {
+			// The listing only helps the user pick a name, so a failure
+			// to list is not worth reporting over the missing files
+			files, _ = p.listSourceFiles(sourcePath, config)
+		}
+		return matches, domain.WithCode(domain.ErrorCodeFileNotFound, fmt.Errorf("proto files not found in %s: %s\nAvailable proto files: %s",
+			sourcePath, strings.Join(missing, ", "), strings.Join(fileNames(files), ", ")))
+	}
+	return matches, nil
+}
+
+// fileNames returns the Name of every file
+func fileNames(files []domain.ProtoFile) []string {
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.Name
+	}
+	return names
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSourceFilesExclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "orders.proto"), "")
+	writeFile(t, filepath.Join(dir, "orders_test.proto"), "")
+	writeFile(t, filepath.Join(dir, "internal", "debug.proto"), "")
+
+	service := newDiskService(nil)
+	config := &domain.SyncConfig{Exclude: []string{"*_test.proto", "internal/*"}}
+
+	files, err := service.listSourceFiles(dir, config)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "orders.proto", files[0].Name)
+
+	assert.Error(t, validateExcludePatterns([]string{"[unclosed"}))
+}
+
+func TestListSourceFilesIncludeThenExclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "api", "orders.proto"), "")
+	writeFile(t, filepath.Join(dir, "api", "orders_test.proto"), "")
+	writeFile(t, filepath.Join(dir, "common", "types.proto"), "")
+
+	service := newDiskService(nil)
+	config := &domain.SyncConfig{Include: []string{"api/*"}, Exclude: []string{"*_test.proto"}}
+
+	files, err := service.listSourceFiles(dir, config)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "orders.proto", files[0].Name)
+
+	config.Include = []string{"missing/*"}
+	_, err = service.copyAllProtoFiles(context.Background(), newSyncRun(config), dir, filepath.Join(t.TempDir(), "dst"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "matched --include")
+}
+
+func TestTargetPathsTemplate(t *testing.T) {
+	repo := domain.Repository{Name: "github.com/example/api", Version: "v1.2.0"}
+
+	config := &domain.SyncConfig{TargetPath: "proto/{version}"}
+	assert.Equal(t, []string{filepath.Join("proto", "v1.2.0")}, targetPaths(config, repo))
+
+	config = &domain.SyncConfig{Targets: []string{"snapshots/{repoBase}/{version}", "vendor/{repo}"}}
+	assert.Equal(t, []string{
+		filepath.Join("snapshots", "api", "v1.2.0"),
+		filepath.Join("vendor", "github.com", "example", "api"),
+	}, targetPaths(config, repo))
+
+	repo.TargetPath = "proto"
+	assert.Equal(t, []string{"proto"}, targetPaths(config, repo))
+
+	assert.NoError(t, validateTargetTemplate("proto/{version}/{repoBase}"))
+	assert.ErrorContains(t, validateTargetTemplate("proto/{tag}"), "unknown placeholder {tag}")
+}
+
+func TestCopyAllProtoFilesInMemoryNoIncludeMatch(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+
+	config := &domain.SyncConfig{Include: []string{"payments/**"}}
+	_, err := service.copyAllProtoFiles(context.Background(), newSyncRun(config), "/mod/schemas", "/proto")
+	assert.Equal(t, domain.ErrorCodeNoFilesMatched, domain.CodeOf(err))
+	assert.False(t, fileRepo.FileExists("/proto"))
+}
+
+func TestListSourceFilesMultiplePatterns(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("a"))
+	fileRepo.AddFile("/mod/schemas/users.proto3", []byte("b"))
+	fileRepo.AddFile("/mod/schemas/gen/debug.protodevel", []byte("c"))
+	fileRepo.AddFile("/mod/schemas/README.md", []byte("docs"))
+
+	config := &domain.SyncConfig{FilePatterns: []string{"*.proto", "*.proto3", "*.protodevel"}}
+	files, err := service.listSourceFiles("/mod/schemas", config)
+	require.NoError(t, err)
+
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"debug.protodevel", "orders.proto", "users.proto3"}, names)
+
+	assert.ErrorContains(t, validateFilePatterns([]string{"*.proto", ""}), "empty file pattern")
+	assert.NotPanics(t, func() {
+		_, _ = service.listSourceFiles("/mod/schemas", &domain.SyncConfig{FilePatterns: []string{""}})
+	})
+}
+
+func TestCopyAllProtoFilesSince(t *testing.T) {
+	dir := t.TempDir()
+	source, target := filepath.Join(dir, "src"), filepath.Join(dir, "proto")
+	writeFile(t, filepath.Join(source, "old.proto"), "old")
+	writeFile(t, filepath.Join(source, "new.proto"), "new")
+	writeFile(t, filepath.Join(target, "old.proto"), "old")
+	cutoff := time.Now().Add(-time.Hour)
+	old := cutoff.Add(-24 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(source, "old.proto"), old, old))
+
+	service := newDiskService(nil)
+	config := &domain.SyncConfig{Since: cutoff, VerifyCount: true}
+
+	files, err := service.copyAllProtoFiles(context.Background(), newSyncRun(config), source, target)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "new.proto", files[0].Name)
+	assert.NoError(t, service.verifyCopiedFiles(config, source, files))
+
+	// Every file is older than the cutoff
+	config.Since = time.Now().Add(time.Hour)
+	files, err = service.copyAllProtoFiles(context.Background(), newSyncRun(config), source, target)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// RunBeforeSync runs the --before-sync hook with the configuration in its
+// environment; a dry run only logs the command
+func (p *ProtoSyncServiceImpl) RunBeforeSync(ctx context.Context, config *domain.SyncConfig) error {
+	if config.BeforeSync == "" {
+		return nil
+	}
+	if config.DryRun {
+		p.logger.Info("Dry run: would run before-sync hook: %s", config.BeforeSync)
+		return nil
+	}
+
+	p.logger.Info("Running before-sync hook: %s", config.BeforeSync)
+	if err := p.shellRunner.Stream(ctx, "before-sync", config.BeforeSync, beforeSyncEnv(config)); err != nil {
+		return fmt.Errorf("before-sync hook failed, nothing was synced: %w", err)
+	}
+	p.logger.Success("before-sync hook completed")
+	return nil
+}
+
+// beforeSyncEnv describes the run before repositories are resolved, so
+// PROTO_SYNC_REPOS is empty when they are detected from go.mod or buf.yaml
+// and PROTO_SYNC_TARGET is empty when the target comes from buf.yaml
+func beforeSyncEnv(config *domain.SyncConfig) []string {
+	var repos []string
+	for _, repo := range config.Repositories {
+		if repo.Version == "" {
+			repos = append(repos, repo.Name)
+			continue
+		}
+		repos = append(repos, repo.Name+"@"+repo.Version)
+	}
+
+	targets := config.Targets
+	if len(targets) == 0 && config.TargetPath != "" {
+		targets = []string{config.TargetPath}
+	}
+
+	return []string{
+		"PROTO_SYNC_TARGET=" + strings.Join(targets, string(os.PathListSeparator)),
+		"PROTO_SYNC_REPOS=" + strings.Join(repos, " "),
+		"PROTO_SYNC_BUF_YAML=" + config.BufYamlPath,
+		"PROTO_SYNC_GO_MOD=" + config.GoModPath,
+		"PROTO_SYNC_SOURCE=" + config.SourcePath,
+		"PROTO_SYNC_VERSION=" + config.SpecifiedVersion,
+		"PROTO_SYNC_SOURCE_OF_TRUTH=" + config.SourceOfTruth,
+		"PROTO_SYNC_DRY_RUN=" + strconv.FormatBool(config.DryRun),
+	}
+}
+
+// runAfterSync runs the --after-sync hook, streaming its output through the
+// logger. The synced targets and repositories are passed in the environment.
+func (p *ProtoSyncServiceImpl) runAfterSync(ctx context.Context, config *domain.SyncConfig, results []domain.SyncResult) error {
+	p.logger.Info("Running after-sync hook: %s", config.AfterSync)
+	if err := p.shellRunner.Stream(ctx, "after-sync", config.AfterSync, afterSyncEnv(config, results)); err != nil {
+		return fmt.Errorf("after-sync hook failed: %w", err)
+	}
+	p.logger.Success("after-sync hook completed")
+	return nil
+}
+
+// afterSyncEnv lists the targets like PATH, and the repositories as
+// space-separated NAME@VERSION so the hook can loop over them
+func afterSyncEnv(config *domain.SyncConfig, results []domain.SyncResult) []string {
+	var targets, repos []string
+	seen := make(map[string]bool)
+	for _, result := range results {
+		repos = append(repos, result.Repository.Name+"@"+result.Repository.Version)
+		for _, target := range targetPaths(config, result.Repository) {
+			if !seen[target] {
+				seen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+
+	return []string{
+		"PROTO_SYNC_TARGET=" + strings.Join(targets, string(os.PathListSeparator)),
+		"PROTO_SYNC_REPOS=" + strings.Join(repos, " "),
+		"PROTO_SYNC_BUF_YAML=" + config.BufYamlPath,
+	}
+}
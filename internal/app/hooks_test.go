@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/Francouer/proto-sync/internal/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAfterSync(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.txt")
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, shellRunner: infrastructure.NewShellRunner(nopLogger{})}
+	config := &domain.SyncConfig{
+		TargetPath:  "proto",
+		BufYamlPath: "buf.yaml",
+		AfterSync:   `echo "$PROTO_SYNC_TARGET|$PROTO_SYNC_REPOS" > ` + out,
+	}
+	results := []domain.SyncResult{
+		{Repository: domain.Repository{Name: "github.com/example/orders", Version: "v1.2.0"}, Success: true},
+		{Repository: domain.Repository{Name: "github.com/example/users", Version: "v0.3.0", TargetPath: "third_party"}, Success: true},
+	}
+
+	require.NoError(t, service.runAfterSync(context.Background(), config, results))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "proto"+string(os.PathListSeparator)+"third_party|github.com/example/orders@v1.2.0 github.com/example/users@v0.3.0\n", string(data))
+
+	config.AfterSync = "echo formatting; exit 3"
+	err = service.runAfterSync(context.Background(), config, results)
+	assert.ErrorContains(t, err, "after-sync hook failed")
+}
+
+func TestRunBeforeSync(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.txt")
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, shellRunner: infrastructure.NewShellRunner(nopLogger{})}
+	config := &domain.SyncConfig{
+		Targets:      []string{"proto"},
+		Repositories: []domain.Repository{{Name: "github.com/example/orders", Version: "v1.2.0"}, {Name: "github.com/example/users"}},
+		DryRun:       true,
+		BeforeSync:   `echo "$PROTO_SYNC_TARGET|$PROTO_SYNC_REPOS|$PROTO_SYNC_DRY_RUN" > ` + out,
+	}
+
+	// A dry run only reports the hook
+	require.NoError(t, service.RunBeforeSync(context.Background(), config))
+	assert.NoFileExists(t, out)
+
+	config.DryRun = false
+	require.NoError(t, service.RunBeforeSync(context.Background(), config))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "proto|github.com/example/orders@v1.2.0 github.com/example/users|false\n", string(data))
+
+	config.BeforeSync = "exit 1"
+	assert.ErrorContains(t, service.RunBeforeSync(context.Background(), config), "before-sync hook failed")
+}
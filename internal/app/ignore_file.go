@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// ignoreFileName lists, one glob per line, target files proto-sync must
+// never overwrite, so hand-written protos can live next to synced ones
+const ignoreFileName = ".protosyncignore"
+
+// targetIgnores maps each target directory to its ignore patterns
+type targetIgnores map[string][]ignorePattern
+
+// ignorePattern is one line of an ignore file. An anchored pattern matches
+// the whole path relative to the target, even without a slash.
+type ignorePattern struct {
+	line     string
+	glob     string
+	anchored bool
+}
+
+// matches reports whether name, a slash-separated path relative to the
+// target, is ignored by the pattern
+func (i ignorePattern) matches(name string) bool {
+	if i.anchored && !strings.Contains(i.glob, "/") {
+		matched, _ := path.Match(i.glob, name)
+		return matched
+	}
+	matched, _ := domain.MatchGlob(i.glob, name)
+	return matched
+}
+
+// loadTargetIgnores reads the .protosyncignore file of every target
+func (p *ProtoSyncServiceImpl) loadTargetIgnores(targets []string) (targetIgnores, error) {
+	ignores := make(targetIgnores, len(targets))
+	for _, target := range targets {
+		patterns, err := p.loadIgnoreFile(filepath.Join(target, ignoreFileName))
+		if err != nil {
+			return nil, err
+		}
+		if len(patterns) > 0 {
+			p.logger.Debug("Loaded %d ignore pattern(s) from %s", len(patterns), filepath.Join(target, ignoreFileName))
+			ignores[target] = patterns
+		}
+	}
+	return ignores, nil
+}
+
+// loadIgnoreFile parses path like a simplified .gitignore: blank lines and
+// lines starting with # are skipped, a leading / anchors the pattern to the
+// target directory and a trailing / matches everything below a directory
+func (p *ProtoSyncServiceImpl) loadIgnoreFile(path string) ([]ignorePattern, error) {
+	if !p.fileRepo.FileExists(path) {
+		return nil, nil
+	}
+
+	data, err := p.fileRepo.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var patterns []ignorePattern
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern := ignorePattern{line: line, glob: strings.TrimPrefix(line, "/"), anchored: strings.HasPrefix(line, "/")}
+		if strings.HasSuffix(pattern.glob, "/") {
+			pattern.glob += "**"
+		}
+		if err := domain.ValidateGlob(pattern.glob); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q on line %d of %s: %w", line, i+1, path, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// ignoredBy returns the pattern of target's ignore file matching name, a
+// path relative to target, or an empty string
+func (t targetIgnores) ignoredBy(target, name string) string {
+	for _, pattern := range t[target] {
+		if pattern.matches(filepath.ToSlash(name)) {
+			return pattern.line
+		}
+	}
+	return ""
+}
+
+// allowedTargets returns the targets whose ignore file doesn't match name,
+// logging every target that is skipped
+func (p *ProtoSyncServiceImpl) allowedTargets(ignores targetIgnores, name string, targets []string) []string {
+	if len(ignores) == 0 {
+		return targets
+	}
+
+	allowed := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if pattern := ignores.ignoredBy(target, name); pattern != "" {
+			p.logger.Info("Skipping %s: matches %q in %s", filepath.Join(target, name), pattern, filepath.Join(target, ignoreFileName))
+			continue
+		}
+		allowed = append(allowed, target)
+	}
+	return allowed
+}
+
+// ignoredByAll reports whether name is ignored in every one of targets
+func (t targetIgnores) ignoredByAll(targets []string, name string) bool {
+	for _, target := range targets {
+		if t.ignoredBy(target, name) == "" {
+			return false
+		}
+	}
+	return len(targets) > 0
+}
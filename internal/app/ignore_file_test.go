@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCopiedFilesSkipsIgnoredFiles(t *testing.T) {
+	dir := t.TempDir()
+	source, target, other := filepath.Join(dir, "src"), filepath.Join(dir, "proto"), filepath.Join(dir, "other")
+	writeFile(t, filepath.Join(source, "a.proto"), "a")
+	writeFile(t, filepath.Join(source, "b.proto"), "b")
+	writeFile(t, filepath.Join(target, ignoreFileName), "b.proto\n")
+
+	service := newDiskService(nil)
+	config := &domain.SyncConfig{VerifyCount: true}
+
+	files, err := service.copyAllProtoFiles(context.Background(), newSyncRun(config), source, target)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.proto"}, fileNames(files))
+	assert.NoError(t, service.verifyCopiedFiles(config, source, files, target))
+
+	// A target that doesn't ignore b.proto still expects it
+	err = service.verifyCopiedFiles(config, source, files, target, other)
+	assert.ErrorContains(t, err, "not copied: b.proto")
+}
+
+func TestCopyAllProtoFilesRespectsIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src")
+	target := filepath.Join(dir, "dst")
+	writeFile(t, filepath.Join(source, "synced.proto"), "package synced;")
+	writeFile(t, filepath.Join(source, "custom.proto"), "package upstream;")
+	writeFile(t, filepath.Join(source, "local", "extra.proto"), "package upstream;")
+	writeFile(t, filepath.Join(target, "custom.proto"), "package handwritten;")
+	writeFile(t, filepath.Join(target, ignoreFileName), "# hand-written protos\ncustom.proto\n\n/local/\n")
+
+	service := newDiskService(nil)
+	run := newSyncRun(&domain.SyncConfig{Recursive: true})
+
+	copied, err := service.copyAllProtoFiles(context.Background(), run, source, target)
+	require.NoError(t, err)
+	require.Len(t, copied, 1)
+	assert.Equal(t, "synced.proto", copied[0].Name)
+
+	data, err := os.ReadFile(filepath.Join(target, "custom.proto"))
+	require.NoError(t, err)
+	assert.Equal(t, "package handwritten;", string(data))
+	assert.NoFileExists(t, filepath.Join(target, "local", "extra.proto"))
+}
+
+func TestIgnoreFileAnchoredPatterns(t *testing.T) {
+	target := t.TempDir()
+	writeFile(t, filepath.Join(target, ignoreFileName), "/foo.proto\nbar.proto\n/local/\n")
+
+	service := newDiskService(nil)
+	ignores, err := service.loadTargetIgnores([]string{target})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/foo.proto", ignores.ignoredBy(target, "foo.proto"))
+	assert.Empty(t, ignores.ignoredBy(target, filepath.Join("sub", "foo.proto")))
+	assert.Equal(t, "bar.proto", ignores.ignoredBy(target, filepath.Join("sub", "bar.proto")))
+	assert.Equal(t, "/local/", ignores.ignoredBy(target, filepath.Join("local", "a.proto")))
+	assert.Empty(t, ignores.ignoredBy(target, filepath.Join("sub", "local", "a.proto")))
+}
+
+func TestLoadIgnoreFileInvalidPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ignoreFileName)
+	writeFile(t, path, "ok.proto\n[broken\n")
+
+	service := newDiskService(nil)
+	_, err := service.loadIgnoreFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestCopySpecificFileInMemoryIgnored(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+	fileRepo.AddFile("/proto/"+ignoreFileName, []byte("orders.proto\n"))
+
+	_, err := service.copySpecificFile(context.Background(), newSyncRun(&domain.SyncConfig{}), "/mod/schemas", "orders.proto", "/proto")
+	assert.ErrorContains(t, err, "ignored by")
+	assert.False(t, fileRepo.FileExists("/proto/orders.proto"))
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// protobufMarker is the go.mod comment ParseProtobufLibraries looks for
+const protobufMarker = "// Protobuf libraries"
+
+func (p *ProtoSyncServiceImpl) Init(ctx context.Context, opts *domain.InitOptions) ([]domain.InitResult, error) {
+	// Check every file first so init never stops halfway
+	var existing []string
+	for _, path := range []string{opts.ConfigPath, opts.BufYamlPath} {
+		if path != "" && p.fileRepo.FileExists(path) {
+			existing = append(existing, path)
+		}
+	}
+	if len(existing) > 0 && !opts.Force {
+		return nil, fmt.Errorf("%s already exist(s); use --force to overwrite", strings.Join(existing, ", "))
+	}
+
+	action := func(path string) string {
+		for _, e := range existing {
+			if e == path {
+				return "overwritten"
+			}
+		}
+		return "created"
+	}
+
+	var results []domain.InitResult
+	if opts.ConfigPath != "" {
+		if err := p.fileRepo.CreateDir(filepath.Dir(opts.ConfigPath)); err != nil {
+			return results, fmt.Errorf("failed to create directory for %s: %w", opts.ConfigPath, err)
+		}
+		if err := p.fileRepo.WriteFile(opts.ConfigPath, opts.Config); err != nil {
+			return results, fmt.Errorf("failed to write config file %s: %w", opts.ConfigPath, err)
+		}
+		results = append(results, domain.InitResult{Path: opts.ConfigPath, Action: action(opts.ConfigPath)})
+	}
+
+	if opts.BufYamlPath != "" {
+		if err := p.bufRepo.WriteBufYaml(opts.BufYamlPath, opts.ModulePath); err != nil {
+			return results, err
+		}
+		results = append(results, domain.InitResult{Path: opts.BufYamlPath, Action: action(opts.BufYamlPath)})
+	}
+
+	result, err := p.addProtobufMarker(opts.GoModPath)
+	if err != nil {
+		return results, err
+	}
+	return append(results, result), nil
+}
+
+// addProtobufMarker appends the // Protobuf libraries comment to go.mod
+// unless it already has one. go.mod is never created, since go mod init
+// knows the module path.
+func (p *ProtoSyncServiceImpl) addProtobufMarker(goModPath string) (domain.InitResult, error) {
+	result := domain.InitResult{Path: goModPath}
+	if !p.fileRepo.FileExists(goModPath) {
+		result.Action = "missing"
+		return result, nil
+	}
+
+	data, err := p.fileRepo.ReadFile(goModPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	if strings.Contains(strings.ToLower(string(data)), strings.ToLower(protobufMarker)) {
+		result.Action = "unchanged"
+		return result, nil
+	}
+
+	content := strings.TrimRight(string(data), "\n") + "\n\n" + protobufMarker + "\n"
+	if err := p.fileRepo.WriteFile(goModPath, []byte(content)); err != nil {
+		return result, fmt.Errorf("failed to update go.mod: %w", err)
+	}
+	result.Action = "updated"
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/Francouer/proto-sync/internal/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit(t *testing.T) {
+	service, fileRepo := newMemService()
+	service.bufRepo = infrastructure.NewBufRepository(nopLogger{}, fileRepo)
+	fileRepo.AddFile("/work/go.mod", []byte("module example.com/app\n\ngo 1.21\n"))
+	opts := &domain.InitOptions{
+		ConfigPath:  "/work/proto-sync.yaml",
+		Config:      []byte("sourcePath: proto\n"),
+		GoModPath:   "/work/go.mod",
+		BufYamlPath: "/work/buf.yaml",
+		ModulePath:  "proto",
+	}
+
+	results, err := service.Init(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.InitResult{
+		{Path: "/work/proto-sync.yaml", Action: "created"},
+		{Path: "/work/buf.yaml", Action: "created"},
+		{Path: "/work/go.mod", Action: "updated"},
+	}, results)
+
+	goMod, err := fileRepo.ReadFile("/work/go.mod")
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/app\n\ngo 1.21\n\n// Protobuf libraries\n", string(goMod))
+	modules, err := service.bufRepo.ParseBufModules("/work/buf.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ModuleInfo{{Path: "proto"}}, modules)
+
+	// Existing files need --force, and nothing is written without it
+	opts.Config = []byte("sourcePath: schemas\n")
+	_, err = service.Init(context.Background(), opts)
+	assert.ErrorContains(t, err, "--force")
+	config, err := fileRepo.ReadFile("/work/proto-sync.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "sourcePath: proto\n", string(config))
+
+	opts.Force = true
+	results, err = service.Init(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "overwritten", results[0].Action)
+	assert.Equal(t, "unchanged", results[2].Action)
+}
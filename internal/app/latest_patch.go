@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"golang.org/x/mod/semver"
+)
+
+// latestPatch returns the highest release in available sharing current's
+// major.minor, or current itself when nothing newer exists. Pre-releases
+// are ignored.
+func latestPatch(current string, available []string) string {
+	canonical := canonicalVersion(current)
+	if !semver.IsValid(canonical) {
+		return current
+	}
+
+	best := current
+	bestCanonical := canonical
+	for _, version := range available {
+		candidate := canonicalVersion(version)
+		if !semver.IsValid(candidate) || semver.Prerelease(candidate) != "" {
+			continue
+		}
+		if semver.MajorMinor(candidate) != semver.MajorMinor(canonical) {
+			continue
+		}
+		if semver.Compare(candidate, bestCanonical) > 0 {
+			best, bestCanonical = version, candidate
+		}
+	}
+
+	return best
+}
+
+// applyLatestPatch upgrades each repository to the latest patch release of
+// its current major.minor. Repositories whose versions cannot be listed
+// keep their version.
+func (p *ProtoSyncServiceImpl) applyLatestPatch(repositories []domain.Repository) {
+	for i := range repositories {
+		repo := &repositories[i]
+		if versionPinned(*repo) {
+			continue
+		}
+		versions, err := p.goModRepo.ListVersions(repo.Name)
+		if err != nil {
+			p.logger.Warning("Could not list versions for %s, keeping %s: %v", repo.Name, repo.Version, err)
+			continue
+		}
+
+		if patch := latestPatch(repo.Version, versions); patch != repo.Version {
+			p.logger.Info("Upgrading %s from %s to latest patch %s", repo.Name, repo.Version, patch)
+			repo.Version = patch
+		}
+	}
+}
+
+// applyLatest replaces each repository's version with the newest release.
+// With stableOnly, pre-releases and pseudo-versions are never picked.
+func (p *ProtoSyncServiceImpl) applyLatest(repositories []domain.Repository, stableOnly bool) error {
+	for i := range repositories {
+		repo := &repositories[i]
+		if versionPinned(*repo) {
+			continue
+		}
+		latest, err := p.latestVersion(repo.Name, stableOnly)
+		if err != nil {
+			return fmt.Errorf("failed to resolve latest version of %s: %w", repo.Name, err)
+		}
+
+		if latest != repo.Version {
+			p.logger.Info("Using latest %s@%s (was %s)", repo.Name, latest, repo.Version)
+		} else {
+			p.logger.Info("%s@%s is already the latest version", repo.Name, latest)
+		}
+		repo.Version = latest
+	}
+	return nil
+}
+
+// latestVersion returns the newest version of repo, only considering
+// release tags when stableOnly is set
+func (p *ProtoSyncServiceImpl) latestVersion(repo string, stableOnly bool) (string, error) {
+	if !stableOnly {
+		return p.goModRepo.GetLatestVersion(repo)
+	}
+
+	versions, err := p.goModRepo.ListVersions(repo)
+	if err != nil {
+		return "", err
+	}
+	latest, ok := domain.LatestStableVersion(versions)
+	if !ok {
+		return "", fmt.Errorf("no stable release among %d version(s)", len(versions))
+	}
+	return latest, nil
+}
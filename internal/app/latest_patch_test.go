@@ -0,0 +1,59 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestPatch(t *testing.T) {
+	available := []string{"v1.2.0", "v1.2.3", "v1.2.10", "v1.3.0", "v2.0.0", "v1.2.11-rc.1"}
+
+	assert.Equal(t, "v1.2.10", latestPatch("v1.2.1", available))
+	assert.Equal(t, "v1.3.0", latestPatch("v1.3.0", available))
+	assert.Equal(t, "v1.4.0", latestPatch("v1.4.0", available))
+	assert.Equal(t, "main", latestPatch("main", available))
+}
+
+func TestApplyLatest(t *testing.T) {
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, goModRepo: &fakeGoModRepo{versions: []string{"v1.0.0", "v1.5.2", "v2.0.0"}}}
+
+	repos := []domain.Repository{{Name: "github.com/example/api", Version: "v1.0.0"}}
+	require.NoError(t, service.applyLatest(repos, false))
+	assert.Equal(t, "v2.0.0", repos[0].Version)
+
+	service.goModRepo = &fakeGoModRepo{}
+	assert.Error(t, service.applyLatest(repos, false))
+}
+
+func TestApplyLatestStableOnly(t *testing.T) {
+	versions := []string{"v1.0.0", "v1.5.2", "v1.5.3-0.20240101000000-abcdef123456", "v2.0.0-rc.1"}
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, goModRepo: &fakeGoModRepo{versions: versions}}
+
+	repos := []domain.Repository{{Name: "github.com/example/api", Version: "v1.0.0"}}
+	require.NoError(t, service.applyLatest(repos, true))
+	assert.Equal(t, "v1.5.2", repos[0].Version)
+
+	require.NoError(t, service.applyLatest(repos, false))
+	assert.Equal(t, "v2.0.0-rc.1", repos[0].Version)
+
+	service.goModRepo = &fakeGoModRepo{versions: []string{"v2.0.0-rc.1"}}
+	assert.ErrorContains(t, service.applyLatest(repos, true), "no stable release")
+}
+
+func TestValidateConfigLatestConflicts(t *testing.T) {
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}}
+	base := domain.SyncConfig{BufYamlPath: "buf.yaml", GoModPath: "go.mod", SourcePath: "proto"}
+
+	config := base
+	config.Latest = true
+	config.SpecifiedVersion = "v1.2.3"
+	assert.ErrorContains(t, service.ValidateConfig(&config), "--latest")
+
+	config = base
+	config.Latest = true
+	config.LatestPatch = true
+	assert.ErrorContains(t, service.ValidateConfig(&config), "--latest")
+}
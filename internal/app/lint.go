@@ -0,0 +1,40 @@
+package app
+
+import (
+	"context"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// lintTargets runs `buf lint` once on every target a repository synced into
+// successfully and records the outcome on each result syncing into it.
+// Copied files are kept when lint fails; the caller decides how to exit.
+func (p *ProtoSyncServiceImpl) lintTargets(ctx context.Context, config *domain.SyncConfig, results []domain.SyncResult) {
+	passed := make(map[string]bool)
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		for _, target := range targetPaths(config, result.Repository) {
+			if _, ok := passed[target]; ok {
+				continue
+			}
+			err := p.bufRepo.Lint(ctx, target)
+			if err != nil {
+				p.logger.Error("%v", err)
+			}
+			passed[target] = err == nil
+		}
+	}
+
+	for i := range results {
+		if !results[i].Success {
+			continue
+		}
+		ok := true
+		for _, target := range targetPaths(config, results[i].Repository) {
+			ok = ok && passed[target]
+		}
+		results[i].LintPassed = &ok
+	}
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// localSourcePath resolves the protos of a repository that go.mod replaces
+// with a local directory. They are read straight from the working copy, so
+// nothing is downloaded, cached or checked against go.sum.
+func (p *ProtoSyncServiceImpl) localSourcePath(config *domain.SyncConfig, repo domain.Repository, sourceSubPath string) (string, error) {
+	if !p.fileRepo.IsDir(repo.LocalPath) {
+		return "", domain.WithCode(domain.ErrorCodeSourceNotFound, fmt.Errorf("local replace directory of %s not found: %s", repo.Name, repo.LocalPath))
+	}
+
+	sourcePath := filepath.Join(repo.LocalPath, sourceSubPath)
+	if !p.fileRepo.FileExists(sourcePath) {
+		return "", domain.WithCode(domain.ErrorCodeSourceNotFound, fmt.Errorf("source directory not found: %s", sourcePath))
+	}
+
+	if config.SourceReadonlyCheck {
+		if err := p.checkSourceInModCache(sourcePath); err != nil {
+			return "", err
+		}
+	}
+	if config.VerifySum {
+		p.logger.Warning("Not checking %s against go.sum: it is replaced by %s", repo.Name, repo.LocalPath)
+	}
+
+	p.logger.Info("Using local replace of %s from %s", repo.Name, sourcePath)
+	return sourcePath, nil
+}
+
+// versionPinned reports whether repo's version can be changed by
+// --version, --latest and similar; local replaces have no version to pick
+func versionPinned(repo domain.Repository) bool {
+	return repo.LocalPath != ""
+}
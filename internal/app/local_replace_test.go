@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveModuleSourceLocalReplace(t *testing.T) {
+	dir := t.TempDir()
+	localDir := filepath.Join(dir, "api")
+	writeFile(t, filepath.Join(localDir, "schemas", "v1", "a.proto"), "a")
+
+	goModRepo := &fakeGoModRepo{moduleDir: filepath.Join(dir, "mod")}
+	service := newDiskService(goModRepo)
+	config := &domain.SyncConfig{SourcePath: "schemas/v1", FileCacheDir: filepath.Join(dir, "cache")}
+	repo := domain.Repository{Name: "github.com/example/api", Version: "v1.0.0", LocalPath: localDir}
+
+	sourcePath, err := service.resolveModuleSource(context.Background(), config, repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(localDir, "schemas", "v1"), sourcePath)
+	assert.Zero(t, goModRepo.downloads)
+	assert.NoDirExists(t, filepath.Join(dir, "cache"))
+
+	repo.LocalPath = filepath.Join(dir, "missing")
+	_, err = service.resolveModuleSource(context.Background(), config, repo)
+	assert.Equal(t, domain.ErrorCodeSourceNotFound, domain.CodeOf(err))
+	assert.ErrorContains(t, err, "local replace directory of github.com/example/api not found")
+}
+
+func TestPrepareRepositoriesKeepsLocalReplaces(t *testing.T) {
+	goModRepo := &fakeGoModRepo{versions: []string{"v1.0.0", "v2.0.0"}}
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, goModRepo: goModRepo}
+	config := &domain.SyncConfig{
+		Targets: []string{"proto"},
+		Latest:  true,
+		Repositories: []domain.Repository{
+			{Name: "github.com/example/api", Version: "v1.0.0"},
+			{Name: "github.com/example/local", Version: "v1.0.0", LocalPath: "../local"},
+		},
+	}
+
+	repositories, err := service.prepareRepositories(config)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", repositories[0].Version)
+	assert.Equal(t, "v1.0.0", repositories[1].Version)
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// syncManifest records the repository versions and file contents a sync
+// installed, so a later run or review can detect drift
+type syncManifest struct {
+	GeneratedAt  time.Time            `json:"generated_at"`
+	Repositories []manifestRepository `json:"repositories"`
+}
+
+// manifestRepository is one synced repository. Version is the one that was
+// downloaded, after --latest and ranges were resolved; local replaces also
+// record their directory.
+type manifestRepository struct {
+	Name      string         `json:"name"`
+	Version   string         `json:"version"`
+	LocalPath string         `json:"local_path,omitempty"`
+	Targets   []string       `json:"targets"`
+	Files     []manifestFile `json:"files"`
+}
+
+// manifestFile is a synced file, by its path relative to the targets
+type manifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+func buildManifest(config *domain.SyncConfig, results []domain.SyncResult, now time.Time) syncManifest {
+	manifest := syncManifest{GeneratedAt: now.UTC(), Repositories: []manifestRepository{}}
+	for _, result := range results {
+		repo := manifestRepository{
+			Name:      result.Repository.Name,
+			Version:   result.Repository.Version,
+			LocalPath: result.Repository.LocalPath,
+			Targets:   targetPaths(config, result.Repository),
+			Files:     []manifestFile{},
+		}
+		for _, file := range result.FilesUpdated {
+			repo.Files = append(repo.Files, manifestFile{Path: filepath.ToSlash(file.Name), SHA256: file.SHA256, Size: file.Size})
+		}
+		sort.Slice(repo.Files, func(i, j int) bool { return repo.Files[i].Path < repo.Files[j].Path })
+		manifest.Repositories = append(manifest.Repositories, repo)
+	}
+	return manifest
+}
+
+// writeManifest saves the manifest of a successful sync to config.Manifest
+func (p *ProtoSyncServiceImpl) writeManifest(config *domain.SyncConfig, results []domain.SyncResult) error {
+	data, err := json.MarshalIndent(buildManifest(config, results, time.Now()), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err := p.fileRepo.CreateDir(filepath.Dir(config.Manifest)); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	if err := p.fileRepo.WriteFile(config.Manifest, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", config.Manifest, err)
+	}
+
+	p.logger.Info("Wrote manifest of %d repository(ies) to %s", len(results), config.Manifest)
+	return nil
+}
+
+func (p *ProtoSyncServiceImpl) VerifyManifest(ctx context.Context, manifestPath string) ([]domain.ManifestDrift, error) {
+	data, err := p.fileRepo.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", manifestPath, err)
+	}
+	var manifest syncManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", manifestPath, err)
+	}
+
+	var drift []domain.ManifestDrift
+	checked := 0
+	for _, repo := range manifest.Repositories {
+		for _, target := range repo.Targets {
+			if err := ctx.Err(); err != nil {
+				return drift, err
+			}
+
+			present, err := p.listTargetFiles(target)
+			if err != nil {
+				return drift, err
+			}
+			for _, file := range repo.Files {
+				checked++
+				path, ok := present[file.Path]
+				if !ok {
+					drift = append(drift, domain.ManifestDrift{Repository: repo.Name, Path: filepath.Join(target, filepath.FromSlash(file.Path)), Missing: true, Expected: file.SHA256})
+					continue
+				}
+
+				content, err := p.fileRepo.ReadFile(path)
+				if err != nil {
+					return drift, fmt.Errorf("failed to read %s: %w", path, err)
+				}
+				if actual := hashContent(content); actual != file.SHA256 {
+					drift = append(drift, domain.ManifestDrift{Repository: repo.Name, Path: path, Expected: file.SHA256, Actual: actual})
+				}
+			}
+		}
+	}
+
+	p.logger.Info("Checked %d file(s) from %d repository(ies) in %s", checked, len(manifest.Repositories), manifestPath)
+	return drift, nil
+}
+
+// listTargetFiles maps the slash-separated path of every file under target
+// to its location; a missing target has no files
+func (p *ProtoSyncServiceImpl) listTargetFiles(target string) (map[string]string, error) {
+	present := make(map[string]string)
+	if !p.fileRepo.IsDir(target) {
+		return present, nil
+	}
+
+	// The manifest may list files other than .proto ones, picked by --pattern
+	files, err := p.fileRepo.ListFiles(target, "**", domain.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", target, err)
+	}
+	for _, file := range files {
+		rel, err := filepath.Rel(target, file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s relative to %s: %w", file.Path, target, err)
+		}
+		present[filepath.ToSlash(rel)] = file.Path
+	}
+	return present, nil
+}
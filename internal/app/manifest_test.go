@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteManifest(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/v1/orders.proto", []byte("message Order {}\n"))
+	config := &domain.SyncConfig{TargetPath: "/work/proto", Manifest: "/work/proto-sync.manifest.json"}
+	require.NoError(t, fileRepo.CreateDir("/work/proto"))
+
+	file, err := service.installToTargets(context.Background(), newSyncRun(config), "/mod/schemas/v1/orders.proto", "v1/orders.proto", []string{"/work/proto"})
+	require.NoError(t, err)
+	assert.Equal(t, hashContent([]byte("message Order {}\n")), file.SHA256)
+
+	results := []domain.SyncResult{
+		{Repository: domain.Repository{Name: "github.com/example/orders", Version: "v1.2.0"}, FilesUpdated: []domain.ProtoFile{file}, Success: true},
+		{Repository: domain.Repository{Name: "github.com/example/users", Version: "v0.3.0", TargetPath: "/work/users"}, Success: true},
+	}
+	require.NoError(t, service.writeManifest(config, results))
+
+	data, err := fileRepo.ReadFile(config.Manifest)
+	require.NoError(t, err)
+	var manifest syncManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, []manifestRepository{
+		{
+			Name:    "github.com/example/orders",
+			Version: "v1.2.0",
+			Targets: []string{"/work/proto"},
+			Files:   []manifestFile{{Path: "v1/orders.proto", SHA256: file.SHA256, Size: 17}},
+		},
+		{Name: "github.com/example/users", Version: "v0.3.0", Targets: []string{"/work/users"}, Files: []manifestFile{}},
+	}, manifest.Repositories)
+	assert.False(t, manifest.GeneratedAt.IsZero())
+}
+
+func TestVerifyManifest(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/work/proto/orders.proto", []byte("message Order {}\n"))
+	fileRepo.AddFile("/work/proto/v1/users.proto", []byte("message User {}\n"))
+	fileRepo.AddFile("/work/proto/README.md", []byte("docs\n"))
+	config := &domain.SyncConfig{TargetPath: "/work/proto", Manifest: "/work/proto-sync.manifest.json"}
+	var files []domain.ProtoFile
+	for _, name := range []string{"orders.proto", "v1/users.proto", "README.md"} {
+		data, err := fileRepo.ReadFile(filepath.Join("/work/proto", name))
+		require.NoError(t, err)
+		files = append(files, domain.ProtoFile{Name: name, SHA256: hashContent(data), Size: int64(len(data))})
+	}
+	results := []domain.SyncResult{{Repository: domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"}, FilesUpdated: files, Success: true}}
+	require.NoError(t, service.writeManifest(config, results))
+
+	drift, err := service.VerifyManifest(context.Background(), config.Manifest)
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+
+	fileRepo.AddFile("/work/proto/orders.proto", []byte("message Order { string id = 1; }\n"))
+	require.NoError(t, fileRepo.DeleteFile("/work/proto/v1/users.proto"))
+	drift, err = service.VerifyManifest(context.Background(), config.Manifest)
+	require.NoError(t, err)
+	require.Len(t, drift, 2)
+	assert.Equal(t, "/work/proto/orders.proto", drift[0].Path)
+	assert.False(t, drift[0].Missing)
+	assert.NotEqual(t, drift[0].Expected, drift[0].Actual)
+	assert.Equal(t, domain.ManifestDrift{Repository: "github.com/example/api", Path: "/work/proto/v1/users.proto", Missing: true, Expected: files[1].SHA256}, drift[1])
+
+	_, err = service.VerifyManifest(context.Background(), "/work/missing.json")
+	assert.ErrorContains(t, err, "failed to read manifest")
+}
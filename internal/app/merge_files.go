@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// checkMergeFiles verifies that shared files listed with --merge-file are
+// identical across every repository of the run. The first repository to
+// provide a file sets the expected content; any later divergence fails the
+// repository before it overwrites anything.
+func (p *ProtoSyncServiceImpl) checkMergeFiles(run *syncRun, repo domain.Repository, sourcePath string) error {
+	for _, name := range run.config.MergeFiles {
+		sourceFile := filepath.Join(sourcePath, name)
+		if !p.fileRepo.FileExists(sourceFile) {
+			continue
+		}
+
+		data, err := p.fileRepo.ReadFile(sourceFile)
+		if err != nil {
+			return fmt.Errorf("failed to read shared file %s: %w", name, err)
+		}
+
+		run.mu.Lock()
+		existing, seen := run.mergedFiles[name]
+		if !seen {
+			run.mergedFiles[name] = mergedFile{repo: repo.Name, data: data}
+		}
+		run.mu.Unlock()
+
+		if !seen {
+			continue
+		}
+
+		if !bytes.Equal(existing.data, data) {
+			return domain.WithCode(domain.ErrorCodeMergeConflict, fmt.Errorf("shared file %s from %s differs from the copy synced from %s", name, repo.Name, existing.repo))
+		}
+		p.logger.Info("Shared file %s from %s matches %s", name, repo.Name, existing.repo)
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a", "options.proto"), "option a = 1;")
+	writeFile(t, filepath.Join(dir, "b", "options.proto"), "option a = 1;")
+	writeFile(t, filepath.Join(dir, "c", "options.proto"), "option a = 2;")
+
+	service := newDiskService(nil)
+	run := newSyncRun(&domain.SyncConfig{MergeFiles: []string{"options.proto", "missing.proto"}})
+
+	require.NoError(t, service.checkMergeFiles(run, domain.Repository{Name: "repo-a"}, filepath.Join(dir, "a")))
+	require.NoError(t, service.checkMergeFiles(run, domain.Repository{Name: "repo-b"}, filepath.Join(dir, "b")))
+
+	err := service.checkMergeFiles(run, domain.Repository{Name: "repo-c"}, filepath.Join(dir, "c"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "repo-c")
+	assert.Contains(t, err.Error(), "repo-a")
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"golang.org/x/mod/module"
+)
+
+// verifyModuleSum checks the module extracted to modulePath against the
+// go.sum next to the configured go.mod. The hash is computed from the files
+// on disk, so a proxy serving altered content is caught even if it also
+// reports a matching checksum.
+func (p *ProtoSyncServiceImpl) verifyModuleSum(config *domain.SyncConfig, repo domain.Repository, modulePath string) error {
+	goSumPath := filepath.Join(filepath.Dir(config.GoModPath), "go.sum")
+	sums, err := p.goModRepo.ParseGoSum(goSumPath)
+	if err != nil {
+		return domain.WithCode(domain.ErrorCodeChecksumMismatch, err)
+	}
+
+	version := downloadedVersion(modulePath, repo.Version)
+	want, ok := sums[repo.Name+"@"+version]
+	if !ok {
+		return domain.WithCode(domain.ErrorCodeChecksumMismatch, fmt.Errorf("%s@%s has no entry in %s; run go get %s@%s first", repo.Name, version, goSumPath, repo.Name, version))
+	}
+
+	got, err := p.goModRepo.HashModuleDir(modulePath, repo.Name, version)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return domain.WithCode(domain.ErrorCodeChecksumMismatch, fmt.Errorf("checksum mismatch for %s@%s: downloaded %s, %s has %s", repo.Name, version, got, goSumPath, want))
+	}
+
+	p.logger.Debug("Verified %s@%s against %s", repo.Name, version, goSumPath)
+	return nil
+}
+
+// downloadedVersion returns the concrete version of a module cache
+// directory such as .../api@v1.2.3, which differs from the requested one
+// for branches and queries
+func downloadedVersion(modulePath, requested string) string {
+	base := filepath.Base(modulePath)
+	at := strings.LastIndex(base, "@")
+	if at < 0 {
+		return requested
+	}
+	version, err := module.UnescapeVersion(base[at+1:])
+	if err != nil {
+		return requested
+	}
+	return version
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveModuleSourceVerifySum(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "message A {}\n")
+
+	goModRepo := &fakeGoModRepo{moduleDir: moduleDir}
+	service := newDiskService(goModRepo)
+	config := &domain.SyncConfig{SourcePath: "schemas", GoModPath: filepath.Join(dir, "go.mod"), VerifySum: true}
+	repo := domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"}
+
+	_, err := service.resolveModuleSource(context.Background(), config, repo)
+	assert.Equal(t, domain.ErrorCodeChecksumMismatch, domain.CodeOf(err))
+
+	writeFile(t, filepath.Join(dir, "go.sum"), "github.com/example/api v1.0.0 h1:not-the-hash=\n")
+	_, err = service.resolveModuleSource(context.Background(), config, repo)
+	assert.Equal(t, domain.ErrorCodeChecksumMismatch, domain.CodeOf(err))
+	assert.ErrorContains(t, err, "checksum mismatch for github.com/example/api@v1.0.0")
+
+	hash, err := goModRepo.HashModuleDir(moduleDir, repo.Name, repo.Version)
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "go.sum"), "github.com/example/api v1.0.0 "+hash+"\n")
+	sourcePath, err := service.resolveModuleSource(context.Background(), config, repo)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(moduleDir, "schemas"), sourcePath)
+}
+
+func TestDownloadedVersion(t *testing.T) {
+	assert.Equal(t, "v0.0.0-20240101000000-abcdef123456", downloadedVersion("/cache/github.com/example/api@v0.0.0-20240101000000-abcdef123456", "main"))
+	assert.Equal(t, "v1.0.0-RC", downloadedVersion("/cache/github.com/example/api@v1.0.0-!r!c", "v1.0.0-RC"))
+	assert.Equal(t, "main", downloadedVersion("/vendor/api", "main"))
+}
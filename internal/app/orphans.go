@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// detectOrphans records, for every target all repositories synced into
+// successfully, the target files matching the file selection that no
+// repository provided in this run. Orphans are attributed to the first
+// repository syncing into the target and deleted with --prune.
+//
+// Runs that deliberately sync a subset (--proto-file, --single-repo,
+// --since) are skipped, since every other file would look orphaned. Dry
+// runs report the files --prune would delete without deleting them.
+func (p *ProtoSyncServiceImpl) detectOrphans(run *syncRun, results []domain.SyncResult) {
+	config := run.config
+	if len(config.SpecificFiles) > 0 || config.SingleRepo || !config.Since.IsZero() {
+		return
+	}
+
+	type targetState struct {
+		first  int
+		failed bool
+		synced map[string]bool
+	}
+	states := make(map[string]*targetState)
+	var order []string
+
+	for i, result := range results {
+		for _, target := range targetPaths(config, result.Repository) {
+			state, ok := states[target]
+			if !ok {
+				state = &targetState{first: i, synced: make(map[string]bool)}
+				states[target] = state
+				order = append(order, target)
+			}
+			// A dry run that couldn't list the source doesn't know its files
+			state.failed = state.failed || !result.Success || result.ChangesUnknown
+			for _, file := range result.FilesUpdated {
+				state.synced[file.Name] = true
+			}
+		}
+	}
+
+	for _, target := range order {
+		state := states[target]
+		if state.failed {
+			p.logger.Debug("Skipping orphan detection in %s: a repository failed to sync", target)
+			continue
+		}
+
+		orphans, err := p.findOrphans(target, config, state.synced)
+		if err != nil {
+			p.logger.Warning("Failed to detect orphaned files in %s: %v", target, err)
+			continue
+		}
+
+		result := &results[state.first]
+		for _, orphan := range orphans {
+			if config.Prune && config.DryRun {
+				p.logger.Info("Would prune orphaned file %s", orphan.Path)
+				result.PrunedFiles = append(result.PrunedFiles, orphan)
+				continue
+			}
+			if config.Prune {
+				if err := p.pruneFile(orphan.Path); err != nil {
+					p.logger.Warning("%v", err)
+					continue
+				}
+				result.PrunedFiles = append(result.PrunedFiles, orphan)
+				continue
+			}
+			p.logger.Warning("Orphaned file %s has no source in this sync (use --prune to delete it)", orphan.Path)
+		}
+		result.OrphanedFiles = append(result.OrphanedFiles, orphans...)
+	}
+}
+
+// findOrphans lists the files in target that the sync would select but
+// that are not in synced. Protected files, files ignored by
+// .protosyncignore and backups are never orphans; without --recursive only
+// files directly in target are considered.
+func (p *ProtoSyncServiceImpl) findOrphans(target string, config *domain.SyncConfig, synced map[string]bool) ([]domain.ProtoFile, error) {
+	if !p.fileRepo.IsDir(target) {
+		return nil, nil
+	}
+
+	// Symlinks in a target are never followed, so --prune can't delete
+	// files outside of it
+	targetConfig := *config
+	targetConfig.DereferenceSymlinks = false
+	files, err := p.listSourceFiles(target, &targetConfig)
+	if err != nil {
+		return nil, err
+	}
+	ignores, err := p.loadTargetIgnores([]string{target})
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []domain.ProtoFile
+	for _, file := range files {
+		rel, err := filepath.Rel(target, file.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case synced[rel]:
+		case !config.Recursive && strings.ContainsRune(rel, filepath.Separator):
+		case strings.HasPrefix(rel, backupDirName+string(filepath.Separator)):
+		case isProtected(config, rel):
+		case ignores.ignoredBy(target, rel) != "":
+		default:
+			file.Name = rel
+			orphans = append(orphans, file)
+		}
+	}
+	return orphans, nil
+}
+
+// pruneFile deletes an orphaned target file
+func (p *ProtoSyncServiceImpl) pruneFile(path string) error {
+	if err := p.fileRepo.DeleteFile(path); err != nil {
+		return fmt.Errorf("failed to prune orphaned file: %w", err)
+	}
+	p.logger.Info("Pruned orphaned file %s", path)
+	return nil
+}
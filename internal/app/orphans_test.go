@@ -0,0 +1,124 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectOrphans(t *testing.T) {
+	target := t.TempDir()
+	writeFile(t, filepath.Join(target, "a.proto"), "package a;")
+	writeFile(t, filepath.Join(target, "b.proto"), "package b;")
+	writeFile(t, filepath.Join(target, "removed.proto"), "package removed;")
+	writeFile(t, filepath.Join(target, "custom.proto"), "package custom;")
+	writeFile(t, filepath.Join(target, "nested", "other.proto"), "package other;")
+	writeFile(t, filepath.Join(target, backupDirName, "20240101-000000", "old.proto"), "package old;")
+	writeFile(t, filepath.Join(target, ignoreFileName), "custom.proto\n")
+
+	service := newDiskService(nil)
+	results := func() []domain.SyncResult {
+		return []domain.SyncResult{
+			{Repository: domain.Repository{Name: "repo-a"}, Success: true, FilesUpdated: []domain.ProtoFile{{Name: "a.proto"}}},
+			{Repository: domain.Repository{Name: "repo-b"}, Success: true, FilesUpdated: []domain.ProtoFile{{Name: "b.proto"}}},
+		}
+	}
+
+	config := &domain.SyncConfig{TargetPath: target}
+	got := results()
+	service.detectOrphans(newSyncRun(config), got)
+	require.Len(t, got[0].OrphanedFiles, 1)
+	assert.Equal(t, "removed.proto", got[0].OrphanedFiles[0].Name)
+	assert.Empty(t, got[1].OrphanedFiles)
+	assert.FileExists(t, filepath.Join(target, "removed.proto"))
+
+	// A failed repository may have provided the file, so nothing is reported
+	got = results()
+	got[1].Success = false
+	service.detectOrphans(newSyncRun(config), got)
+	assert.Empty(t, got[0].OrphanedFiles)
+
+	config.Prune = true
+	got = results()
+	service.detectOrphans(newSyncRun(config), got)
+	require.Len(t, got[0].OrphanedFiles, 1)
+	require.Len(t, got[0].PrunedFiles, 1)
+	assert.Equal(t, filepath.Join(target, "removed.proto"), got[0].PrunedFiles[0].Path)
+	assert.NoFileExists(t, filepath.Join(target, "removed.proto"))
+	assert.FileExists(t, filepath.Join(target, "custom.proto"))
+	assert.FileExists(t, filepath.Join(target, "nested", "other.proto"))
+}
+
+func TestDetectOrphansDryRun(t *testing.T) {
+	target := t.TempDir()
+	writeFile(t, filepath.Join(target, "a.proto"), "package a;")
+	writeFile(t, filepath.Join(target, "removed.proto"), "package removed;")
+
+	service := newDiskService(nil)
+	config := &domain.SyncConfig{TargetPath: target, DryRun: true, Prune: true}
+	results := []domain.SyncResult{{Repository: domain.Repository{Name: "repo-a"}, Success: true, FilesUpdated: []domain.ProtoFile{{Name: "a.proto", Change: domain.ChangeUnchanged}}}}
+	service.detectOrphans(newSyncRun(config), results)
+
+	assert.Equal(t, []string{"removed.proto"}, fileNames(results[0].PrunedFiles))
+	assert.FileExists(t, filepath.Join(target, "removed.proto"))
+	assert.True(t, domain.HasPendingChanges(results))
+
+	// Without the module's file list every target file would look orphaned
+	results = []domain.SyncResult{{Repository: domain.Repository{Name: "repo-a"}, Success: true, ChangesUnknown: true}}
+	service.detectOrphans(newSyncRun(config), results)
+	assert.Empty(t, results[0].OrphanedFiles)
+	assert.Empty(t, results[0].PrunedFiles)
+}
+
+func TestPruneKeepsProtectedFiles(t *testing.T) {
+	target := t.TempDir()
+	writeFile(t, filepath.Join(target, "a.proto"), "package a;")
+	writeFile(t, filepath.Join(target, "removed.proto"), "package removed;")
+	writeFile(t, filepath.Join(target, "local.proto"), "package local;")
+	writeFile(t, filepath.Join(target, "buf.yaml"), "version: v2\n")
+	writeFile(t, filepath.Join(target, "buf.lock"), "version: v2\n")
+
+	service := newDiskService(nil)
+	// The patterns select the buf files too, so only protection keeps them
+	config := &domain.SyncConfig{
+		TargetPath:   target,
+		FilePatterns: []string{"*.proto", "buf.*"},
+		Protect:      []string{"local.proto"},
+		Prune:        true,
+	}
+	results := []domain.SyncResult{{Repository: domain.Repository{Name: "repo-a"}, Success: true, FilesUpdated: []domain.ProtoFile{{Name: "a.proto"}}}}
+	service.detectOrphans(newSyncRun(config), results)
+
+	assert.Equal(t, []string{"removed.proto"}, fileNames(results[0].OrphanedFiles))
+	assert.Equal(t, []string{"removed.proto"}, fileNames(results[0].PrunedFiles))
+	assert.NoFileExists(t, filepath.Join(target, "removed.proto"))
+	assert.FileExists(t, filepath.Join(target, "local.proto"))
+	assert.FileExists(t, filepath.Join(target, "buf.yaml"))
+	assert.FileExists(t, filepath.Join(target, "buf.lock"))
+}
+
+func TestDetectOrphansInMemory(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/proto/orders.proto", []byte("a"))
+	fileRepo.AddFile("/proto/stale.proto", []byte("b"))
+	fileRepo.AddFile("/proto/local_only.proto", []byte("c"))
+	fileRepo.AddFile("/proto/buf.yaml", []byte("version: v2\n"))
+	require.NoError(t, fileRepo.SetReadOnly("/proto/stale.proto"))
+
+	config := &domain.SyncConfig{TargetPath: "/proto", Exclude: []string{"local_*"}, Prune: true}
+	results := []domain.SyncResult{{
+		Repository:   domain.Repository{Name: "github.com/example/api"},
+		Success:      true,
+		FilesUpdated: []domain.ProtoFile{{Name: "orders.proto"}},
+	}}
+
+	service.detectOrphans(newSyncRun(config), results)
+
+	require.Len(t, results[0].OrphanedFiles, 1)
+	assert.Equal(t, "/proto/stale.proto", results[0].OrphanedFiles[0].Path)
+	require.Len(t, results[0].PrunedFiles, 1)
+	assert.Equal(t, []string{"/proto/buf.yaml", "/proto/local_only.proto", "/proto/orders.proto"}, fileRepo.Files())
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+var protoPackageRegex = regexp.MustCompile(`(?m)^\s*package\s+([A-Za-z_][A-Za-z0-9_.]*)\s*;`)
+
+// parseProtoPackage returns the package declared in a proto file, or "" if
+// there is none
+func parseProtoPackage(data []byte) string {
+	matches := protoPackageRegex.FindSubmatch(data)
+	if len(matches) != 2 {
+		return ""
+	}
+	return string(matches[1])
+}
+
+// packageMatchesDir reports whether relDir (slash separated, relative to the
+// target) ends with the directory layout implied by pkg
+func packageMatchesDir(pkg, relDir string) bool {
+	expected := strings.ReplaceAll(pkg, ".", "/")
+	return relDir == expected || strings.HasSuffix(relDir, "/"+expected)
+}
+
+// checkPackagePaths warns about copied files whose package declaration does
+// not correspond to their location below targetPath
+func (p *ProtoSyncServiceImpl) checkPackagePaths(targetPath string, files []domain.ProtoFile) []string {
+	var warnings []string
+
+	for _, file := range files {
+		data, err := p.fileRepo.ReadFile(file.Path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("could not read %s to check its package: %v", file.Path, err))
+			continue
+		}
+
+		pkg := parseProtoPackage(data)
+		if pkg == "" {
+			continue
+		}
+
+		rel, err := filepath.Rel(targetPath, file.Path)
+		if err != nil {
+			continue
+		}
+		relDir := filepath.ToSlash(filepath.Dir(rel))
+
+		if !packageMatchesDir(pkg, relDir) {
+			warning := fmt.Sprintf("%s declares package %s but is located in %s (expected .../%s)",
+				rel, pkg, relDir, strings.ReplaceAll(pkg, ".", "/"))
+			p.logger.Warning("%s", warning)
+			warnings = append(warnings, warning)
+		}
+	}
+
+	return warnings
+}
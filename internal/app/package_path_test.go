@@ -0,0 +1,33 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPackagePaths(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "org", "api", "v1", "good.proto")
+	moved := filepath.Join(dir, "org", "api", "moved.proto")
+	nopkg := filepath.Join(dir, "nopkg.proto")
+	writeFile(t, good, "syntax = \"proto3\";\n\npackage org.api.v1;\n")
+	writeFile(t, moved, "syntax = \"proto3\";\npackage org.api.v1;\n")
+	writeFile(t, nopkg, "syntax = \"proto3\";\n")
+
+	service := newDiskService(nil)
+	warnings := service.checkPackagePaths(dir, []domain.ProtoFile{{Path: good}, {Path: moved}, {Path: nopkg}})
+
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "moved.proto declares package org.api.v1")
+}
+
+func TestPackageMatchesDir(t *testing.T) {
+	assert.True(t, packageMatchesDir("org.api.v1", "org/api/v1"))
+	assert.True(t, packageMatchesDir("api.v1", "vendor/api/v1"))
+	assert.False(t, packageMatchesDir("api.v1", "vendorapi/v1"))
+	assert.False(t, packageMatchesDir("org.api.v1", "."))
+}
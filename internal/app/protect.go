@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// validateProtectPatterns ensures every --protect pattern is a valid glob
+func validateProtectPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if err := domain.ValidateGlob(pattern); err != nil {
+			return fmt.Errorf("invalid protect pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// isProtected reports whether a target file must be left alone by pruning.
+// relPath is relative to the target directory; patterns are matched like
+// --pattern globs, so `buf.yaml` protects nested copies as well and
+// `api/**` everything below api.
+func isProtected(config *domain.SyncConfig, relPath string) bool {
+	base := filepath.Base(relPath)
+
+	for _, name := range domain.DefaultProtectedFiles {
+		if base == name {
+			return true
+		}
+	}
+
+	for _, pattern := range config.Protect {
+		if matched, _ := domain.MatchGlob(pattern, filepath.ToSlash(relPath)); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsProtected(t *testing.T) {
+	config := &domain.SyncConfig{Protect: []string{"internal/*.proto", "keep_*.proto", "vendor/**"}}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "buf.yaml", want: true},
+		{path: "buf.lock", want: true},
+		{path: "nested/buf.md", want: true},
+		{path: "buf.gen.yaml", want: true},
+		{path: "internal/secret.proto", want: true},
+		{path: "keep_me.proto", want: true},
+		{path: "api/v1/keep_me.proto", want: true},
+		{path: filepath.Join("vendor", "google", "api", "http.proto"), want: true},
+		{path: filepath.Join("internal", "nested", "secret.proto"), want: false},
+		{path: "orders.proto", want: false},
+		{path: "api/v1/orders.proto", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, isProtected(config, tt.path))
+		})
+	}
+}
+
+func TestValidateProtectPatterns(t *testing.T) {
+	assert.NoError(t, validateProtectPatterns([]string{"*.proto", "api/**"}))
+	assert.Error(t, validateProtectPatterns([]string{"[broken"}))
+}
@@ -1,19 +1,23 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/Francouer/proto-sync/internal/domain"
 )
 
 type ProtoSyncServiceImpl struct {
-	logger    domain.Logger
-	fileRepo  domain.FileRepository
-	goModRepo domain.GoModRepository
-	bufRepo   domain.BufRepository
+	logger      domain.Logger
+	fileRepo    domain.FileRepository
+	goModRepo   domain.GoModRepository
+	bufRepo     domain.BufRepository
+	shellRunner domain.ShellRunner
 }
 
 // NewProtoSyncService creates a new proto sync service
@@ -22,12 +26,14 @@ func NewProtoSyncService(
 	fileRepo domain.FileRepository,
 	goModRepo domain.GoModRepository,
 	bufRepo domain.BufRepository,
+	shellRunner domain.ShellRunner,
 ) domain.ProtoSyncService {
 	return &ProtoSyncServiceImpl{
-		logger:    logger,
-		fileRepo:  fileRepo,
-		goModRepo: goModRepo,
-		bufRepo:   bufRepo,
+		logger:      logger,
+		fileRepo:    fileRepo,
+		goModRepo:   goModRepo,
+		bufRepo:     bufRepo,
+		shellRunner: shellRunner,
 	}
 }
 
@@ -44,8 +50,92 @@ func (p *ProtoSyncServiceImpl) ValidateConfig(config *domain.SyncConfig) error {
 		return fmt.Errorf("go.mod path is required")
 	}
 
+	switch config.SourceOfTruth {
+	case "", domain.SourceOfTruthGoMod, domain.SourceOfTruthBuf:
+	default:
+		return fmt.Errorf("invalid source of truth %q: must be %s or %s", config.SourceOfTruth, domain.SourceOfTruthGoMod, domain.SourceOfTruthBuf)
+	}
+
 	if config.SourcePath == "" {
-		return fmt.Errorf("source path is required")
+		// Repositories from go.mod always need the global source path
+		if len(config.Repositories) == 0 {
+			return fmt.Errorf("source path is required")
+		}
+		for _, repo := range config.Repositories {
+			if repo.SourcePath == "" {
+				return fmt.Errorf("source path is required for %s: set --source or its sourcePath in the config file", repo.Name)
+			}
+		}
+	}
+
+	for _, target := range append([]string{config.TargetPath}, config.Targets...) {
+		if err := validateTargetTemplate(target); err != nil {
+			return err
+		}
+	}
+	for _, repo := range config.Repositories {
+		if err := validateTargetTemplate(repo.TargetPath); err != nil {
+			return err
+		}
+	}
+
+	if config.Retries < 0 || config.RetryDelay < 0 {
+		return fmt.Errorf("retries and retry delay must not be negative")
+	}
+
+	if config.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
+	if config.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative")
+	}
+
+	if config.LatestPatch && config.SpecifiedVersion != "" {
+		return fmt.Errorf("--latest-patch cannot be combined with --version")
+	}
+
+	if config.Latest && config.SpecifiedVersion != "" {
+		return fmt.Errorf("--latest cannot be combined with --version")
+	}
+
+	if config.Latest && config.LatestPatch {
+		return fmt.Errorf("--latest cannot be combined with --latest-patch")
+	}
+
+	if isVersionConstraint(config.SpecifiedVersion) {
+		if _, err := parseVersionConstraint(config.SpecifiedVersion); err != nil {
+			return err
+		}
+	}
+
+	if err := validateVersion(config.SpecifiedVersion); err != nil {
+		return err
+	}
+	for _, repo := range config.Repositories {
+		if err := validateVersion(repo.Version); err != nil {
+			return fmt.Errorf("%s: %w", repo.Name, err)
+		}
+	}
+
+	if err := validateFilePatterns(config.FilePatterns); err != nil {
+		return err
+	}
+
+	if err := validateIncludePatterns(config.Include); err != nil {
+		return err
+	}
+
+	if err := validateExcludePatterns(config.Exclude); err != nil {
+		return err
+	}
+
+	if _, err := parseSourceRules(config.SourceRules); err != nil {
+		return err
+	}
+
+	if err := validateProtectPatterns(config.Protect); err != nil {
+		return err
 	}
 
 	// Check if required files exist
@@ -57,56 +147,59 @@ func (p *ProtoSyncServiceImpl) ValidateConfig(config *domain.SyncConfig) error {
 		return fmt.Errorf("go.mod file not found at: %s", config.GoModPath)
 	}
 
+	if config.ProtectEdits && config.StateFile == "" {
+		return fmt.Errorf("--protect-edits requires --state-file")
+	}
+
+	if config.Netrc != "" && !p.fileRepo.FileExists(config.Netrc) {
+		return fmt.Errorf("netrc file not found at: %s", config.Netrc)
+	}
+
+	if config.VersionsFile != "" && !p.fileRepo.FileExists(config.VersionsFile) {
+		return fmt.Errorf("versions file not found at: %s", config.VersionsFile)
+	}
+
 	return nil
 }
 
 func (p *ProtoSyncServiceImpl) Sync(ctx context.Context, config *domain.SyncConfig) ([]domain.SyncResult, error) {
 	if err := p.ValidateConfig(config); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+		return nil, domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("invalid configuration: %w", err))
 	}
 
-	// Get target path from buf.yaml
-	moduleInfo, err := p.bufRepo.ParseBufYaml(config.BufYamlPath)
+	repositories, err := p.prepareRepositories(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse buf.yaml: %w", err)
+		return nil, err
 	}
 
-	config.TargetPath = moduleInfo.Path
-	p.logger.Info("Target path from %s: %s", config.BufYamlPath, config.TargetPath)
+	p.logger.Info("Processing %d repository(ies)...", len(repositories))
 
-	// Determine repositories to process
-	repositories := config.Repositories
-	if len(repositories) == 0 {
-		p.logger.Info("Auto-detecting protobuf libraries from %s...", config.GoModPath)
-		goModInfo, err := p.goModRepo.ParseProtobufLibraries(config.GoModPath)
+	run := newSyncRun(config)
+	if config.StateFile != "" {
+		state, err := p.loadEditState(config.StateFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+			return nil, err
 		}
-		repositories = goModInfo.Repositories
+		run.editState = state
 	}
 
-	// Override version if specified
-	if config.SpecifiedVersion != "" {
-		for i := range repositories {
-			repositories[i].Version = config.SpecifiedVersion
+	if config.Atomic && !config.DryRun {
+		if err := p.stageTargets(run, repositories); err != nil {
+			return nil, err
 		}
 	}
 
-	// Process single repo if requested
-	if config.SingleRepo && len(repositories) > 1 {
-		p.logger.Info("Single repo mode: processing only the first repository")
-		repositories = repositories[:1]
+	results := p.processRepositories(ctx, run, repositories)
+	if len(run.staged) > 0 {
+		if err := p.finishAtomic(run, results); err != nil {
+			return results, err
+		}
 	}
+	p.detectOrphans(run, results)
 
-	p.logger.Info("Processing %d repository(ies)...", len(repositories))
-
-	var results []domain.SyncResult
-	for _, repo := range repositories {
-		result := p.processRepository(ctx, repo, config)
-		results = append(results, result)
-
-		if !config.DryRun && result.Error != nil {
-			p.logger.Error("Failed to process repository %s: %v", repo.Name, result.Error)
+	if !config.DryRun && run.editState != nil {
+		if err := p.saveEditState(config.StateFile, run.editState); err != nil {
+			p.logger.Warning("%v", err)
 		}
 	}
 
@@ -118,19 +211,206 @@ func (p *ProtoSyncServiceImpl) Sync(ctx context.Context, config *domain.SyncConf
 			}
 		}
 
+		// Lint what was copied even after partial failures; files stay in
+		// place when lint fails
+		if config.Lint {
+			p.lintTargets(ctx, config, results)
+		}
+
 		if successCount == len(results) {
 			p.logger.Success("All proto files updated successfully!")
-			p.logger.Info("You may want to run 'buf generate' to regenerate code from the updated protos")
+			if config.Manifest != "" {
+				if err := p.writeManifest(config, results); err != nil {
+					return results, err
+				}
+			}
+			for _, dir := range run.backups() {
+				p.logger.Info("Replaced files were backed up to %s", dir)
+			}
+			switch {
+			case config.Generate && domain.LintFailed(results):
+				p.logger.Warning("Skipping buf generate because buf lint failed")
+			case config.Generate:
+				if err := p.bufRepo.Generate(ctx, filepath.Dir(config.BufYamlPath)); err != nil {
+					return results, err
+				}
+			default:
+				p.logger.Info("You may want to run 'buf generate' to regenerate code from the updated protos")
+			}
+			if config.AfterSync != "" {
+				if err := p.runAfterSync(ctx, config, results); err != nil {
+					return results, err
+				}
+			}
 		} else {
 			p.logger.Warning("%d out of %d repositories processed successfully", successCount, len(results))
+			if config.Generate {
+				p.logger.Warning("Skipping buf generate because not every repository synced")
+			}
+			if config.AfterSync != "" {
+				p.logger.Warning("Skipping after-sync hook because not every repository synced")
+			}
+			if config.Manifest != "" {
+				p.logger.Warning("Not writing manifest %s because not every repository synced", config.Manifest)
+			}
 		}
+	} else if config.AfterSync != "" {
+		p.logger.Info("Dry run: would run after-sync hook: %s", config.AfterSync)
 	}
 
 	return results, nil
 }
 
-func (p *ProtoSyncServiceImpl) processRepository(ctx context.Context, repo domain.Repository, config *domain.SyncConfig) domain.SyncResult {
-	result := domain.SyncResult{
+// prepareRepositories resolves the target path and the repositories and
+// versions a run operates on
+func (p *ProtoSyncServiceImpl) prepareRepositories(config *domain.SyncConfig) ([]domain.Repository, error) {
+	// modules stays set only when each repository needs its own buf module
+	var modules []domain.ModuleInfo
+	var err error
+
+	// Get target path from --target or buf.yaml
+	if len(config.Targets) > 0 {
+		config.TargetPath = config.Targets[0]
+		p.logger.Info("Target paths from --target: %s", strings.Join(config.Targets, ", "))
+	} else {
+		modules, err = p.bufRepo.ParseBufModules(config.BufYamlPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse buf.yaml: %w", err)
+		}
+		if err := p.applyDefaultModulePath(modules, config, config.Prune); err != nil {
+			return nil, err
+		}
+
+		module, index, err := selectConfiguredModule(modules, config)
+		if err != nil {
+			return nil, err
+		}
+		if module != nil {
+			config.TargetPath = module.Path
+			p.logger.Info("Target path from %s: %s", config.BufYamlPath, config.TargetPath)
+
+			if err := p.validateTargetPath(config.TargetPath, config.BufYamlPath, index); err != nil {
+				return nil, err
+			}
+			modules = nil
+		}
+	}
+
+	// Determine repositories to process
+	repositories := config.Repositories
+	if len(repositories) == 0 {
+		repositories, err = p.DetectRepositories(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(modules) > 0 {
+		if err := p.assignModules(repositories, modules, config.BufYamlPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.applyVersionsFile(config, repositories); err != nil {
+		return nil, err
+	}
+
+	// Versions from go.mod and --versions-file skip ValidateConfig
+	for _, repo := range repositories {
+		if err := validateVersion(repo.Version); err != nil {
+			return nil, fmt.Errorf("%s: %w", repo.Name, err)
+		}
+	}
+
+	// Resolve ranges given for single repositories, e.g. by repeated
+	// --version flags
+	for i := range repositories {
+		if isVersionConstraint(repositories[i].Version) {
+			if err := p.applyVersionConstraint(repositories[i:i+1], repositories[i].Version); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Override version if specified
+	if isVersionConstraint(config.SpecifiedVersion) {
+		if err := p.applyVersionConstraint(repositories, config.SpecifiedVersion); err != nil {
+			return nil, err
+		}
+	} else if config.SpecifiedVersion != "" {
+		for i := range repositories {
+			if !versionPinned(repositories[i]) {
+				repositories[i].Version = config.SpecifiedVersion
+			}
+		}
+	}
+
+	// Resolve single repositories asking for the newest release, e.g. by
+	// --repo module@latest
+	for i := range repositories {
+		if repositories[i].Version == "latest" {
+			if err := p.applyLatest(repositories[i:i+1], config.StableOnly); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if config.Latest {
+		if err := p.applyLatest(repositories, config.StableOnly); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.LatestPatch {
+		p.applyLatestPatch(repositories)
+	}
+
+	// Sort by module path so output doesn't depend on go.mod ordering
+	if config.SortRepos {
+		sort.SliceStable(repositories, func(i, j int) bool {
+			return repositories[i].Name < repositories[j].Name
+		})
+	}
+
+	// Process single repo if requested
+	if config.SingleRepo && len(repositories) > 1 {
+		p.logger.Info("Single repo mode: processing only the first repository")
+		repositories = repositories[:1]
+	}
+
+	return repositories, nil
+}
+
+// applyVersionsFile pins repositories to the versions in --versions-file
+func (p *ProtoSyncServiceImpl) applyVersionsFile(config *domain.SyncConfig, repositories []domain.Repository) error {
+	if config.VersionsFile == "" {
+		return nil
+	}
+
+	versions, err := p.goModRepo.ParseVersionsFile(config.VersionsFile)
+	if err != nil {
+		return err
+	}
+	for i := range repositories {
+		if versionPinned(repositories[i]) {
+			continue
+		}
+		if version, ok := versions[repositories[i].Name]; ok && version != repositories[i].Version {
+			p.logger.Info("Using %s@%s from %s (go.mod: %s)", repositories[i].Name, version, config.VersionsFile, repositories[i].Version)
+			repositories[i].Version = version
+		}
+	}
+	return nil
+}
+
+func (p *ProtoSyncServiceImpl) processRepository(ctx context.Context, run *syncRun, repo domain.Repository) (result domain.SyncResult) {
+	config := run.config
+	start := time.Now()
+	defer func() {
+		result.Timings.Total = time.Since(start)
+	}()
+
+	result = domain.SyncResult{
 		Repository: repo,
 		Success:    false,
 	}
@@ -138,181 +418,672 @@ func (p *ProtoSyncServiceImpl) processRepository(ctx context.Context, repo domai
 	p.logger.Info("Processing repository: %s", repo.Name)
 
 	if config.DryRun {
-		return p.dryRunRepository(repo, config)
-	}
-
-	// Download the module
-	if err := p.goModRepo.DownloadModule(ctx, repo.Name, repo.Version); err != nil {
-		result.Error = fmt.Errorf("failed to download module: %w", err)
-		return result
+		return p.dryRunRepository(ctx, run, repo)
 	}
 
-	// Get module path
-	modulePath, err := p.goModRepo.GetModulePath(repo.Name, repo.Version)
+	downloadStart := time.Now()
+	sourcePath, err := p.resolveModuleSource(ctx, config, repo)
+	result.Timings.Download = time.Since(downloadStart)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to get module path: %w", err)
+		result.Error = err
 		return result
 	}
 
-	sourcePath := filepath.Join(modulePath, config.SourcePath)
-	if !p.fileRepo.FileExists(sourcePath) {
-		result.Error = fmt.Errorf("source directory not found: %s", sourcePath)
+	if err := p.checkMergeFiles(run, repo, sourcePath); err != nil {
+		result.Error = err
 		return result
 	}
 
-	// Create target directory if it doesn't exist
-	if !p.fileRepo.FileExists(config.TargetPath) {
-		p.logger.Info("Creating target directory: %s", config.TargetPath)
-		if err := p.fileRepo.CreateDir(config.TargetPath); err != nil {
-			result.Error = fmt.Errorf("failed to create target directory: %w", err)
-			return result
+	copyStart := time.Now()
+	defer func() {
+		result.Timings.Copy = time.Since(copyStart)
+	}()
+
+	// Create target directories if they don't exist
+	targets := run.stagedTargets(targetPaths(config, repo))
+	for _, target := range targets {
+		if !p.fileRepo.FileExists(target) {
+			p.logger.Info("Creating target directory: %s", target)
+			if err := p.fileRepo.CreateDir(target); err != nil {
+				result.Error = domain.WithCode(domain.ErrorCodeCopyFailed, fmt.Errorf("failed to create target directory: %w", err))
+				return result
+			}
 		}
 	}
 
-	// Copy proto files
-	if config.SpecificFile != "" {
-		file, err := p.copySpecificFile(sourcePath, config.TargetPath, config.SpecificFile)
+	// Copy proto files, keeping the files already copied when a copy fails
+	// or the sync is cancelled partway
+	if len(config.SpecificFiles) > 0 {
+		files, err := p.copySpecificFiles(ctx, run, sourcePath, targets...)
+		result.FilesUpdated = files
 		if err != nil {
-			result.Error = err
+			result.Error = domain.WithDefaultCode(domain.ErrorCodeCopyFailed, err)
 			return result
 		}
-		result.FilesUpdated = []domain.ProtoFile{file}
 	} else {
-		files, err := p.copyAllProtoFiles(sourcePath, config.TargetPath)
+		files, err := p.copyAllProtoFiles(ctx, run, sourcePath, targets...)
+		result.FilesUpdated = files
 		if err != nil {
-			result.Error = err
+			result.Error = domain.WithDefaultCode(domain.ErrorCodeCopyFailed, err)
 			return result
 		}
-		result.FilesUpdated = files
+
+		if config.VerifyCount {
+			if err := p.verifyCopiedFiles(config, sourcePath, files, targets...); err != nil {
+				result.Error = err
+				return result
+			}
+		}
+	}
+
+	if config.CheckPackagePath {
+		result.Warnings = append(result.Warnings, p.checkPackagePaths(targets[0], result.FilesUpdated)...)
 	}
 
 	result.Success = true
 	return result
 }
 
-func (p *ProtoSyncServiceImpl) dryRunRepository(repo domain.Repository, config *domain.SyncConfig) domain.SyncResult {
+// checkSourceInModCache guards against reading protos from outside the module
+// cache, e.g. through a symlink or a crafted replace directive
+func (p *ProtoSyncServiceImpl) checkSourceInModCache(sourcePath string) error {
+	modCache, err := p.goModRepo.GetModCacheDir()
+	if err != nil {
+		return fmt.Errorf("failed to resolve module cache for source check: %w", err)
+	}
+
+	cacheRoot, err := p.fileRepo.ResolvePath(modCache)
+	if err != nil {
+		return fmt.Errorf("failed to resolve module cache for source check: %w", err)
+	}
+
+	resolvedSource, err := p.fileRepo.ResolvePath(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve source path for source check: %w", err)
+	}
+
+	if !isWithin(cacheRoot, resolvedSource) {
+		p.logger.Warning("Source path %s resolves to %s, outside module cache %s", sourcePath, resolvedSource, cacheRoot)
+		return domain.WithCode(domain.ErrorCodeSourceOutsideCache, fmt.Errorf("source path %s escapes the module cache %s", resolvedSource, cacheRoot))
+	}
+
+	return nil
+}
+
+// isWithin reports whether path is root itself or located below it
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// resolveModuleSource returns the directory holding the repository's protos,
+// serving it from the file cache when possible and downloading otherwise
+func (p *ProtoSyncServiceImpl) resolveModuleSource(ctx context.Context, config *domain.SyncConfig, repo domain.Repository) (string, error) {
+	sourceSubPath := p.resolveSourcePath(repo, config)
+	if repo.LocalPath != "" {
+		return p.localSourcePath(config, repo, sourceSubPath)
+	}
+
+	// Cached protos can't be checked against go.sum, and live outside
+	// GOMODCACHE by design, so --verify-sum and --source-readonly-check
+	// always download
+	if cachedPath, ok := p.cachedSourcePath(config, repo, sourceSubPath); ok && !config.VerifySum && !config.SourceReadonlyCheck {
+		p.logger.Info("Using cached protos for %s@%s from %s", repo.Name, repo.Version, cachedPath)
+		return cachedPath, nil
+	}
+
+	// Download the module
+	modulePath, err := p.goModRepo.DownloadModule(ctx, repo.Name, repo.Version, config.DownloadOptions())
+	if err != nil {
+		return "", domain.WithDefaultCode(domain.ErrorCodeDownloadFailed, fmt.Errorf("failed to download module: %w", err))
+	}
+
+	if config.VerifySum {
+		if err := p.verifyModuleSum(config, repo, modulePath); err != nil {
+			return "", err
+		}
+	}
+
+	sourcePath := filepath.Join(modulePath, sourceSubPath)
+	if !p.fileRepo.FileExists(sourcePath) {
+		return "", domain.WithCode(domain.ErrorCodeSourceNotFound, fmt.Errorf("source directory not found: %s", sourcePath))
+	}
+
+	if config.SourceReadonlyCheck {
+		if err := p.checkSourceInModCache(sourcePath); err != nil {
+			return "", err
+		}
+	}
+
+	p.populateFileCache(config, repo, sourceSubPath, sourcePath)
+
+	return sourcePath, nil
+}
+
+// dryRunRepository logs the actions a sync would take. Files are compared
+// with their targets when the module is already on disk; --dry-run-diff
+// downloads it first so the comparison covers every file.
+func (p *ProtoSyncServiceImpl) dryRunRepository(ctx context.Context, run *syncRun, repo domain.Repository) domain.SyncResult {
+	config := run.config
 	result := domain.SyncResult{
 		Repository: repo,
 		Success:    true,
 	}
 
 	p.logger.Info("DRY RUN MODE - Actions that would be performed:")
-	fmt.Printf("  1. Download: go mod download %s@%s\n", repo.Name, repo.Version)
+	sourceSubPath := p.resolveSourcePath(repo, config)
+	var sourcePath string
+	var cached bool
+	if repo.LocalPath != "" {
+		p.logger.Plain("  1. Use local replace: %s", repo.LocalPath)
+		sourcePath, cached = filepath.Join(repo.LocalPath, sourceSubPath), true
+	} else if config.DryRunDiff {
+		p.logger.Plain("  1. Download for comparison: go mod download %s@%s", repo.Name, repo.Version)
+		downloaded, err := p.resolveModuleSource(ctx, config, repo)
+		if err != nil {
+			p.logger.Error("  2. Error downloading module: %v", err)
+			result.Success = false
+			result.Error = err
+			return result
+		}
+		sourcePath, cached = downloaded, true
+	} else {
+		p.logger.Plain("  1. Download: go mod download %s@%s", repo.Name, repo.Version)
+		sourcePath, cached = p.cachedSourcePath(config, repo, sourceSubPath)
+	}
+	if !cached {
+		modulePath, err := p.goModRepo.GetModulePath(repo.Name, repo.Version)
+		if err != nil {
+			p.logger.Error("  2. Error getting module path: %v", err)
+			result.Success = false
+			result.Error = domain.WithDefaultCode(domain.ErrorCodeDownloadFailed, fmt.Errorf("failed to get module path: %w", err))
+			return result
+		}
+		sourcePath = filepath.Join(modulePath, sourceSubPath)
+	}
+
+	p.logger.Plain("  2. Source directory: %s", sourcePath)
+	targets := targetPaths(config, repo)
+	p.logger.Plain("  3. Target directory: %s", strings.Join(targets, ", "))
 
-	modulePath, err := p.goModRepo.GetModulePath(repo.Name, repo.Version)
+	ignores, err := p.loadTargetIgnores(targets)
 	if err != nil {
-		fmt.Printf("  2. Error getting module path: %v\n", err)
-		return result
+		p.logger.Warning("     Error reading %s: %v", ignoreFileName, err)
 	}
 
-	sourcePath := filepath.Join(modulePath, config.SourcePath)
-	fmt.Printf("  2. Source directory: %s\n", sourcePath)
-	fmt.Printf("  3. Target directory: %s\n", config.TargetPath)
-
 	if p.fileRepo.FileExists(sourcePath) {
-		if config.SpecificFile != "" {
-			fmt.Printf("  4. Specific proto file that would be copied:\n")
-			if p.fileRepo.FileExists(filepath.Join(sourcePath, config.SpecificFile)) {
-				fmt.Printf("     - %s\n", config.SpecificFile)
-			} else {
-				fmt.Printf("     - %s (NOT FOUND - would fail)\n", config.SpecificFile)
+		if len(config.SpecificFiles) > 0 {
+			p.logger.Plain("  4. Specific proto files that would be copied:")
+			files, err := p.specificSourceFiles(sourcePath, config)
+			if err != nil {
+				p.logger.Warning("     %v (would fail)", err)
+			}
+			for _, sourceFile := range files {
+				file := p.previewFile(ctx, run, sourceFile.Name, sourceFile.Path, targets)
+				result.FilesUpdated = append(result.FilesUpdated, file)
+				p.logger.Plain("     - %s (%s)", file.Name, dryRunAction(file.Change))
 			}
 		} else {
-			fmt.Printf("  4. Proto files that would be copied:\n")
-			files, err := p.fileRepo.ListFiles(sourcePath, "*.proto")
+			p.logger.Plain("  4. Proto files that would be copied:")
+			files, err := p.listSourceFiles(sourcePath, config)
 			if err != nil {
-				fmt.Printf("     Error listing files: %v\n", err)
+				p.logger.Warning("     Error listing files: %v", err)
 			} else {
-				for _, file := range files {
-					fmt.Printf("     - %s\n", file.Name)
+				for _, sourceFile := range files {
+					name := targetName(sourcePath, sourceFile, config)
+					allowed := p.allowedTargets(ignores, name, targets)
+					if len(allowed) == 0 {
+						p.logger.Plain("     - %s (ignored by %s)", name, ignoreFileName)
+						continue
+					}
+					if !modifiedSince(config, sourceFile) {
+						p.logger.Plain("     - %s (not modified since %s, skipped)", name, config.Since.Format(time.RFC3339))
+						continue
+					}
+					file := p.previewFile(ctx, run, name, sourceFile.Path, allowed)
+					result.FilesUpdated = append(result.FilesUpdated, file)
+					p.logger.Plain("     - %s (%s)", file.Name, dryRunAction(file.Change))
 				}
 			}
 		}
 	} else {
-		fmt.Printf("  4. Source directory does not exist yet (would be created by download)\n")
+		p.logger.Plain("  4. Source directory does not exist yet (would be created by download); use --dry-run-diff to compare its files")
+		result.ChangesUnknown = true
 	}
 
 	return result
 }
 
-func (p *ProtoSyncServiceImpl) copySpecificFile(sourcePath, targetPath, fileName string) (domain.ProtoFile, error) {
+// dryRunAction describes what syncing a file with change would do
+func dryRunAction(change domain.ChangeType) string {
+	switch change {
+	case domain.ChangeAdded:
+		return "new file"
+	case domain.ChangeUnchanged:
+		return "would overwrite (identical)"
+	default:
+		return "would overwrite (modified)"
+	}
+}
+
+// previewFile classifies what copying sourceFile into each target would do
+// without writing anything. The content is the one a sync would write, so
+// --transform runs.
+func (p *ProtoSyncServiceImpl) previewFile(ctx context.Context, run *syncRun, name, sourceFile string, targets []string) domain.ProtoFile {
+	file := domain.ProtoFile{Name: name, Path: filepath.Join(targets[0], name), Targets: targets}
+
+	data, err := p.fileContent(ctx, run, sourceFile)
+	if err != nil {
+		p.logger.Warning("     %v", err)
+		file.Change = domain.ChangeModified
+		return file
+	}
+
+	for _, target := range targets {
+		file.Change = domain.CombineChange(file.Change, p.classifyContent(data, filepath.Join(target, name)))
+	}
+	return file
+}
+
+// copySpecificFiles copies every --proto-file file and every file matching
+// a --proto-file glob. Nothing is copied unless all of them are found. Glob
+// matches ignored in every target are skipped.
+func (p *ProtoSyncServiceImpl) copySpecificFiles(ctx context.Context, run *syncRun, sourcePath string, targets ...string) ([]domain.ProtoFile, error) {
+	matches, err := p.specificSourceFiles(sourcePath, run.config)
+	if err != nil {
+		return nil, err
+	}
+	ignores, err := p.loadTargetIgnores(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	named := make(map[string]bool)
+	for _, name := range run.config.SpecificFiles {
+		if !isFileGlob(name) {
+			named[name] = true
+		}
+	}
+
+	if len(matches) > 1 {
+		p.logger.Info("%d proto files selected by %s", len(matches), strings.Join(run.config.SpecificFiles, ", "))
+	}
+	var files []domain.ProtoFile
+	for _, match := range matches {
+		if err := ctx.Err(); err != nil {
+			return files, copyCancelled(ctx, len(files), len(matches))
+		}
+		if !named[match.Name] && len(p.allowedTargets(ignores, match.Name, targets)) == 0 {
+			p.logger.Info("Skipping %s: ignored by %s", match.Name, ignoreFileName)
+			continue
+		}
+		file, err := p.copySpecificFile(ctx, run, sourcePath, match.Name, targets...)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (p *ProtoSyncServiceImpl) copySpecificFile(ctx context.Context, run *syncRun, sourcePath, fileName string, targets ...string) (domain.ProtoFile, error) {
+	// --proto-file names a file below the source; anything else could
+	// read from, and write to, outside the source and targets
+	if !filepath.IsLocal(fileName) {
+		return domain.ProtoFile{}, domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("proto file %q must be a relative path inside the source directory", fileName))
+	}
 	sourceFile := filepath.Join(sourcePath, fileName)
-	targetFile := filepath.Join(targetPath, fileName)
 
 	if !p.fileRepo.FileExists(sourceFile) {
 		// List available files for user reference
-		availableFiles, _ := p.fileRepo.ListFiles(sourcePath, "*.proto")
-		fileNames := make([]string, len(availableFiles))
-		for i, file := range availableFiles {
-			fileNames[i] = file.Name
-		}
-
-		return domain.ProtoFile{}, fmt.Errorf("specific proto file not found: %s\nAvailable proto files: %s",
-			sourceFile, strings.Join(fileNames, ", "))
+		availableFiles, _ := p.listSourceFiles(sourcePath, run.config)
+		return domain.ProtoFile{}, domain.WithCode(domain.ErrorCodeFileNotFound, fmt.Errorf("specific proto file not found: %s\nAvailable proto files: %s",
+			sourceFile, strings.Join(fileNames(availableFiles), ", ")))
 	}
 
-	// Make target file writable if it exists
-	if p.fileRepo.FileExists(targetFile) {
-		if err := p.fileRepo.MakeWritable(targetFile); err != nil {
-			return domain.ProtoFile{}, fmt.Errorf("failed to make target file writable: %w", err)
-		}
+	ignores, err := p.loadTargetIgnores(targets)
+	if err != nil {
+		return domain.ProtoFile{}, err
+	}
+	targets = p.allowedTargets(ignores, fileName, targets)
+	if len(targets) == 0 {
+		return domain.ProtoFile{}, fmt.Errorf("%s is ignored by %s in every target", fileName, ignoreFileName)
 	}
 
 	p.logger.Info("Copying specific proto file: %s", fileName)
-	if err := p.fileRepo.CopyFile(sourceFile, targetFile); err != nil {
+	file, err := p.installToTargets(ctx, run, sourceFile, fileName, targets)
+	if err != nil {
 		return domain.ProtoFile{}, fmt.Errorf("failed to copy file: %w", err)
 	}
 
 	p.logger.Success("Successfully copied proto file: %s", fileName)
+	run.report(domain.ProgressEvent{Event: domain.ProgressFileCopied, Name: fileName, Change: file.Change})
+
+	return file, nil
+}
 
-	return domain.ProtoFile{
-		Name: fileName,
-		Path: targetFile,
-	}, nil
+// copyCancelled is the error a copy loop stops with when ctx is done
+func copyCancelled(ctx context.Context, copied, total int) error {
+	return domain.WithCode(domain.ErrorCodeCancelled, fmt.Errorf("copy cancelled after %d of %d file(s): %w", copied, total, context.Cause(ctx)))
 }
 
-func (p *ProtoSyncServiceImpl) copyAllProtoFiles(sourcePath, targetPath string) ([]domain.ProtoFile, error) {
-	sourceFiles, err := p.fileRepo.ListFiles(sourcePath, "*.proto")
+func (p *ProtoSyncServiceImpl) copyAllProtoFiles(ctx context.Context, run *syncRun, sourcePath string, targets ...string) ([]domain.ProtoFile, error) {
+	sourceFiles, err := p.listSourceFiles(sourcePath, run.config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list proto files: %w", err)
 	}
 
+	if len(sourceFiles) == 0 && len(run.config.Include) > 0 {
+		return nil, domain.WithCode(domain.ErrorCodeNoFilesMatched, fmt.Errorf("no files in %s matched --include patterns %s", sourcePath, strings.Join(run.config.Include, ", ")))
+	}
+
 	if len(sourceFiles) == 0 {
-		p.logger.Warning("No .proto files found in %s", sourcePath)
+		p.logger.Warning("No files matching %s found in %s", strings.Join(filePatterns(run.config), ", "), sourcePath)
 		return []domain.ProtoFile{}, nil
 	}
 
-	p.logger.Info("Copying %d proto file(s) from %s to %s...", len(sourceFiles), sourcePath, targetPath)
+	sourceFiles = p.filterSince(run.config, sourcePath, sourceFiles)
+	if len(sourceFiles) == 0 {
+		return []domain.ProtoFile{}, nil
+	}
 
-	// Make all existing proto files writable before copying
-	existingFiles, _ := p.fileRepo.ListFiles(targetPath, "*.proto")
-	for _, file := range existingFiles {
-		if err := p.fileRepo.MakeWritable(file.Path); err != nil {
-			p.logger.Warning("Failed to make file writable: %s", file.Path)
-		}
+	p.logger.Info("Copying %d proto file(s) from %s to %s...", len(sourceFiles), sourcePath, strings.Join(targets, ", "))
+
+	ignores, err := p.loadTargetIgnores(targets)
+	if err != nil {
+		return nil, err
 	}
 
 	var copiedFiles []domain.ProtoFile
-	for _, sourceFile := range sourceFiles {
-		targetFile := filepath.Join(targetPath, sourceFile.Name)
-		if err := p.fileRepo.CopyFile(sourceFile.Path, targetFile); err != nil {
-			return copiedFiles, fmt.Errorf("failed to copy %s: %w", sourceFile.Name, err)
+	for i, sourceFile := range sourceFiles {
+		if err := ctx.Err(); err != nil {
+			return copiedFiles, copyCancelled(ctx, len(copiedFiles), len(sourceFiles))
+		}
+		name := targetName(sourcePath, sourceFile, run.config)
+		allowed := p.allowedTargets(ignores, name, targets)
+		if len(allowed) == 0 {
+			continue
 		}
 
-		copiedFiles = append(copiedFiles, domain.ProtoFile{
-			Name: sourceFile.Name,
-			Path: targetFile,
-		})
+		file, err := p.installToTargets(ctx, run, sourceFile.Path, name, allowed)
+		if err != nil {
+			return copiedFiles, fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+
+		copiedFiles = append(copiedFiles, file)
+		run.report(domain.ProgressEvent{Event: domain.ProgressFileCopied, Name: name, Change: file.Change, Copied: i + 1, Total: len(sourceFiles)})
 	}
 
-	p.logger.Success("Successfully copied proto files:")
-	for _, file := range copiedFiles {
-		fmt.Printf("  - %s\n", file.Name)
+	skipped := domain.SyncResult{FilesUpdated: copiedFiles}.SkippedFiles()
+	if run.config.ProgressBar {
+		p.logger.Success("Successfully synced %d proto file(s): %d copied, %d identical skipped", len(copiedFiles), len(copiedFiles)-skipped, skipped)
+		for _, file := range copiedFiles {
+			p.logger.Debug("  - %s (%s)", file.Name, file.Change)
+		}
+	} else {
+		p.logger.Success("Successfully synced proto files (%d copied, %d identical skipped):", len(copiedFiles)-skipped, skipped)
+		for _, file := range copiedFiles {
+			p.logger.Info("  - %s (%s)", file.Name, file.Change)
+		}
 	}
 
 	return copiedFiles, nil
 }
 
+// installToTargets installs sourceFile as name under every target,
+// creating nested directories as needed. The content is read (and
+// transformed) once. The returned file's Path is the copy in the first
+// target.
+func (p *ProtoSyncServiceImpl) installToTargets(ctx context.Context, run *syncRun, sourceFile, name string, targets []string) (domain.ProtoFile, error) {
+	file := domain.ProtoFile{Name: name, Path: filepath.Join(targets[0], name)}
+
+	data, err := p.fileContent(ctx, run, sourceFile)
+	if err != nil {
+		return file, err
+	}
+	file.Size = int64(len(data))
+	file.SHA256 = hashContent(data)
+
+	file.Skipped = true
+	for _, target := range targets {
+		targetFile := filepath.Join(target, name)
+		if dir := filepath.Dir(targetFile); dir != filepath.Clean(target) {
+			if err := p.fileRepo.CreateDir(dir); err != nil {
+				return file, fmt.Errorf("failed to create directory %s: %w", dir, err)
+			}
+		}
+
+		change, err := p.writeTarget(run, sourceFile, target, name, data)
+		if err != nil {
+			return file, err
+		}
+		file.Change = domain.CombineChange(file.Change, change)
+		file.Targets = append(file.Targets, target)
+		file.Skipped = file.Skipped && skipsWrite(run.config, change)
+	}
+
+	// Report the written file: CopyFile preserves the source mtime, while
+	// transformed files carry the time they were written
+	if modTime, err := p.fileRepo.ModTime(file.Path); err == nil {
+		file.ModifiedTime = modTime
+	}
+
+	return file, nil
+}
+
+// writeTarget backs up and writes name into target, holding the target
+// file's lock so concurrent repositories providing the same file don't
+// interleave their writes
+func (p *ProtoSyncServiceImpl) writeTarget(run *syncRun, sourceFile, target, name string, data []byte) (domain.ChangeType, error) {
+	targetFile := filepath.Join(target, name)
+	unlock := run.lockPath(targetFile)
+	defer unlock()
+
+	if run.config.Backup {
+		if err := p.backupFile(run, target, name, data); err != nil {
+			return domain.ChangeUnchanged, err
+		}
+	}
+
+	return p.writeContent(run, sourceFile, targetFile, data)
+}
+
+// verifyCopiedFiles re-lists the source after copying and reports files that
+// vanished or appeared compared to what was copied, catching partial syncs.
+// Files every target's .protosyncignore skips were never meant to be copied.
+func (p *ProtoSyncServiceImpl) verifyCopiedFiles(config *domain.SyncConfig, sourcePath string, copied []domain.ProtoFile, targets ...string) error {
+	current, err := p.listSourceFiles(sourcePath, config)
+	if err != nil {
+		return fmt.Errorf("failed to re-list source files for verification: %w", err)
+	}
+	ignores, err := p.loadTargetIgnores(targets)
+	if err != nil {
+		return err
+	}
+
+	copiedNames := make(map[string]bool, len(copied))
+	for _, file := range copied {
+		copiedNames[file.Name] = true
+	}
+
+	currentNames := make(map[string]bool, len(current))
+	var notCopied []string
+	for _, file := range current {
+		name := targetName(sourcePath, file, config)
+		currentNames[name] = true
+		if !copiedNames[name] && modifiedSince(config, file) && !ignores.ignoredByAll(targets, name) {
+			notCopied = append(notCopied, name)
+		}
+	}
+
+	var vanished []string
+	for _, file := range copied {
+		if !currentNames[file.Name] {
+			vanished = append(vanished, file.Name)
+		}
+	}
+
+	if len(notCopied) == 0 && len(vanished) == 0 {
+		p.logger.Info("Verified %d copied file(s) against source", len(copied))
+		return nil
+	}
+
+	var details []string
+	if len(vanished) > 0 {
+		details = append(details, "no longer in source: "+strings.Join(vanished, ", "))
+	}
+	if len(notCopied) > 0 {
+		details = append(details, "not copied: "+strings.Join(notCopied, ", "))
+	}
+	return domain.WithCode(domain.ErrorCodeVerificationFailed, fmt.Errorf("copied %d file(s) but source now lists %d (%s)", len(copied), len(current), strings.Join(details, "; ")))
+}
+
+// fileContent returns the content sourceFile is installed with: the file
+// itself or the output of the --transform command
+func (p *ProtoSyncServiceImpl) fileContent(ctx context.Context, run *syncRun, sourceFile string) ([]byte, error) {
+	if run.config.Transform != "" {
+		return p.transformFile(ctx, run.config.Transform, sourceFile)
+	}
+
+	data, err := p.fileRepo.ReadFile(sourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", sourceFile, err)
+	}
+	return data, nil
+}
+
+// writeContent writes data, previously obtained from fileContent, to
+// targetFile after checking for local edits
+func (p *ProtoSyncServiceImpl) writeContent(run *syncRun, sourceFile, targetFile string, data []byte) (domain.ChangeType, error) {
+	change := p.classifyContent(data, targetFile)
+	if err := p.checkLocalEdits(run, targetFile, data); err != nil {
+		return change, err
+	}
+
+	// Identical targets are left alone so their mtime and permissions don't
+	// churn
+	if skipsWrite(run.config, change) {
+		p.logger.Debug("Skipping %s: already identical", targetFile)
+		run.recordWrite(targetFile, data)
+		return change, nil
+	}
+
+	// Existing targets may be read-only
+	if change != domain.ChangeAdded {
+		if err := p.fileRepo.MakeWritable(targetFile); err != nil {
+			return change, fmt.Errorf("failed to make target file writable: %w", err)
+		}
+	}
+
+	if run.config.Transform != "" {
+		if err := p.fileRepo.CreateDir(filepath.Dir(targetFile)); err != nil {
+			return change, fmt.Errorf("failed to create destination directory: %w", err)
+		}
+		if err := p.fileRepo.WriteFile(targetFile, data); err != nil {
+			return change, err
+		}
+	} else if err := p.fileRepo.CopyFile(sourceFile, targetFile); err != nil {
+		return change, err
+	}
+
+	if run.config.Verify {
+		if err := p.verifyWrite(run, sourceFile, targetFile, data); err != nil {
+			return change, domain.WithCode(domain.ErrorCodeVerificationFailed, err)
+		}
+	}
+
+	run.recordWrite(targetFile, data)
+	return change, nil
+}
+
+// skipsWrite reports whether a target with change is left untouched: it is
+// already identical and --force is not set
+func skipsWrite(config *domain.SyncConfig, change domain.ChangeType) bool {
+	return change == domain.ChangeUnchanged && !config.Force
+}
+
+// verifyWrite checks targetFile against what was meant to be written:
+// the source itself, or the transform output
+func (p *ProtoSyncServiceImpl) verifyWrite(run *syncRun, sourceFile, targetFile string, data []byte) error {
+	if run.config.Transform == "" {
+		return p.fileRepo.VerifyCopy(sourceFile, targetFile)
+	}
+
+	written, err := p.fileRepo.ReadFile(targetFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s for verification: %w", targetFile, err)
+	}
+	if want, got := hashContent(data), hashContent(written); want != got {
+		return fmt.Errorf("write verification failed for %s: expected sha256 %s, destination sha256 %s", targetFile, want, got)
+	}
+	return nil
+}
+
+// transformFile pipes the content of sourceFile through command
+func (p *ProtoSyncServiceImpl) transformFile(ctx context.Context, command, sourceFile string) ([]byte, error) {
+	data, err := p.fileRepo.ReadFile(sourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", sourceFile, err)
+	}
+
+	env := []string{
+		"PROTO_SYNC_FILE=" + filepath.Base(sourceFile),
+		"PROTO_SYNC_SOURCE=" + sourceFile,
+	}
+	out, err := p.shellRunner.Run(ctx, command, env, data)
+	if err != nil {
+		return nil, fmt.Errorf("transform failed for %s: %w", filepath.Base(sourceFile), err)
+	}
+
+	return out, nil
+}
+
+// classifyContent compares incoming data with the current target
+func (p *ProtoSyncServiceImpl) classifyContent(data []byte, targetFile string) domain.ChangeType {
+	if !p.fileRepo.FileExists(targetFile) {
+		return domain.ChangeAdded
+	}
+
+	targetData, err := p.fileRepo.ReadFile(targetFile)
+	if err != nil {
+		return domain.ChangeModified
+	}
+
+	if bytes.Equal(data, targetData) {
+		return domain.ChangeUnchanged
+	}
+	return domain.ChangeModified
+}
+
+// ModuleCacheDir returns the GOMODCACHE directory modules are downloaded to
+func (p *ProtoSyncServiceImpl) ModuleCacheDir() (string, error) {
+	return p.goModRepo.GetModCacheDir()
+}
+
+func (p *ProtoSyncServiceImpl) DetectRepositories(config *domain.SyncConfig) ([]domain.Repository, error) {
+	if config.SourceOfTruth == domain.SourceOfTruthBuf {
+		p.logger.Info("Auto-detecting protobuf libraries from buf dependencies of %s...", config.BufYamlPath)
+		repositories, err := p.bufRepo.ParseDependencies(config.BufYamlPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse buf dependencies: %w", err)
+		}
+		if len(repositories) == 0 {
+			return nil, fmt.Errorf("no buf dependency of %s can be downloaded as a Go module; pass the modules to sync with --repo", config.BufYamlPath)
+		}
+		return repositories, nil
+	}
+
+	p.logger.Info("Auto-detecting protobuf libraries from %s...", config.GoModPath)
+	goModInfo, err := p.goModRepo.ParseProtobufLibraries(config.GoModPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	return goModInfo.Repositories, nil
+}
+
 func (p *ProtoSyncServiceImpl) ListVersions(ctx context.Context, repositories []domain.Repository) (map[string][]string, error) {
 	result := make(map[string][]string)
 
@@ -0,0 +1,650 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/Francouer/proto-sync/internal/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		name string
+		root string
+		path string
+		want bool
+	}{
+		{name: "same directory", root: "/cache", path: "/cache", want: true},
+		{name: "nested", root: "/cache", path: "/cache/github.com/org/api@v1.0.0", want: true},
+		{name: "sibling with shared prefix", root: "/cache", path: "/cache-other/api", want: false},
+		{name: "parent", root: "/cache/mod", path: "/cache", want: false},
+		{name: "dot-dot prefixed name", root: "/cache", path: "/cache/..api", want: true},
+		{name: "unrelated", root: "/cache", path: "/home/user/protos", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isWithin(tt.root, tt.path))
+		})
+	}
+}
+
+// nopLogger discards all log output in tests
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, args ...interface{})    {}
+func (nopLogger) Success(msg string, args ...interface{}) {}
+func (nopLogger) Warning(msg string, args ...interface{}) {}
+func (nopLogger) Error(msg string, args ...interface{})   {}
+func (nopLogger) Debug(msg string, args ...interface{})   {}
+func (nopLogger) Plain(msg string, args ...interface{})   {}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// newDiskService returns a service working on the real filesystem;
+// goModRepo may be nil for tests that never resolve a module
+func newDiskService(goModRepo domain.GoModRepository) *ProtoSyncServiceImpl {
+	return &ProtoSyncServiceImpl{logger: nopLogger{}, fileRepo: infrastructure.NewFileRepository(nopLogger{}), goModRepo: goModRepo}
+}
+
+func newMemService() (*ProtoSyncServiceImpl, *infrastructure.MemFileRepository) {
+	fileRepo := infrastructure.NewMemFileRepository()
+	return &ProtoSyncServiceImpl{logger: nopLogger{}, fileRepo: fileRepo}, fileRepo
+}
+
+func TestInstallToTargetsTransform(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src", "orders.proto")
+	target := filepath.Join(dir, "dst", "orders.proto")
+	writeFile(t, source, "package internal;\n")
+
+	service := &ProtoSyncServiceImpl{
+		logger:      nopLogger{},
+		fileRepo:    infrastructure.NewFileRepository(nopLogger{}),
+		shellRunner: infrastructure.NewShellRunner(nopLogger{}),
+	}
+	config := &domain.SyncConfig{Transform: `sed "s/internal/public/"; echo "// $PROTO_SYNC_FILE"`}
+	run := newSyncRun(config)
+
+	file, err := service.installToTargets(context.Background(), run, source, "orders.proto", []string{filepath.Dir(target)})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeAdded, file.Change)
+
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "package public;\n// orders.proto\n", string(data))
+
+	file, err = service.installToTargets(context.Background(), run, source, "orders.proto", []string{filepath.Dir(target)})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeUnchanged, file.Change)
+
+	config.Transform = "exit 3"
+	_, err = service.installToTargets(context.Background(), run, source, "orders.proto", []string{filepath.Dir(target)})
+	assert.Error(t, err)
+}
+
+func TestCopyAllProtoFilesRecursive(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src")
+	writeFile(t, filepath.Join(source, "common", "types.proto"), "package common;")
+	writeFile(t, filepath.Join(source, "api", "v1", "foo.proto"), "package api.v1;")
+
+	service := newDiskService(nil)
+
+	flat := filepath.Join(dir, "flat")
+	copied, err := service.copyAllProtoFiles(context.Background(), newSyncRun(&domain.SyncConfig{}), source, flat)
+	require.NoError(t, err)
+	require.Len(t, copied, 2)
+	assert.FileExists(t, filepath.Join(flat, "foo.proto"))
+	assert.FileExists(t, filepath.Join(flat, "types.proto"))
+
+	config := &domain.SyncConfig{Recursive: true}
+	nested := filepath.Join(dir, "nested")
+	copied, err = service.copyAllProtoFiles(context.Background(), newSyncRun(config), source, nested)
+	require.NoError(t, err)
+	require.Len(t, copied, 2)
+	assert.FileExists(t, filepath.Join(nested, "api", "v1", "foo.proto"))
+	assert.FileExists(t, filepath.Join(nested, "common", "types.proto"))
+	assert.Equal(t, filepath.Join("api", "v1", "foo.proto"), copied[0].Name)
+
+	require.NoError(t, service.verifyCopiedFiles(config, source, copied))
+}
+
+func TestCopyAllProtoFilesMultipleTargets(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src")
+	gateway := filepath.Join(dir, "gateway")
+	internal := filepath.Join(dir, "internal")
+	writeFile(t, filepath.Join(source, "orders.proto"), "package orders;")
+	writeFile(t, filepath.Join(gateway, "orders.proto"), "package orders;")
+
+	service := newDiskService(nil)
+
+	copied, err := service.copyAllProtoFiles(context.Background(), newSyncRun(&domain.SyncConfig{}), source, gateway, internal)
+	require.NoError(t, err)
+	require.Len(t, copied, 1)
+	assert.Equal(t, []string{gateway, internal}, copied[0].Targets)
+	assert.Equal(t, domain.ChangeAdded, copied[0].Change)
+	assert.FileExists(t, filepath.Join(internal, "orders.proto"))
+}
+
+func TestVerifyCopiedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.proto"), "a")
+	writeFile(t, filepath.Join(dir, "b.proto"), "b")
+
+	service := newDiskService(nil)
+
+	require.NoError(t, service.verifyCopiedFiles(&domain.SyncConfig{}, dir, []domain.ProtoFile{{Name: "a.proto"}, {Name: "b.proto"}}))
+
+	err := service.verifyCopiedFiles(&domain.SyncConfig{}, dir, []domain.ProtoFile{{Name: "a.proto"}, {Name: "gone.proto"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no longer in source: gone.proto")
+	assert.Contains(t, err.Error(), "not copied: b.proto")
+}
+
+// fakeGoModRepo serves modules from a fixed directory and counts downloads
+type fakeGoModRepo struct {
+	moduleDir string
+	versions  []string
+	// failing lists repositories whose download fails
+	failing map[string]bool
+	// hanging lists repositories whose download blocks until ctx is done
+	hanging map[string]bool
+
+	mu        sync.Mutex
+	downloads int
+}
+
+func (f *fakeGoModRepo) ParseProtobufLibraries(goModPath string) (*domain.GoModInfo, error) {
+	return &domain.GoModInfo{}, nil
+}
+
+func (f *fakeGoModRepo) ParseVersionsFile(path string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (f *fakeGoModRepo) ParseGoSum(goSumPath string) (map[string]string, error) {
+	return infrastructure.NewGoModRepository(nopLogger{}, nil, nil).ParseGoSum(goSumPath)
+}
+
+func (f *fakeGoModRepo) HashModuleDir(dir, repo, version string) (string, error) {
+	return infrastructure.NewGoModRepository(nopLogger{}, nil, nil).HashModuleDir(dir, repo, version)
+}
+
+func (f *fakeGoModRepo) GetLatestVersion(repo string) (string, error) {
+	if len(f.versions) == 0 {
+		return "", errors.New("no versions available")
+	}
+	return f.versions[len(f.versions)-1], nil
+}
+
+func (f *fakeGoModRepo) ListVersions(repo string) ([]string, error) {
+	return f.versions, nil
+}
+
+func (f *fakeGoModRepo) DownloadModule(ctx context.Context, repo, version string, opts domain.DownloadOptions) (string, error) {
+	f.mu.Lock()
+	f.downloads++
+	f.mu.Unlock()
+	if f.failing[repo] {
+		return "", fmt.Errorf("failed to download %s@%s", repo, version)
+	}
+	if f.hanging[repo] {
+		<-ctx.Done()
+		return "", fmt.Errorf("failed to download %s@%s: %w", repo, version, ctx.Err())
+	}
+	return f.moduleDir, nil
+}
+
+func (f *fakeGoModRepo) GetModulePath(repo, version string) (string, error) {
+	if f.failing[repo] {
+		return "", fmt.Errorf("no module path for %s@%s", repo, version)
+	}
+	return f.moduleDir, nil
+}
+
+func (f *fakeGoModRepo) GetModCacheDir() (string, error) {
+	return filepath.Dir(f.moduleDir), nil
+}
+
+// versionsRunner answers `go list -m -versions` with versions for any module
+type versionsRunner struct {
+	versions string
+}
+
+func (r versionsRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if len(args) < 4 || args[0] != "list" {
+		return nil, errors.New("unexpected command")
+	}
+	return []byte(args[3] + " " + r.versions + "\n"), nil
+}
+
+func (r versionsRunner) RunWithEnv(ctx context.Context, env []string, name string, args ...string) ([]byte, error) {
+	return r.Run(ctx, name, args...)
+}
+
+func TestListVersionsOfDetectedRepositories(t *testing.T) {
+	goMod := filepath.Join(t.TempDir(), "go.mod")
+	writeFile(t, goMod, `module example.com/service
+
+// Protobuf libraries
+require github.com/example/api v1.0.0
+require github.com/example/users v0.2.0
+`)
+
+	service := &ProtoSyncServiceImpl{
+		logger:    nopLogger{},
+		goModRepo: infrastructure.NewGoModRepository(nopLogger{}, versionsRunner{versions: "v1.0.0 v1.1.0"}, nil),
+	}
+
+	repositories, err := service.DetectRepositories(&domain.SyncConfig{GoModPath: goMod})
+	require.NoError(t, err)
+	require.Len(t, repositories, 2)
+
+	versions, err := service.ListVersions(context.Background(), repositories)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"github.com/example/api":   {"v1.0.0", "v1.1.0"},
+		"github.com/example/users": {"v1.0.0", "v1.1.0"},
+	}, versions)
+
+	_, err = service.DetectRepositories(&domain.SyncConfig{GoModPath: filepath.Join(t.TempDir(), "go.mod")})
+	assert.ErrorContains(t, err, "failed to parse go.mod")
+}
+
+func TestPrepareRepositoriesPerRepositoryTarget(t *testing.T) {
+	dir := t.TempDir()
+	bufYaml := filepath.Join(dir, "buf.yaml")
+	writeFile(t, bufYaml, "version: v2\nmodules:\n  - path: "+filepath.Join(dir, "proto", "orders")+"\n  - path: "+filepath.Join(dir, "proto", "users")+"\n")
+
+	fileRepo := infrastructure.NewFileRepository(nopLogger{})
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, fileRepo: fileRepo, bufRepo: infrastructure.NewBufRepository(nopLogger{}, fileRepo)}
+	config := &domain.SyncConfig{
+		BufYamlPath: bufYaml,
+		Repositories: []domain.Repository{
+			{Name: "github.com/example/orders", Version: "v1.0.0"},
+			{Name: "github.com/example/users", Version: "v1.0.0", TargetPath: "third_party/users"},
+		},
+	}
+
+	repositories, err := service.prepareRepositories(config)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "proto", "orders"), repositories[0].TargetPath)
+	assert.Equal(t, "third_party/users", repositories[1].TargetPath)
+
+	// A repository's own target also wins over --target
+	config.Targets = []string{"proto"}
+	assert.Equal(t, []string{"third_party/users"}, targetPaths(config, repositories[1]))
+	assert.Equal(t, []string{"proto"}, targetPaths(config, domain.Repository{Name: "github.com/example/api"}))
+}
+
+func TestPrepareRepositoriesResolvesPerRepositoryRanges(t *testing.T) {
+	goModRepo := &fakeGoModRepo{versions: []string{"v1.2.0", "v1.4.1", "v2.0.0"}}
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, goModRepo: goModRepo}
+	config := &domain.SyncConfig{
+		Targets: []string{"proto"},
+		Repositories: []domain.Repository{
+			{Name: "github.com/example/a", Version: "^1.2.0"},
+			{Name: "github.com/example/b", Version: "v2.0.0"},
+		},
+	}
+
+	repositories, err := service.prepareRepositories(config)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.4.1", repositories[0].Version)
+	assert.Equal(t, "v2.0.0", repositories[1].Version)
+}
+
+func TestPrepareRepositoriesResolvesLatestPerRepository(t *testing.T) {
+	goModRepo := &fakeGoModRepo{versions: []string{"v1.2.0", "v2.0.0"}}
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, goModRepo: goModRepo}
+	config := &domain.SyncConfig{
+		Targets: []string{"proto"},
+		Repositories: []domain.Repository{
+			{Name: "github.com/example/a", Version: "latest"},
+			{Name: "github.com/example/b", Version: "v1.2.0"},
+		},
+	}
+
+	repositories, err := service.prepareRepositories(config)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", repositories[0].Version)
+	assert.Equal(t, "v1.2.0", repositories[1].Version)
+}
+
+func TestDryRunRepositoryUsesRepositoryTarget(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "a")
+	writeFile(t, filepath.Join(dir, "users", "a.proto"), "a")
+
+	service := newDiskService(&fakeGoModRepo{moduleDir: moduleDir})
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto"), DryRun: true}
+
+	result := service.dryRunRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/users", Version: "v1.0.0", TargetPath: filepath.Join(dir, "users")})
+	require.Len(t, result.FilesUpdated, 1)
+	assert.Equal(t, domain.ChangeUnchanged, result.FilesUpdated[0].Change)
+
+	result = service.dryRunRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"})
+	require.Len(t, result.FilesUpdated, 1)
+	assert.Equal(t, domain.ChangeAdded, result.FilesUpdated[0].Change)
+}
+
+func TestDryRunDiffDownloadsAndComparesContent(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "a")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "b.proto"), "b2")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "c.proto"), "c")
+	writeFile(t, filepath.Join(dir, "proto", "a.proto"), "a")
+	writeFile(t, filepath.Join(dir, "proto", "b.proto"), "b1")
+
+	goMod := &fakeGoModRepo{moduleDir: moduleDir, failing: map[string]bool{"github.com/example/broken": true}}
+	service := newDiskService(goMod)
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto"), DryRun: true}
+
+	result := service.dryRunRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"})
+	require.Len(t, result.FilesUpdated, 3)
+	assert.Zero(t, goMod.downloads)
+
+	config.DryRunDiff = true
+	result = service.dryRunRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"})
+	assert.Equal(t, 1, goMod.downloads)
+	require.True(t, result.Success)
+	require.Len(t, result.FilesUpdated, 3)
+	assert.Equal(t, domain.ChangeUnchanged, result.FilesUpdated[0].Change)
+	assert.Equal(t, domain.ChangeModified, result.FilesUpdated[1].Change)
+	assert.Equal(t, domain.ChangeAdded, result.FilesUpdated[2].Change)
+	assert.Equal(t, "would overwrite (identical)", dryRunAction(result.FilesUpdated[0].Change))
+	assert.Equal(t, "would overwrite (modified)", dryRunAction(result.FilesUpdated[1].Change))
+	assert.Equal(t, "new file", dryRunAction(result.FilesUpdated[2].Change))
+
+	result = service.dryRunRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/broken", Version: "v1.0.0"})
+	assert.False(t, result.Success)
+	assert.Equal(t, domain.ErrorCodeDownloadFailed, domain.CodeOf(result.Error))
+}
+
+func TestDryRunRepositoryWithoutModule(t *testing.T) {
+	dir := t.TempDir()
+	goMod := &fakeGoModRepo{moduleDir: filepath.Join(dir, "mod", "api@v1.0.0"), failing: map[string]bool{"github.com/example/broken": true}}
+	service := newDiskService(goMod)
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto"), DryRun: true}
+
+	// The module isn't downloaded, so its files can't be compared
+	result := service.dryRunRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"})
+	assert.True(t, result.Success)
+	assert.True(t, result.ChangesUnknown)
+	assert.True(t, domain.HasPendingChanges([]domain.SyncResult{result}))
+
+	result = service.dryRunRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/broken", Version: "v1.0.0"})
+	assert.False(t, result.Success)
+	assert.ErrorContains(t, result.Error, "failed to get module path")
+}
+
+func TestDryRunRepositoryTransform(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "package internal;\n")
+	writeFile(t, filepath.Join(dir, "proto", "a.proto"), "package public;\n")
+
+	service := &ProtoSyncServiceImpl{
+		logger:      nopLogger{},
+		fileRepo:    infrastructure.NewFileRepository(nopLogger{}),
+		goModRepo:   &fakeGoModRepo{moduleDir: moduleDir},
+		shellRunner: infrastructure.NewShellRunner(nopLogger{}),
+	}
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto"), DryRun: true, Transform: `sed "s/internal/public/"`}
+
+	result := service.dryRunRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"})
+	require.Len(t, result.FilesUpdated, 1)
+	assert.Equal(t, domain.ChangeUnchanged, result.FilesUpdated[0].Change)
+	assert.False(t, domain.HasPendingChanges([]domain.SyncResult{result}))
+}
+
+func TestDetectRepositoriesFromBuf(t *testing.T) {
+	service, fileRepo := newMemService()
+	service.bufRepo = infrastructure.NewBufRepository(nopLogger{}, fileRepo)
+	fileRepo.AddFile("/work/buf.yaml", []byte("version: v2\ndeps:\n  - github.com/example/orders-protos:v1.2.0\n"))
+	config := &domain.SyncConfig{BufYamlPath: "/work/buf.yaml", GoModPath: "/work/go.mod", SourcePath: "proto", SourceOfTruth: domain.SourceOfTruthBuf}
+
+	repositories, err := service.DetectRepositories(config)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Repository{
+		{Name: "github.com/example/orders-protos", Version: "v1.2.0", URL: "https://github.com/example/orders-protos"},
+	}, repositories)
+
+	// Only BSR modules leaves nothing that can be downloaded
+	fileRepo.AddFile("/work/buf.yaml", []byte("version: v2\ndeps:\n  - buf.build/googleapis/googleapis\n"))
+	_, err = service.DetectRepositories(config)
+	assert.ErrorContains(t, err, "--repo")
+
+	config.SourceOfTruth = "cargo"
+	assert.ErrorContains(t, service.ValidateConfig(config), "invalid source of truth")
+}
+
+func TestCopySpecificFilesGlob(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/product_a.proto", []byte("message A {}\n"))
+	fileRepo.AddFile("/mod/schemas/product_b.proto", []byte("message B {}\n"))
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+
+	config := &domain.SyncConfig{SpecificFiles: []string{"product_*.proto"}}
+	files, err := service.copySpecificFiles(context.Background(), newSyncRun(config), "/mod/schemas", "/proto")
+	require.NoError(t, err)
+
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	assert.ElementsMatch(t, []string{"product_a.proto", "product_b.proto"}, names)
+	assert.True(t, fileRepo.FileExists("/proto/product_a.proto"))
+	assert.True(t, fileRepo.FileExists("/proto/product_b.proto"))
+	assert.False(t, fileRepo.FileExists("/proto/orders.proto"))
+
+	config = &domain.SyncConfig{SpecificFiles: []string{"payment_*.proto"}}
+	_, err = service.copySpecificFiles(context.Background(), newSyncRun(config), "/mod/schemas", "/proto")
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrorCodeFileNotFound, domain.CodeOf(err))
+	assert.Contains(t, err.Error(), "Available proto files")
+}
+
+func TestCopyAllProtoFilesInMemory(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+	fileRepo.AddFile("/mod/schemas/users.proto", []byte("message User {}\n"))
+	fileRepo.AddFile("/mod/schemas/internal_debug.proto", []byte("message Debug {}\n"))
+	fileRepo.AddFile("/mod/schemas/README.md", []byte("docs"))
+	fileRepo.AddFile("/proto/orders.proto", []byte("message OldOrder {}\n"))
+	require.NoError(t, fileRepo.SetReadOnly("/proto/orders.proto"))
+
+	config := &domain.SyncConfig{Exclude: []string{"internal_*"}}
+	files, err := service.copyAllProtoFiles(context.Background(), newSyncRun(config), "/mod/schemas", "/proto")
+	require.NoError(t, err)
+
+	changes := make(map[string]domain.ChangeType)
+	for _, file := range files {
+		changes[file.Name] = file.Change
+		modTime, err := fileRepo.ModTime(file.Path)
+		require.NoError(t, err)
+		assert.Equal(t, modTime, file.ModifiedTime)
+	}
+	assert.Equal(t, map[string]domain.ChangeType{
+		"orders.proto": domain.ChangeModified,
+		"users.proto":  domain.ChangeAdded,
+	}, changes)
+	assert.Equal(t, int64(len("message Order {}\n")+len("message User {}\n")), domain.SyncResult{FilesUpdated: files}.TotalBytes())
+
+	// The read-only target was made writable and replaced
+	data, err := fileRepo.ReadFile("/proto/orders.proto")
+	require.NoError(t, err)
+	assert.Equal(t, "message Order {}\n", string(data))
+	assert.Equal(t, []string{
+		"/mod/schemas/README.md",
+		"/mod/schemas/internal_debug.proto",
+		"/mod/schemas/orders.proto",
+		"/mod/schemas/users.proto",
+		"/proto/orders.proto",
+		"/proto/users.proto",
+	}, fileRepo.Files())
+}
+
+func TestCopySpecificFilesMultiple(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+	fileRepo.AddFile("/mod/schemas/users.proto", []byte("message User {}\n"))
+	fileRepo.AddFile("/mod/schemas/product_a.proto", []byte("message A {}\n"))
+	fileRepo.AddFile("/mod/schemas/debug.proto", []byte("message Debug {}\n"))
+
+	config := &domain.SyncConfig{SpecificFiles: []string{"orders.proto", "users.proto", "product_*.proto", "orders.proto"}}
+	files, err := service.copySpecificFiles(context.Background(), newSyncRun(config), "/mod/schemas", "/proto")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders.proto", "users.proto", "product_a.proto"}, fileNames(files))
+	assert.False(t, fileRepo.FileExists("/proto/debug.proto"))
+
+	// Every missing file is reported at once and nothing is copied
+	config = &domain.SyncConfig{SpecificFiles: []string{"debug.proto", "missing.proto", "gone_*.proto"}}
+	files, err = service.copySpecificFiles(context.Background(), newSyncRun(config), "/mod/schemas", "/other")
+	assert.Empty(t, files)
+	assert.Equal(t, domain.ErrorCodeFileNotFound, domain.CodeOf(err))
+	assert.ErrorContains(t, err, "proto files not found in /mod/schemas: missing.proto, gone_*.proto")
+	assert.False(t, fileRepo.FileExists("/other/debug.proto"))
+}
+
+// cancelAfterCopy cancels a sync once a file has been copied
+type cancelAfterCopy struct {
+	cancel context.CancelFunc
+}
+
+func (c cancelAfterCopy) Report(event domain.ProgressEvent) {
+	if event.Event == domain.ProgressFileCopied {
+		c.cancel()
+	}
+}
+
+func TestCopyAllProtoFilesCancelled(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/a.proto", []byte("message A {}\n"))
+	fileRepo.AddFile("/mod/schemas/b.proto", []byte("message B {}\n"))
+	fileRepo.AddFile("/mod/schemas/c.proto", []byte("message C {}\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config := &domain.SyncConfig{Progress: cancelAfterCopy{cancel}}
+	files, err := service.copyAllProtoFiles(ctx, newSyncRun(config), "/mod/schemas", "/proto")
+	assert.Equal(t, domain.ErrorCodeCancelled, domain.CodeOf(err))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "copy cancelled after 1 of 3 file(s)")
+	assert.Equal(t, []string{"a.proto"}, fileNames(files))
+	assert.True(t, fileRepo.FileExists("/proto/a.proto"))
+	assert.False(t, fileRepo.FileExists("/proto/b.proto"))
+}
+
+func TestCopySpecificFileInMemory(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+	fileRepo.AddFile("/mod/schemas/users.proto", []byte("message User {}\n"))
+	fileRepo.AddFile("/proto/orders.proto", []byte("message OldOrder {}\n"))
+	require.NoError(t, fileRepo.SetReadOnly("/proto/orders.proto"))
+	run := newSyncRun(&domain.SyncConfig{})
+
+	file, err := service.copySpecificFile(context.Background(), run, "/mod/schemas", "orders.proto", "/proto")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChangeModified, file.Change)
+	assert.False(t, fileRepo.FileExists("/proto/users.proto"))
+
+	_, err = service.copySpecificFile(context.Background(), run, "/mod/schemas", "missing.proto", "/proto")
+	assert.Equal(t, domain.ErrorCodeFileNotFound, domain.CodeOf(err))
+	assert.ErrorContains(t, err, "Available proto files: orders.proto, users.proto")
+}
+
+func TestCopySpecificFileRejectsPathsOutsideSource(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+	fileRepo.AddFile("/mod/secret.proto", []byte("secret"))
+	fileRepo.AddFile("/etc/passwd", []byte("root"))
+	fileRepo.AddFile("/proto/buf.yaml", []byte("version: v2\n"))
+
+	for _, name := range []string{"../secret.proto", "sub/../../secret.proto", "/etc/passwd"} {
+		_, err := service.copySpecificFile(context.Background(), newSyncRun(&domain.SyncConfig{}), "/mod/schemas", name, "/proto")
+		require.Error(t, err, name)
+		assert.Equal(t, domain.ErrorCodeInvalidConfig, domain.CodeOf(err))
+		assert.ErrorContains(t, err, "must be a relative path inside the source directory")
+	}
+	assert.Equal(t, []string{"/etc/passwd", "/mod/schemas/orders.proto", "/mod/secret.proto", "/proto/buf.yaml"}, fileRepo.Files())
+}
+
+func TestCopyAllProtoFilesSkipsIdenticalTargets(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+	fileRepo.AddFile("/mod/schemas/users.proto", []byte("message User {}\n"))
+	fileRepo.AddFile("/proto/orders.proto", []byte("message Order {}\n"))
+	fileRepo.AddFile("/proto/users.proto", []byte("message OldUser {}\n"))
+	require.NoError(t, fileRepo.SetReadOnly("/proto/orders.proto"))
+	require.NoError(t, fileRepo.SetReadOnly("/proto/users.proto"))
+	before, err := fileRepo.ModTime("/proto/orders.proto")
+	require.NoError(t, err)
+
+	config := &domain.SyncConfig{}
+	files, err := service.copyAllProtoFiles(context.Background(), newSyncRun(config), "/mod/schemas", "/proto")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.True(t, files[0].Skipped)
+	assert.Equal(t, domain.ChangeUnchanged, files[0].Change)
+	assert.False(t, files[1].Skipped)
+	assert.Equal(t, 1, domain.SyncResult{FilesUpdated: files}.SkippedFiles())
+
+	// The identical target was neither rewritten nor made writable
+	after, err := fileRepo.ModTime("/proto/orders.proto")
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+	assert.True(t, fileRepo.IsReadOnly("/proto/orders.proto"))
+	assert.False(t, fileRepo.IsReadOnly("/proto/users.proto"))
+
+	config.Force = true
+	files, err = service.copyAllProtoFiles(context.Background(), newSyncRun(config), "/mod/schemas", "/proto")
+	require.NoError(t, err)
+	assert.False(t, files[0].Skipped)
+	assert.False(t, fileRepo.IsReadOnly("/proto/orders.proto"))
+}
+
+func TestProcessRepositoryRecordsTimings(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "a.proto"), "a")
+
+	goMod := &fakeGoModRepo{moduleDir: moduleDir}
+	service := newDiskService(goMod)
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto")}
+
+	result := service.processRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"})
+	require.True(t, result.Success)
+	assert.Positive(t, result.Timings.Download)
+	assert.Positive(t, result.Timings.Copy)
+	assert.GreaterOrEqual(t, result.Timings.Total, result.Timings.Download+result.Timings.Copy)
+
+	goMod.failing = map[string]bool{"github.com/example/broken": true}
+	result = service.processRepository(context.Background(), newSyncRun(config), domain.Repository{Name: "github.com/example/broken", Version: "v1.0.0"})
+	require.Error(t, result.Error)
+	assert.Zero(t, result.Timings.Copy)
+	assert.Positive(t, result.Timings.Total)
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// Rollback restores the files of the newest backup directory of every
+// target over the target. Backups are kept, so a rollback can be repeated.
+func (p *ProtoSyncServiceImpl) Rollback(ctx context.Context, config *domain.SyncConfig) ([]domain.RollbackResult, error) {
+	targets, err := p.resolveTargets(config, false)
+	if err != nil {
+		return nil, err
+	}
+	if config.BackupDir != "" && len(targets) > 1 {
+		return nil, fmt.Errorf("--backup-dir needs a single target, got %s; use --target or --module", strings.Join(targets, ", "))
+	}
+
+	var results []domain.RollbackResult
+	var searched []string
+	for _, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		root := config.BackupDir
+		if root == "" {
+			root = filepath.Join(target, backupDirName)
+		}
+		searched = append(searched, root)
+
+		backupDir, files, err := p.latestBackup(root)
+		if err != nil {
+			return results, err
+		}
+		if backupDir == "" {
+			p.logger.Warning("No backups found for %s in %s", target, root)
+			continue
+		}
+
+		result, err := p.restoreBackup(target, backupDir, files)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no backups found in %s", strings.Join(searched, ", "))
+	}
+	return results, nil
+}
+
+// resolveTargets returns the target directories of config: every --target,
+// the buf.yaml module chosen by --module, or every buf.yaml module. deletes
+// is set by commands that delete files from the targets.
+func (p *ProtoSyncServiceImpl) resolveTargets(config *domain.SyncConfig, deletes bool) ([]string, error) {
+	if len(config.Targets) > 0 {
+		return config.Targets, nil
+	}
+
+	modules, err := p.bufRepo.ParseBufModules(config.BufYamlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse buf.yaml: %w", err)
+	}
+	if err := p.applyDefaultModulePath(modules, config, deletes); err != nil {
+		return nil, err
+	}
+
+	module, _, err := selectConfiguredModule(modules, config)
+	if err != nil {
+		return nil, err
+	}
+	if module != nil {
+		return []string{module.Path}, nil
+	}
+
+	targets := make([]string, 0, len(modules))
+	for _, module := range modules {
+		targets = append(targets, module.Path)
+	}
+	return targets, nil
+}
+
+// latestBackup returns the newest timestamped directory below root and the
+// files it holds, or an empty directory when root has no backups
+func (p *ProtoSyncServiceImpl) latestBackup(root string) (string, []domain.ProtoFile, error) {
+	if !p.fileRepo.IsDir(root) {
+		return "", nil, nil
+	}
+
+	files, err := p.fileRepo.ListFiles(root, "*.proto"+backupSuffix, domain.ListOptions{})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list backups in %s: %w", root, err)
+	}
+
+	// Timestamps sort lexically, so the newest backup has the largest name
+	stamps := make(map[string][]domain.ProtoFile)
+	latest := ""
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file.Path)
+		if err != nil {
+			return "", nil, err
+		}
+		stamp := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+		if stamp == filepath.ToSlash(rel) {
+			// Files directly in root are not part of a backup
+			continue
+		}
+		stamps[stamp] = append(stamps[stamp], file)
+		if stamp > latest {
+			latest = stamp
+		}
+	}
+
+	if latest == "" {
+		return "", nil, nil
+	}
+	return filepath.Join(root, latest), stamps[latest], nil
+}
+
+// restoreBackup copies files from backupDir back into target
+func (p *ProtoSyncServiceImpl) restoreBackup(target, backupDir string, files []domain.ProtoFile) (domain.RollbackResult, error) {
+	result := domain.RollbackResult{Target: target, BackupDir: backupDir}
+
+	for _, file := range files {
+		rel, err := filepath.Rel(backupDir, file.Path)
+		if err != nil {
+			return result, err
+		}
+		rel = strings.TrimSuffix(rel, backupSuffix)
+		targetFile := filepath.Join(target, rel)
+
+		if p.fileRepo.FileExists(targetFile) {
+			if err := p.fileRepo.MakeWritable(targetFile); err != nil {
+				return result, fmt.Errorf("failed to make %s writable: %w", targetFile, err)
+			}
+		}
+		if err := p.fileRepo.CopyFile(file.Path, targetFile); err != nil {
+			return result, fmt.Errorf("failed to restore %s: %w", targetFile, err)
+		}
+
+		p.logger.Info("Restored %s from %s", targetFile, backupDir)
+		result.Files = append(result.Files, rel)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackRestoresLatestBackup(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "proto")
+	root := filepath.Join(target, backupDirName)
+	writeFile(t, filepath.Join(root, "20240101-090000.000000000", "a.proto.bak"), "package older;")
+	writeFile(t, filepath.Join(root, "20240102-090000.000000001", "a.proto.bak"), "package same second;")
+	writeFile(t, filepath.Join(root, "20240102-090000.000000002", "a.proto.bak"), "package newer;")
+	writeFile(t, filepath.Join(root, "20240102-090000.000000002", "v1", "b.proto.bak"), "package b;")
+	writeFile(t, filepath.Join(target, "a.proto"), "package synced;")
+	require.NoError(t, os.Chmod(filepath.Join(target, "a.proto"), 0o444))
+
+	service := newDiskService(nil)
+	results, err := service.Rollback(context.Background(), &domain.SyncConfig{Targets: []string{target}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, filepath.Join(root, "20240102-090000.000000002"), results[0].BackupDir)
+	assert.Equal(t, []string{"a.proto", filepath.Join("v1", "b.proto")}, results[0].Files)
+
+	data, err := os.ReadFile(filepath.Join(target, "a.proto"))
+	require.NoError(t, err)
+	assert.Equal(t, "package newer;", string(data))
+	assert.FileExists(t, filepath.Join(target, "v1", "b.proto"))
+}
+
+func TestRollbackWithoutBackups(t *testing.T) {
+	target := t.TempDir()
+	service := newDiskService(nil)
+
+	_, err := service.Rollback(context.Background(), &domain.SyncConfig{Targets: []string{target}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no backups found")
+
+	_, err = service.Rollback(context.Background(), &domain.SyncConfig{Targets: []string{target, target}, BackupDir: target})
+	assert.Error(t, err)
+}
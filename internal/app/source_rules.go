@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"golang.org/x/mod/semver"
+)
+
+// versionComparison compares versions against a canonical version with one
+// of the operators <, <=, >, >= or =
+type versionComparison struct {
+	op      string
+	version string
+}
+
+// sourceRule selects a source path for repository versions matching a
+// comparison, e.g. `>=2.0.0=schemas/api/v1`
+type sourceRule struct {
+	versionComparison
+	path string
+}
+
+// sourceRuleOperators is ordered so two-character operators match first
+var sourceRuleOperators = []string{"<=", ">=", "<", ">", "="}
+
+func parseSourceRule(rule string) (sourceRule, error) {
+	var op string
+	for _, candidate := range sourceRuleOperators {
+		if strings.HasPrefix(rule, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return sourceRule{}, fmt.Errorf("invalid source rule %q: must start with one of <, <=, >, >=, =", rule)
+	}
+
+	version, path, found := strings.Cut(strings.TrimPrefix(rule, op), "=")
+	if !found || path == "" {
+		return sourceRule{}, fmt.Errorf("invalid source rule %q: expected <op><version>=<path>", rule)
+	}
+
+	version = canonicalVersion(version)
+	if !semver.IsValid(version) {
+		return sourceRule{}, fmt.Errorf("invalid source rule %q: %q is not a semantic version", rule, version)
+	}
+
+	return sourceRule{versionComparison: versionComparison{op: op, version: version}, path: path}, nil
+}
+
+func parseSourceRules(rules []string) ([]sourceRule, error) {
+	parsed := make([]sourceRule, 0, len(rules))
+	for _, rule := range rules {
+		r, err := parseSourceRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+func (c versionComparison) matches(version string) bool {
+	version = canonicalVersion(version)
+	if !semver.IsValid(version) {
+		return false
+	}
+
+	cmp := semver.Compare(version, c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// canonicalVersion adds the leading "v" Go module versions require
+func canonicalVersion(version string) string {
+	if version != "" && !strings.HasPrefix(version, "v") {
+		return "v" + version
+	}
+	return version
+}
+
+// resolveSourcePath returns the source path for repo: its own source path
+// when the config file sets one, otherwise the first matching --source-rule
+// and finally config.SourcePath
+func (p *ProtoSyncServiceImpl) resolveSourcePath(repo domain.Repository, config *domain.SyncConfig) string {
+	if repo.SourcePath != "" {
+		return repo.SourcePath
+	}
+
+	rules, err := parseSourceRules(config.SourceRules)
+	if err != nil {
+		// Rules are validated up front, so this only happens for direct callers
+		return config.SourcePath
+	}
+
+	for _, rule := range rules {
+		if rule.matches(repo.Version) {
+			p.logger.Info("Using source path %s for %s@%s (rule %s%s)", rule.path, repo.Name, repo.Version, rule.op, rule.version)
+			return rule.path
+		}
+	}
+
+	return config.SourcePath
+}
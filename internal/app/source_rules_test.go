@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSourcePath(t *testing.T) {
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}}
+	config := &domain.SyncConfig{
+		SourcePath:  "default/path",
+		SourceRules: []string{"<2.0.0=api/v1", ">=2.0.0=schemas/api/v1"},
+	}
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "v1.9.3", want: "api/v1"},
+		{version: "v2.0.0", want: "schemas/api/v1"},
+		{version: "v2.1.0-rc.1", want: "schemas/api/v1"},
+		{version: "main", want: "default/path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			repo := domain.Repository{Name: "github.com/example/api", Version: tt.version}
+			assert.Equal(t, tt.want, service.resolveSourcePath(repo, config))
+		})
+	}
+}
+
+func TestResolveSourcePathPerRepository(t *testing.T) {
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}}
+	config := &domain.SyncConfig{SourcePath: "schemas/api/v1", SourceRules: []string{">=1.0.0=api/v1"}}
+
+	repo := domain.Repository{Name: "github.com/example/api", Version: "v1.2.0", SourcePath: "proto"}
+	assert.Equal(t, "proto", service.resolveSourcePath(repo, config))
+}
+
+func TestValidateConfigSourcePath(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("buf.yaml", []byte("version: v2\n"))
+	config := domain.SyncConfig{BufYamlPath: "buf.yaml", GoModPath: "go.mod"}
+	assert.ErrorContains(t, service.ValidateConfig(&config), "source path is required")
+
+	config.Repositories = []domain.Repository{
+		{Name: "github.com/example/api", SourcePath: "proto"},
+		{Name: "github.com/example/users"},
+	}
+	assert.ErrorContains(t, service.ValidateConfig(&config), "source path is required for github.com/example/users")
+
+	config.Repositories[1].SourcePath = "schemas/api/v1"
+	assert.NoError(t, service.ValidateConfig(&config))
+}
+
+func TestParseSourceRuleInvalid(t *testing.T) {
+	for _, rule := range []string{"2.0.0=api", "<2.0.0", ">=two=api", "<2.0.0="} {
+		_, err := parseSourceRule(rule)
+		assert.Error(t, err, rule)
+	}
+}
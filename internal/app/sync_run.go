@@ -0,0 +1,150 @@
+package app
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// syncRun holds state shared by all repositories processed in one Sync call
+type syncRun struct {
+	config *domain.SyncConfig
+
+	mu          sync.Mutex
+	mergedFiles map[string]mergedFile
+	editState   *editState
+
+	// backupStamp names this run's backup directories; backupDirs holds
+	// every backup directory files were copied into
+	backupStamp string
+	backupDirs  map[string]struct{}
+
+	// staged holds the staging directories of an --atomic run
+	staged []stagedTarget
+
+	// pathLocks serializes writes to one target file by repositories
+	// processed concurrently
+	pathLocks map[string]*sync.Mutex
+}
+
+// mergedFile records the first repository that provided a shared file
+type mergedFile struct {
+	repo string
+	data []byte
+}
+
+func newSyncRun(config *domain.SyncConfig) *syncRun {
+	return &syncRun{
+		config:      config,
+		mergedFiles: make(map[string]mergedFile),
+		backupStamp: time.Now().Format(backupStampLayout),
+		backupDirs:  make(map[string]struct{}),
+		pathLocks:   make(map[string]*sync.Mutex),
+	}
+}
+
+// recordBackup remembers that files were backed up into dir
+func (r *syncRun) recordBackup(dir string) {
+	r.mu.Lock()
+	r.backupDirs[r.realPath(dir)] = struct{}{}
+	r.mu.Unlock()
+}
+
+// stagedTargets returns the directories to copy into for targets: their
+// staging directories in an --atomic run, targets themselves otherwise
+func (r *syncRun) stagedTargets(targets []string) []string {
+	if len(r.staged) == 0 {
+		return targets
+	}
+
+	dirs := make([]string, len(targets))
+	for i, target := range targets {
+		dirs[i] = target
+		for _, staged := range r.staged {
+			if staged.target == filepath.Clean(target) {
+				dirs[i] = staged.staging
+			}
+		}
+	}
+	return dirs
+}
+
+// realPath maps a path below a staging directory to the same path below
+// its target
+func (r *syncRun) realPath(path string) string {
+	for _, staged := range r.staged {
+		if isWithin(staged.staging, path) {
+			rel, _ := filepath.Rel(staged.staging, path)
+			return filepath.Join(staged.target, rel)
+		}
+	}
+	return path
+}
+
+// lockPath locks targetFile against writes from other repositories of the
+// run, which with --concurrency may share a target and file name. The
+// returned function releases the lock.
+func (r *syncRun) lockPath(targetFile string) func() {
+	path := filepath.Clean(r.realPath(targetFile))
+
+	r.mu.Lock()
+	lock, ok := r.pathLocks[path]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.pathLocks[path] = lock
+	}
+	r.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// backups returns the backup directories of this run in sorted order
+func (r *syncRun) backups() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dirs := make([]string, 0, len(r.backupDirs))
+	for dir := range r.backupDirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// recordWrite remembers the hash of content written to targetFile when edit
+// tracking is enabled
+func (r *syncRun) recordWrite(targetFile string, data []byte) {
+	if r.editState == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.editState.Files[filepath.Clean(r.realPath(targetFile))] = hashContent(data)
+	r.mu.Unlock()
+}
+
+// report forwards a progress event when a reporter is configured
+func (r *syncRun) report(event domain.ProgressEvent) {
+	if r.config.Progress != nil {
+		r.config.Progress.Report(event)
+	}
+}
+
+// reportRepoDone emits the repo_done event for result
+func (r *syncRun) reportRepoDone(result domain.SyncResult) {
+	success := result.Success
+	event := domain.ProgressEvent{
+		Event:   domain.ProgressRepoDone,
+		Repo:    result.Repository.Name,
+		Version: result.Repository.Version,
+		Success: &success,
+	}
+	if result.Error != nil {
+		event.Error = result.Error.Error()
+	}
+	r.report(event)
+}
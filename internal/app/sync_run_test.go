@@ -0,0 +1,37 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+func TestSyncRunLockPath(t *testing.T) {
+	run := newSyncRun(&domain.SyncConfig{})
+	unlock := run.lockPath(filepath.Join("proto", "a.proto"))
+
+	acquired := make(chan struct{})
+	go func() {
+		release := run.lockPath(filepath.Join("proto", ".", "a.proto"))
+		close(acquired)
+		release()
+	}()
+
+	// Other files are not held up
+	run.lockPath(filepath.Join("proto", "b.proto"))()
+
+	select {
+	case <-acquired:
+		t.Fatal("second writer acquired a.proto while it was locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second writer never acquired a.proto")
+	}
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// targetPlaceholder matches a {placeholder} in a target path
+var targetPlaceholder = regexp.MustCompile(`\{[^{}/]*\}`)
+
+// expandTargetPath replaces the {version}, {repo} and {repoBase}
+// placeholders of target with the resolved version, module path and last
+// module path element of repo, so separate runs can keep several versions
+// side by side in e.g. proto/{version}
+func expandTargetPath(target string, repo domain.Repository) string {
+	if !strings.Contains(target, "{") {
+		return target
+	}
+	return strings.NewReplacer(
+		"{version}", repo.Version,
+		"{repo}", filepath.FromSlash(repo.Name),
+		"{repoBase}", path.Base(repo.Name),
+	).Replace(target)
+}
+
+// validateTargetTemplate fails on placeholders expandTargetPath doesn't
+// know, which would otherwise end up as literal directory names
+func validateTargetTemplate(target string) error {
+	for _, placeholder := range targetPlaceholder.FindAllString(target, -1) {
+		switch placeholder {
+		case "{version}", "{repo}", "{repoBase}":
+		default:
+			return fmt.Errorf("unknown placeholder %s in target path %q: use {version}, {repo} or {repoBase}", placeholder, target)
+		}
+	}
+	return nil
+}
+
+// validateTargetPath checks a module path read from buf.yaml before
+// anything is downloaded: it must stay inside the project, so a buf.yaml
+// with `path: ../../etc` can't make proto-sync write elsewhere, and it must
+// be a directory or be creatable, so typos in buf.yaml fail early with a
+// clear message
+func (p *ProtoSyncServiceImpl) validateTargetPath(targetPath, bufYamlPath string, index int) error {
+	field := fmt.Sprintf("modules[%d].path %q in %s", index, targetPath, bufYamlPath)
+
+	if err := p.checkWithinProject(targetPath, bufYamlPath); err != nil {
+		return fmt.Errorf("%s %w", field, err)
+	}
+
+	if p.fileRepo.FileExists(targetPath) {
+		if !p.fileRepo.IsDir(targetPath) {
+			return fmt.Errorf("%s is not a directory", field)
+		}
+		return nil
+	}
+
+	absTarget, err := filepath.Abs(targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", field, err)
+	}
+
+	// The nearest existing ancestor must be a directory for creation to work
+	for dir := filepath.Dir(absTarget); ; dir = filepath.Dir(dir) {
+		if p.fileRepo.FileExists(dir) {
+			if !p.fileRepo.IsDir(dir) {
+				return fmt.Errorf("%s cannot be created: %s is not a directory", field, dir)
+			}
+			break
+		}
+	}
+
+	p.logger.Info("Target directory %s does not exist yet and will be created", targetPath)
+	return nil
+}
+
+// checkWithinProject fails unless targetPath, with symlinks resolved,
+// is inside the working directory or the directory holding buf.yaml
+func (p *ProtoSyncServiceImpl) checkWithinProject(targetPath, bufYamlPath string) error {
+	absTarget, err := filepath.Abs(targetPath)
+	if err != nil {
+		return fmt.Errorf("cannot be resolved: %w", err)
+	}
+	workDir, err := filepath.Abs(".")
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	bufDir, err := filepath.Abs(filepath.Dir(bufYamlPath))
+	if err != nil {
+		return fmt.Errorf("failed to resolve the directory of %s: %w", bufYamlPath, err)
+	}
+
+	resolved := p.resolveExistingPrefix(absTarget)
+	roots := []string{workDir}
+	if bufDir != workDir {
+		roots = append(roots, bufDir)
+	}
+	for _, root := range roots {
+		if isWithin(p.resolveExistingPrefix(root), resolved) {
+			return nil
+		}
+	}
+	return fmt.Errorf("resolves to %s, outside the project (%s)", resolved, strings.Join(roots, " or "))
+}
+
+// resolveExistingPrefix resolves symlinks in the longest existing prefix
+// of the absolute path, so a target that doesn't exist yet is still checked
+// against where it would really be created
+func (p *ProtoSyncServiceImpl) resolveExistingPrefix(path string) string {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if p.fileRepo.FileExists(dir) {
+			resolved, err := p.fileRepo.ResolvePath(dir)
+			if err != nil {
+				return path
+			}
+			rest, err := filepath.Rel(dir, path)
+			if err != nil {
+				return path
+			}
+			return filepath.Join(resolved, rest)
+		}
+		if filepath.Dir(dir) == dir {
+			return path
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTargetPath(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	writeFile(t, filepath.Join(dir, "proto", "a.proto"), "")
+	writeFile(t, filepath.Join(dir, "README.md"), "")
+
+	service := newDiskService(nil)
+
+	assert.NoError(t, service.validateTargetPath("proto", "buf.yaml", 0))
+	assert.NoError(t, service.validateTargetPath("third_party/proto", "buf.yaml", 0))
+
+	err = service.validateTargetPath("README.md", "buf.yaml", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `modules[0].path "README.md" in buf.yaml is not a directory`)
+
+	assert.Error(t, service.validateTargetPath("README.md/proto", "buf.yaml", 0))
+	assert.Error(t, service.validateTargetPath("../elsewhere/proto", "buf.yaml", 0))
+}
+
+func TestValidateTargetPathRejectsEscapes(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "project")
+	outside := filepath.Join(root, "outside")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "api"), 0755))
+	require.NoError(t, os.MkdirAll(outside, 0755))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link")))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	service := newDiskService(nil)
+
+	// Existing directories outside the project are rejected too
+	for _, path := range []string{"../outside", "../../etc", "api/../../outside", outside, "/etc", "link", "link/proto"} {
+		err := service.validateTargetPath(path, "buf.yaml", 0)
+		require.Error(t, err, path)
+		assert.Contains(t, err.Error(), "outside the project", path)
+	}
+
+	assert.NoError(t, service.validateTargetPath(filepath.Join(dir, "api"), "buf.yaml", 0))
+	assert.NoError(t, service.validateTargetPath("api/../proto", "buf.yaml", 0))
+	// Module paths may point anywhere below a buf.yaml outside the working directory
+	assert.NoError(t, service.validateTargetPath("../outside/proto", "../outside/buf.yaml", 0))
+}
+
+func TestSyncIntoVersionedTarget(t *testing.T) {
+	service, fileRepo := newMemService()
+	fileRepo.AddFile("/mod/schemas/orders.proto", []byte("message Order {}\n"))
+
+	config := &domain.SyncConfig{TargetPath: "/proto/{version}"}
+	repo := domain.Repository{Name: "github.com/example/api", Version: "v1.2.0"}
+	files, err := service.copyAllProtoFiles(context.Background(), newSyncRun(config), "/mod/schemas", targetPaths(config, repo)...)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, filepath.Join("/proto", "v1.2.0", "orders.proto"), files[0].Path)
+	assert.True(t, fileRepo.FileExists("/proto/v1.2.0/orders.proto"))
+}
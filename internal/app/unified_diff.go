@@ -0,0 +1,218 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffEdits bounds the search for a minimal edit script. Regions needing
+// more edits are shown as all old lines removed and all new lines added.
+const maxDiffEdits = 2000
+
+// diffOp is one line of an edit script: ' ' keeps, '-' deletes, '+' inserts.
+// line keeps its "\n", which only the last line of a file may lack.
+// oldLine and newLine are the 0-based positions in each file before the op.
+type diffOp struct {
+	kind    byte
+	line    string
+	oldLine int
+	newLine int
+}
+
+// unifiedDiff renders the changes from oldData to newData in unified diff
+// format, or "" when they are identical
+func unifiedDiff(oldName, newName string, oldData, newData []byte) string {
+	ops := diffLines(splitLines(oldData), splitLines(newData))
+
+	var changed []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(changed); {
+		// Extend the hunk while the next change is close enough to share context
+		end := start
+		for end+1 < len(changed) && changed[end+1]-changed[end] <= 2*diffContext {
+			end++
+		}
+
+		first := max(changed[start]-diffContext, 0)
+		last := min(changed[end]+diffContext, len(ops)-1)
+		writeHunk(&b, ops[first:last+1])
+		start = end + 1
+	}
+
+	return b.String()
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+
+	// An empty range starts at the line before it, per the unified format
+	oldStart, newStart := ops[0].oldLine, ops[0].newLine
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, op := range ops {
+		b.WriteByte(op.kind)
+		b.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits data after every "\n", so a missing final newline
+// makes the last line differ
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines builds an edit script from a to b. The common prefix and suffix
+// are trimmed first so the search only covers the changed region.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: ' ', line: a[i], oldLine: i, newLine: i})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	i, j := 0, 0
+	for _, kind := range editKinds(midA, midB) {
+		op := diffOp{kind: kind, oldLine: prefix + i, newLine: prefix + j}
+		switch kind {
+		case ' ':
+			op.line = midA[i]
+			i++
+			j++
+		case '-':
+			op.line = midA[i]
+			i++
+		default:
+			op.line = midB[j]
+			j++
+		}
+		ops = append(ops, op)
+	}
+
+	for k := 0; k < suffix; k++ {
+		oldLine, newLine := len(a)-suffix+k, len(b)-suffix+k
+		ops = append(ops, diffOp{kind: ' ', line: a[oldLine], oldLine: oldLine, newLine: newLine})
+	}
+
+	return ops
+}
+
+// editKinds returns the kinds of a shortest edit script from a to b, found
+// with Myers' O((n+m)d) algorithm. When more than maxDiffEdits edits are
+// needed it gives up and replaces every line.
+func editKinds(a, b []string) []byte {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxDiffEdits)
+
+	// v[offset+k] is the furthest x reached on diagonal k = x-y. trace[d]
+	// keeps diagonals -d-1..d+1 of v as they were before step d, which is
+	// all backtracking through step d reads.
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		kinds := make([]byte, 0, n+m)
+		kinds = append(kinds, strings.Repeat("-", n)...)
+		return append(kinds, strings.Repeat("+", m)...)
+	}
+
+	var kinds []byte
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int { return snapshot[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			kinds = append(kinds, ' ')
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				kinds = append(kinds, '+')
+			} else {
+				kinds = append(kinds, '-')
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	slices.Reverse(kinds)
+	return kinds
+}
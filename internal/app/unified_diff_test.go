@@ -0,0 +1,63 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	oldData := []byte("syntax = \"proto3\";\n\npackage api;\n\nmessage Order {\n  string id = 1;\n}\n")
+	newData := []byte("syntax = \"proto3\";\n\npackage api;\n\nmessage Order {\n  string id = 1;\n  int64 total = 2;\n}\n")
+
+	assert.Equal(t, `--- a/order.proto
++++ b/order.proto
+@@ -4,4 +4,5 @@
+ 
+ message Order {
+   string id = 1;
++  int64 total = 2;
+ }
+`, unifiedDiff("a/order.proto", "b/order.proto", oldData, newData))
+
+	assert.Empty(t, unifiedDiff("a", "b", oldData, oldData))
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	assert.Equal(t, "--- /dev/null\n+++ b/a.proto\n@@ -0,0 +1,2 @@\n+package a;\n+message A {}\n",
+		unifiedDiff("/dev/null", "b/a.proto", nil, []byte("package a;\nmessage A {}\n")))
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	var oldLines, newLines []byte
+	for i := 0; i < 20; i++ {
+		line := []byte{byte('a' + i), '\n'}
+		oldLines = append(oldLines, line...)
+		if i == 2 || i == 17 {
+			line = []byte{'X', '\n'}
+		}
+		newLines = append(newLines, line...)
+	}
+
+	diff := unifiedDiff("a", "b", oldLines, newLines)
+	assert.Contains(t, diff, "@@ -1,6 +1,6 @@\n")
+	assert.Contains(t, diff, "@@ -15,6 +15,6 @@\n")
+}
+
+func TestUnifiedDiffMissingFinalNewline(t *testing.T) {
+	assert.Equal(t, "--- a\n+++ b\n@@ -1,2 +1,2 @@\n package a;\n-message A {}\n\\ No newline at end of file\n+message A {}\n",
+		unifiedDiff("a", "b", []byte("package a;\nmessage A {}"), []byte("package a;\nmessage A {}\n")))
+}
+
+func TestUnifiedDiffLargeReplacement(t *testing.T) {
+	var oldData, newData strings.Builder
+	for i := 0; i < maxDiffEdits; i++ {
+		oldData.WriteString("old\n")
+		newData.WriteString("new\n")
+	}
+
+	diff := unifiedDiff("a", "b", []byte(oldData.String()), []byte(newData.String()))
+	assert.Contains(t, diff, "@@ -1,2000 +1,2000 @@\n-old\n")
+	assert.Equal(t, maxDiffEdits, strings.Count(diff, "\n+new"))
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// Validate downloads every repository and checks each source proto it would
+// sync, without reading or writing the targets
+func (p *ProtoSyncServiceImpl) Validate(ctx context.Context, config *domain.SyncConfig) ([]domain.FileValidation, error) {
+	if err := p.ValidateConfig(config); err != nil {
+		return nil, domain.WithCode(domain.ErrorCodeInvalidConfig, fmt.Errorf("invalid configuration: %w", err))
+	}
+
+	repositories, err := p.prepareRepositories(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var validations []domain.FileValidation
+	for _, repo := range repositories {
+		repoValidations, err := p.validateRepository(ctx, config, repo)
+		if err != nil {
+			return validations, fmt.Errorf("failed to validate %s@%s: %w", repo.Name, repo.Version, err)
+		}
+		validations = append(validations, repoValidations...)
+	}
+
+	return validations, nil
+}
+
+func (p *ProtoSyncServiceImpl) validateRepository(ctx context.Context, config *domain.SyncConfig, repo domain.Repository) ([]domain.FileValidation, error) {
+	sourcePath, err := p.resolveModuleSource(ctx, config, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceFiles []domain.ProtoFile
+	if len(config.SpecificFiles) > 0 {
+		sourceFiles, err = p.specificSourceFiles(sourcePath, config)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		sourceFiles, err = p.listSourceFiles(sourcePath, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list proto files: %w", err)
+		}
+	}
+	if len(sourceFiles) == 0 {
+		return nil, domain.WithCode(domain.ErrorCodeNoFilesMatched, fmt.Errorf("no proto files found in %s", sourcePath))
+	}
+
+	validations := make([]domain.FileValidation, 0, len(sourceFiles))
+	for _, sourceFile := range sourceFiles {
+		validation := domain.FileValidation{
+			Repository: repo,
+			Name:       targetName(sourcePath, sourceFile, config),
+			Path:       sourceFile.Path,
+		}
+		data, err := p.fileRepo.ReadFile(sourceFile.Path)
+		if err != nil {
+			validation.Error = fmt.Errorf("failed to read file: %w", err)
+		} else {
+			validation.Error = checkProtoSyntax(data)
+		}
+		validations = append(validations, validation)
+	}
+
+	return validations, nil
+}
+
+// checkProtoSyntax is a lexical sanity check rather than a full parser: the
+// file must be non-empty UTF-8 text whose comments and string literals are
+// terminated and whose braces, brackets and parentheses balance
+func checkProtoSyntax(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("file is empty")
+	}
+	if !utf8.Valid(data) {
+		return fmt.Errorf("file is not valid UTF-8")
+	}
+
+	closing := map[byte]byte{'}': '{', ']': '[', ')': '('}
+	type opener struct {
+		char byte
+		line int
+	}
+	var stack []opener
+	line := 1
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\n':
+			line++
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			line++
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			start := line
+			i += 2
+			for ; i < len(data) && !(data[i] == '*' && i+1 < len(data) && data[i+1] == '/'); i++ {
+				if data[i] == '\n' {
+					line++
+				}
+			}
+			if i >= len(data) {
+				return fmt.Errorf("line %d: unterminated block comment", start)
+			}
+			i++
+		case c == '"' || c == '\'':
+			i++
+			for ; i < len(data) && data[i] != c; i++ {
+				if data[i] == '\\' {
+					i++
+				} else if data[i] == '\n' {
+					return fmt.Errorf("line %d: unterminated string literal", line)
+				}
+			}
+			if i >= len(data) {
+				return fmt.Errorf("line %d: unterminated string literal", line)
+			}
+		case c == '{' || c == '[' || c == '(':
+			stack = append(stack, opener{char: c, line: line})
+		case closing[c] != 0:
+			if len(stack) == 0 || stack[len(stack)-1].char != closing[c] {
+				return fmt.Errorf("line %d: unexpected %q", line, c)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if len(stack) > 0 {
+		unclosed := stack[len(stack)-1]
+		return fmt.Errorf("line %d: %q is never closed", unclosed.line, unclosed.char)
+	}
+	return nil
+}
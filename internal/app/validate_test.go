@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckProtoSyntax(t *testing.T) {
+	valid := `syntax = "proto3";
+
+// A comment with an unbalanced { brace
+message Order {
+  string id = 1 [json_name = "id"];
+  /* block } comment */
+  map<string, string> labels = 2;
+  string note = 3 [default = "}"];
+}
+`
+	assert.NoError(t, checkProtoSyntax([]byte(valid)))
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"empty", "", "file is empty"},
+		{"unclosed message", "syntax = \"proto3\";\nmessage A {\n", `line 2: '{' is never closed`},
+		{"stray brace", "message A {}\n}\n", `line 2: unexpected '}'`},
+		{"unterminated string", "syntax = \"proto3;\n", "line 1: unterminated string literal"},
+		{"unterminated comment", "message A {}\n/* trailing", "line 2: unterminated block comment"},
+		{"binary", "\xff\xfe", "not valid UTF-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkProtoSyntax([]byte(tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateRepository(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "mod", "api@v1.0.0")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "good.proto"), "message Good {}\n")
+	writeFile(t, filepath.Join(moduleDir, "schemas", "bad.proto"), "message Bad {\n")
+
+	service := newDiskService(&fakeGoModRepo{moduleDir: moduleDir})
+	config := &domain.SyncConfig{SourcePath: "schemas", TargetPath: filepath.Join(dir, "proto")}
+
+	validations, err := service.validateRepository(context.Background(), config, domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"})
+	require.NoError(t, err)
+
+	failures := make(map[string]bool)
+	for _, validation := range validations {
+		failures[validation.Name] = validation.Error != nil
+	}
+	assert.Equal(t, map[string]bool{"good.proto": false, "bad.proto": true}, failures)
+	assert.NoDirExists(t, filepath.Join(dir, "proto"))
+}
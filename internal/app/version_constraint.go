@@ -0,0 +1,245 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"golang.org/x/mod/semver"
+)
+
+// versionConstraint is a parsed --version range such as `^1.2.0` or
+// `>=1.2, <2.0`. A version satisfies it when it matches every comparison
+// of at least one alternative; alternatives are separated by `||`.
+type versionConstraint struct {
+	alternatives [][]versionComparison
+}
+
+// isVersionConstraint reports whether version is a range rather than an
+// exact tag, branch or commit
+func isVersionConstraint(version string) bool {
+	if version == "" {
+		return false
+	}
+	if strings.ContainsAny(version[:1], "^~<>=*") || strings.ContainsAny(version, ", ") || strings.Contains(version, "||") {
+		return true
+	}
+	// Wildcard versions such as 1.2.x
+	for _, part := range strings.Split(strings.TrimPrefix(version, "v"), ".") {
+		if part == "x" || part == "X" || part == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseVersionConstraint(constraint string) (versionConstraint, error) {
+	var parsed versionConstraint
+	for _, alternative := range strings.Split(constraint, "||") {
+		var comparisons []versionComparison
+		for _, term := range strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' }) {
+			termComparisons, err := parseConstraintTerm(term)
+			if err != nil {
+				return versionConstraint{}, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+			}
+			comparisons = append(comparisons, termComparisons...)
+		}
+		if len(comparisons) == 0 {
+			return versionConstraint{}, fmt.Errorf("invalid version constraint %q: empty range", constraint)
+		}
+		parsed.alternatives = append(parsed.alternatives, comparisons)
+	}
+	return parsed, nil
+}
+
+// parseConstraintTerm expands one term, e.g. `^1.2.0` becomes
+// `>=1.2.0 <2.0.0`
+func parseConstraintTerm(term string) ([]versionComparison, error) {
+	switch {
+	case term == "*" || term == "x" || term == "X":
+		return []versionComparison{{op: ">=", version: "v0.0.0-0"}}, nil
+	case strings.HasPrefix(term, "^"):
+		return caretRange(term[1:])
+	case strings.HasPrefix(term, "~"):
+		return tildeRange(term[1:])
+	}
+
+	for _, op := range sourceRuleOperators {
+		if strings.HasPrefix(term, op) {
+			version, err := constraintVersion(term[len(op):])
+			if err != nil {
+				return nil, err
+			}
+			return []versionComparison{{op: op, version: version}}, nil
+		}
+	}
+
+	if lower, upper, ok := wildcardRange(term); ok {
+		return []versionComparison{{op: ">=", version: lower}, {op: "<", version: upper}}, nil
+	}
+
+	version, err := constraintVersion(term)
+	if err != nil {
+		return nil, err
+	}
+	return []versionComparison{{op: "=", version: version}}, nil
+}
+
+// caretRange allows changes that keep the left-most non-zero component
+func caretRange(version string) ([]versionComparison, error) {
+	lower, err := constraintVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	major, minor, patch := versionParts(lower)
+	var upper string
+	switch {
+	case major != 0:
+		upper = fmt.Sprintf("v%d.0.0", major+1)
+	case minor != 0:
+		upper = fmt.Sprintf("v0.%d.0", minor+1)
+	default:
+		upper = fmt.Sprintf("v0.0.%d", patch+1)
+	}
+	return []versionComparison{{op: ">=", version: lower}, {op: "<", version: upper + "-0"}}, nil
+}
+
+// tildeRange allows patch changes, or minor changes when only a major
+// version is given
+func tildeRange(version string) ([]versionComparison, error) {
+	lower, err := constraintVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	major, minor, _ := versionParts(lower)
+	upper := fmt.Sprintf("v%d.%d.0", major, minor+1)
+	if !strings.Contains(strings.TrimPrefix(version, "v"), ".") {
+		upper = fmt.Sprintf("v%d.0.0", major+1)
+	}
+	return []versionComparison{{op: ">=", version: lower}, {op: "<", version: upper + "-0"}}, nil
+}
+
+// wildcardRange turns 1.x or 1.2.* into a half-open range
+func wildcardRange(term string) (string, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(term, "v"), ".")
+	for i, part := range parts {
+		if part != "x" && part != "X" && part != "*" {
+			continue
+		}
+		prefix := "v" + strings.Join(parts[:i], ".")
+		if i == 0 || !semver.IsValid(prefix) {
+			return "", "", false
+		}
+		major, minor, _ := versionParts(prefix)
+		if i == 1 {
+			return fmt.Sprintf("v%d.0.0-0", major), fmt.Sprintf("v%d.0.0-0", major+1), true
+		}
+		return fmt.Sprintf("v%d.%d.0-0", major, minor), fmt.Sprintf("v%d.%d.0-0", major, minor+1), true
+	}
+	return "", "", false
+}
+
+// constraintVersion canonicalizes a version inside a constraint
+func constraintVersion(version string) (string, error) {
+	canonical := canonicalVersion(version)
+	if !semver.IsValid(canonical) {
+		return "", fmt.Errorf("%q is not a semantic version", version)
+	}
+	return semver.Canonical(canonical), nil
+}
+
+// versionParts returns the numeric components of a canonical version
+func versionParts(version string) (int, int, int) {
+	var major, minor, patch int
+	fmt.Sscanf(strings.SplitN(semver.Canonical(version), "-", 2)[0], "v%d.%d.%d", &major, &minor, &patch)
+	return major, minor, patch
+}
+
+// matches reports whether version satisfies the constraint. Like npm and
+// Cargo, pre-releases only match when a comparison of the same alternative
+// names a pre-release of the same major.minor.patch, so `^1.2.0` never
+// selects v1.3.0-rc.1 while `>=1.3.0-rc.1` does.
+func (c versionConstraint) matches(version string) bool {
+	canonical := canonicalVersion(version)
+	if !semver.IsValid(canonical) {
+		return false
+	}
+
+	for _, comparisons := range c.alternatives {
+		if semver.Prerelease(canonical) != "" && !allowsPrerelease(comparisons, canonical) {
+			continue
+		}
+
+		matched := true
+		for _, comparison := range comparisons {
+			if !comparison.matches(canonical) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func allowsPrerelease(comparisons []versionComparison, version string) bool {
+	base := strings.SplitN(semver.Canonical(version), "-", 2)[0]
+	for _, comparison := range comparisons {
+		// Upper bounds end in -0 so they exclude pre-releases of the bound
+		if semver.Prerelease(comparison.version) == "" || semver.Prerelease(comparison.version) == "-0" {
+			continue
+		}
+		if strings.SplitN(comparison.version, "-", 2)[0] == base {
+			return true
+		}
+	}
+	return false
+}
+
+// highestMatch returns the highest version in available satisfying c
+func (c versionConstraint) highestMatch(available []string) (string, bool) {
+	best, bestCanonical := "", ""
+	for _, version := range available {
+		if !c.matches(version) {
+			continue
+		}
+		canonical := canonicalVersion(version)
+		if best == "" || semver.Compare(canonical, bestCanonical) > 0 {
+			best, bestCanonical = version, canonical
+		}
+	}
+	return best, best != ""
+}
+
+// applyVersionConstraint resolves a --version range to the highest
+// matching version available for each repository
+func (p *ProtoSyncServiceImpl) applyVersionConstraint(repositories []domain.Repository, constraint string) error {
+	parsed, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return err
+	}
+
+	for i := range repositories {
+		repo := &repositories[i]
+		if versionPinned(*repo) {
+			continue
+		}
+		versions, err := p.goModRepo.ListVersions(repo.Name)
+		if err != nil {
+			return fmt.Errorf("failed to list versions of %s to resolve %q: %w", repo.Name, constraint, err)
+		}
+
+		version, ok := parsed.highestMatch(versions)
+		if !ok {
+			return fmt.Errorf("no version of %s satisfies %q (available: %s)", repo.Name, constraint, strings.Join(versions, ", "))
+		}
+
+		p.logger.Info("Resolved %s %s to %s", repo.Name, constraint, version)
+		repo.Version = version
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionConstraintMatches(t *testing.T) {
+	available := []string{"v0.9.0", "v1.1.0", "v1.2.0", "v1.2.5", "v1.3.0-rc.1", "v1.4.0", "v2.0.0-beta.1", "v2.0.0", "v2.1.0"}
+
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{constraint: "^1.2.0", want: "v1.4.0"},
+		{constraint: "~1.2.0", want: "v1.2.5"},
+		{constraint: ">=1.2, <2.0", want: "v1.4.0"},
+		{constraint: ">=1.2 <1.4", want: "v1.2.5"},
+		{constraint: "1.2.x", want: "v1.2.5"},
+		{constraint: "1.x", want: "v1.4.0"},
+		{constraint: "*", want: "v2.1.0"},
+		{constraint: "^0.9.0 || ^2.0.0", want: "v2.1.0"},
+		{constraint: ">=1.3.0-rc.1, <1.4.0", want: "v1.3.0-rc.1"},
+		{constraint: ">=2.0.0-beta.1, <2.0.0", want: "v2.0.0-beta.1"},
+		{constraint: "^0.9.0", want: "v0.9.0"},
+	}
+	for _, tt := range tests {
+		assert.True(t, isVersionConstraint(tt.constraint), tt.constraint)
+		constraint, err := parseVersionConstraint(tt.constraint)
+		require.NoError(t, err, tt.constraint)
+		got, ok := constraint.highestMatch(available)
+		assert.True(t, ok, tt.constraint)
+		assert.Equal(t, tt.want, got, tt.constraint)
+	}
+
+	constraint, err := parseVersionConstraint("^3.0.0")
+	require.NoError(t, err)
+	_, ok := constraint.highestMatch(available)
+	assert.False(t, ok)
+
+	for _, exact := range []string{"v1.2.0", "main", "0123abcd"} {
+		assert.False(t, isVersionConstraint(exact), exact)
+	}
+	for _, invalid := range []string{"^banana", ">=1.2, <", ">=1.2 || "} {
+		_, err := parseVersionConstraint(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestApplyVersionConstraint(t *testing.T) {
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}, goModRepo: &fakeGoModRepo{versions: []string{"v1.0.0", "v1.5.2", "v2.0.0"}}}
+
+	repos := []domain.Repository{{Name: "github.com/example/api", Version: "v1.0.0"}}
+	require.NoError(t, service.applyVersionConstraint(repos, "^1.0.0"))
+	assert.Equal(t, "v1.5.2", repos[0].Version)
+
+	err := service.applyVersionConstraint(repos, ">=3.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no version")
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/mod/semver"
+)
+
+var (
+	// looksLikeVersion matches strings meant as release versions rather
+	// than branch names, e.g. v1.2, 1.2.3 or v2
+	looksLikeVersion = regexp.MustCompile(`^v?[0-9]+(\.|$|-|\+)`)
+	// commitHash matches abbreviated and full commit hashes, which go mod
+	// download resolves to pseudo-versions
+	commitHash = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+)
+
+// validateVersion checks that a version given for a module is one go mod
+// download accepts: "latest", a semantic version or pseudo-version with its
+// leading v, a commit hash or a branch name. Ranges are checked by
+// parseVersionConstraint instead.
+func validateVersion(version string) error {
+	if version == "" || version == "latest" || isVersionConstraint(version) || commitHash.MatchString(version) {
+		return nil
+	}
+	if !looksLikeVersion.MatchString(version) {
+		// Branch names and other queries are resolved by go
+		return nil
+	}
+	if semver.IsValid(version) {
+		return nil
+	}
+
+	if version[0] != 'v' && semver.IsValid("v"+version) {
+		return fmt.Errorf("invalid version %q: module versions start with v, did you mean v%s?", version, version)
+	}
+	return fmt.Errorf("invalid version %q: expected a semantic version such as v1.2.3, a pseudo-version such as v0.0.0-20240102150405-abcdef123456, or latest", version)
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateVersion(t *testing.T) {
+	for _, version := range []string{"", "latest", "v1.2.3", "v2.0.0-rc.1", "v0.0.0-20240102150405-abcdef123456", "v1.2", "^1.2.0", "main", "release/v2", "1a2b3c4d"} {
+		assert.NoError(t, validateVersion(version), version)
+	}
+
+	err := validateVersion("1.2.3")
+	assert.ErrorContains(t, err, `did you mean v1.2.3?`)
+	for _, version := range []string{"v1.2.3.4", "v1.02.3", "2024.01", "v1.2.3-"} {
+		assert.ErrorContains(t, validateVersion(version), "expected a semantic version", version)
+	}
+}
+
+func TestValidateConfigVersionFormat(t *testing.T) {
+	service := &ProtoSyncServiceImpl{logger: nopLogger{}}
+	base := domain.SyncConfig{BufYamlPath: "buf.yaml", GoModPath: "go.mod", SourcePath: "proto"}
+
+	config := base
+	config.SpecifiedVersion = "1.4.0"
+	assert.ErrorContains(t, service.ValidateConfig(&config), "did you mean v1.4.0?")
+
+	config = base
+	config.Repositories = []domain.Repository{{Name: "github.com/example/api", Version: "v1.4"}, {Name: "github.com/example/users", Version: "2.0.0-beta"}}
+	assert.ErrorContains(t, service.ValidateConfig(&config), "github.com/example/users: invalid version")
+}
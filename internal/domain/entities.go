@@ -7,6 +7,42 @@ type Repository struct {
 	Name    string
 	Version string
 	URL     string
+	// TargetPath is the directory this repository syncs into, either set
+	// for the repository in the config file or --repo, or the matching
+	// module when buf.yaml declares several; empty uses the config target
+	TargetPath string
+	// SourcePath overrides the global source path and --source-rule for
+	// this repository; empty uses them
+	SourcePath string
+	// LocalPath is the directory of a filesystem replace in go.mod; the
+	// protos are copied from it instead of downloading a version
+	LocalPath string
+}
+
+// ChangeType classifies how a synced file relates to the existing target
+type ChangeType string
+
+const (
+	ChangeAdded     ChangeType = "added"
+	ChangeModified  ChangeType = "modified"
+	ChangeUnchanged ChangeType = "unchanged"
+)
+
+// HasPendingChanges reports whether any file in results would be added,
+// modified or pruned. Results whose changes are unknown count as pending,
+// since they can't be shown to be in sync.
+func HasPendingChanges(results []SyncResult) bool {
+	for _, result := range results {
+		if result.ChangesUnknown || len(result.PrunedFiles) > 0 {
+			return true
+		}
+		for _, file := range result.FilesUpdated {
+			if file.Change == ChangeAdded || file.Change == ChangeModified {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // ProtoFile represents a protobuf file
@@ -15,20 +51,158 @@ type ProtoFile struct {
 	Path         string
 	Size         int64
 	ModifiedTime time.Time
+	Change       ChangeType
+	// Targets lists every target directory the file was written to
+	Targets []string
+	// Skipped is set when every target already held identical content, so
+	// nothing was written
+	Skipped bool
+	// SHA256 is the hex digest of the content installed in the targets
+	SHA256 string
 }
 
+// Sources repositories are auto-detected from
+const (
+	SourceOfTruthGoMod = "gomod"
+	SourceOfTruthBuf   = "buf"
+)
+
 // SyncConfig represents the configuration for syncing proto files
 type SyncConfig struct {
-	Repositories     []Repository
-	SourcePath       string
-	TargetPath       string
-	BufYamlPath      string
-	GoModPath        string
-	SpecificFile     string
-	DryRun           bool
+	Repositories  []Repository
+	SourcePath    string
+	TargetPath    string
+	BufYamlPath   string
+	GoModPath     string
+	VersionsFile  string
+	SpecificFiles []string
+	DryRun        bool
+	// DryRunDiff makes a dry run download modules that aren't cached yet so
+	// every file can be compared with its target
+	DryRunDiff       bool
 	SingleRepo       bool
+	SortRepos        bool
 	ListVersions     bool
 	SpecifiedVersion string
+	// Targets overrides the buf.yaml module path; every synced file is
+	// copied into each target
+	Targets []string
+	// LatestPatch upgrades each repository to the newest patch release of
+	// its major.minor
+	LatestPatch bool
+	// Latest ignores go.mod versions and syncs the newest release
+	Latest bool
+	// Concurrency is the number of repositories processed in parallel;
+	// values below 2 process them one at a time
+	Concurrency int
+	// CheckPackagePath warns when a proto's package doesn't match its path
+	CheckPackagePath bool
+	// Recursive preserves source subdirectories under the target instead of
+	// flattening files into it
+	Recursive bool
+	// Progress receives live progress events; nil disables reporting
+	Progress ProgressReporter
+	// ProgressBar is set when a live progress display shows copies, so the
+	// per-file listing is only logged at debug level
+	ProgressBar bool
+	// FailFast stops a sync at the first repository that fails, cancelling
+	// those still in flight
+	FailFast bool
+	// Generate runs `buf generate` next to buf.yaml after a fully
+	// successful sync
+	Generate bool
+	// Lint runs `buf lint` on every target after syncing
+	Lint bool
+	// Prune deletes orphaned target files that no repository provided
+	Prune bool
+	// Backup copies target files that are about to be replaced into
+	// <target>/.proto-sync-backup/<timestamp>/ first
+	Backup bool
+	// Atomic copies into a staging directory beside each target and swaps
+	// it into place only when every repository synced
+	Atomic bool
+	// BackupDir is read by rollback instead of <target>/.proto-sync-backup
+	BackupDir string
+	// DefaultModulePath is the target for buf v1 files, which declare no
+	// module path; empty uses the directory containing buf.yaml
+	DefaultModulePath string
+	// Module selects the buf.yaml module, by name or path, when buf.yaml
+	// declares more than one
+	Module string
+	// SourceOfTruth picks where repositories are detected from when none
+	// are given: SourceOfTruthGoMod or SourceOfTruthBuf
+	SourceOfTruth string
+	// Retries is the number of times a failed module download is retried
+	Retries int
+	// RetryDelay is the wait before the first retry; it doubles each time
+	RetryDelay time.Duration
+	// Netrc and Token authenticate module downloads; see DownloadOptions
+	Netrc string
+	Token string
+	// DereferenceSymlinks follows symlinks while listing source files
+	DereferenceSymlinks bool
+	// Force rewrites target files even when they are already identical
+	Force bool
+	// Since skips source files not modified after it; the zero time copies
+	// every file
+	Since time.Time
+	// StableOnly makes --latest skip pre-releases and pseudo-versions
+	StableOnly bool
+	// Timeout bounds the whole run, downloads and copies included; zero
+	// means no limit
+	Timeout time.Duration
+	// Verify checks every written file against its source by SHA-256
+	Verify bool
+	// VerifyCount re-lists the source after copying and fails on mismatches
+	VerifyCount bool
+	// VerifySum checks every downloaded module against the go.sum next to
+	// GoModPath
+	VerifySum bool
+	// SourceReadonlyCheck rejects source paths that resolve outside GOMODCACHE
+	SourceReadonlyCheck bool
+	// FilePatterns select files to sync relative to the source path;
+	// entries starting with "!" exclude matches (default: *.proto)
+	FilePatterns []string
+	// Include restricts syncing to source files whose relative path matches
+	// at least one glob; it is applied before Exclude
+	Include []string
+	// Exclude skips source files whose relative path matches any glob
+	Exclude []string
+	// SourceRules pick a source path by version, e.g. "<2.0.0=api/v1"
+	SourceRules []string
+	// FileCacheDir caches proto sets by module@version to skip downloads
+	FileCacheDir string
+	// StateFile records hashes of written files to detect local edits
+	StateFile string
+	// ProtectEdits refuses to overwrite files edited since the last sync
+	ProtectEdits bool
+	// Transform is a shell command each proto is piped through during copy
+	Transform string
+	// MergeFiles are shared files that must be identical across repositories
+	MergeFiles []string
+	// Protect lists glob patterns of target files that must never be pruned
+	Protect []string
+	// BeforeSync is a shell command run before anything is downloaded; a
+	// failure aborts the sync
+	BeforeSync string
+	// AfterSync is a shell command run once every repository synced
+	AfterSync string
+	// Manifest is a JSON file recording the versions and file hashes of a
+	// fully successful sync
+	Manifest string
+}
+
+// DefaultFilePatterns select the files synced when no --pattern is given
+var DefaultFilePatterns = []string{"*.proto"}
+
+// DefaultProtectedFiles are buf configuration files that live next to protos
+// in a target directory and are always protected from pruning
+var DefaultProtectedFiles = []string{
+	"buf.yaml",
+	"buf.lock",
+	"buf.md",
+	"buf.gen.yaml",
+	"buf.work.yaml",
 }
 
 // SyncResult represents the result of a sync operation
@@ -37,6 +211,74 @@ type SyncResult struct {
 	FilesUpdated []ProtoFile
 	Success      bool
 	Error        error
+	Warnings     []string
+	// OrphanedFiles are target files no repository provided in this sync;
+	// they are deleted when Prune is set
+	OrphanedFiles []ProtoFile
+	// PrunedFiles are the orphaned files that were deleted, or in a dry run
+	// would be
+	PrunedFiles []ProtoFile
+	// ChangesUnknown is set by dry runs that couldn't compare the files,
+	// because the module isn't downloaded
+	ChangesUnknown bool
+	// LintPassed reports whether `buf lint` passed on the repository's
+	// targets; nil when lint did not run
+	LintPassed *bool
+	// Timings records how long each phase of the sync took
+	Timings SyncTimings
+}
+
+// SyncTimings records how long syncing a repository took. Resolving the
+// module path is part of Download, since `go mod download` reports it.
+type SyncTimings struct {
+	// Download covers fetching the module, or finding it in the file cache
+	// or a local replace, including go.sum verification
+	Download time.Duration
+	// Copy covers listing, transforming and writing the protos
+	Copy time.Duration
+	// Total is the whole repository from start to result
+	Total time.Duration
+}
+
+// TotalBytes returns the combined size of the files synced for result.
+// A file written to several targets is counted once.
+func (r SyncResult) TotalBytes() int64 {
+	var total int64
+	for _, file := range r.FilesUpdated {
+		total += file.Size
+	}
+	return total
+}
+
+// SkippedFiles returns how many of the files synced for result were left
+// untouched because their targets were already identical
+func (r SyncResult) SkippedFiles() int {
+	skipped := 0
+	for _, file := range r.FilesUpdated {
+		if file.Skipped {
+			skipped++
+		}
+	}
+	return skipped
+}
+
+// TotalBytes returns the combined size of the files synced for results
+func TotalBytes(results []SyncResult) int64 {
+	var total int64
+	for _, result := range results {
+		total += result.TotalBytes()
+	}
+	return total
+}
+
+// LintFailed reports whether `buf lint` failed for any result
+func LintFailed(results []SyncResult) bool {
+	for _, result := range results {
+		if result.LintPassed != nil && !*result.LintPassed {
+			return true
+		}
+	}
+	return false
 }
 
 // ModuleInfo represents information from buf.yaml
@@ -50,3 +292,147 @@ type GoModInfo struct {
 	Repositories []Repository
 	ModuleName   string
 }
+
+// DownloadOptions control how modules are downloaded
+type DownloadOptions struct {
+	// Retries is the number of retries after a transient failure
+	Retries int
+	// RetryDelay is the wait before the first retry; it doubles each time
+	RetryDelay time.Duration
+	// Netrc is a .netrc file the go command reads credentials from
+	Netrc string
+	// Token authenticates git over HTTPS to the module's host, as TOKEN or
+	// USER:TOKEN. It must never be logged.
+	Token string
+}
+
+// DownloadOptions returns the download settings of config
+func (c *SyncConfig) DownloadOptions() DownloadOptions {
+	return DownloadOptions{Retries: c.Retries, RetryDelay: c.RetryDelay, Netrc: c.Netrc, Token: c.Token}
+}
+
+// ListOptions control how directories are walked when listing files
+type ListOptions struct {
+	// FollowSymlinks lists the files of symlinked directories, and the
+	// targets of symlinked files, as if they were in place
+	FollowSymlinks bool
+}
+
+// SourceListOptions returns the settings for walking source directories
+func (c *SyncConfig) SourceListOptions() ListOptions {
+	return ListOptions{FollowSymlinks: c.DereferenceSymlinks}
+}
+
+// UpdateCheck compares a repository's pinned version with the newest one
+type UpdateCheck struct {
+	Repository Repository
+	// Current is the version pinned in go.mod or the versions file
+	Current string
+	// Latest is empty when it couldn't be resolved or for local replaces
+	Latest string
+	// UpdateAvailable is set when Latest is newer than Current
+	UpdateAvailable bool
+	// Error is set when the latest version couldn't be resolved
+	Error error
+}
+
+// FileValidation is the outcome of checking one source proto file
+type FileValidation struct {
+	Repository Repository
+	Name       string
+	Path       string
+	// Error is nil when the file passed
+	Error error
+}
+
+// FileDiff describes how syncing one file would change a target file
+type FileDiff struct {
+	Repository Repository
+	Name       string
+	Path       string
+	Change     ChangeType
+	// Diff is the unified diff of the change, empty when unchanged
+	Diff string
+}
+
+// RollbackResult lists the files restored into one target
+type RollbackResult struct {
+	Target    string
+	BackupDir string
+	// Files are the restored paths relative to Target
+	Files []string
+}
+
+// ManifestDrift is a target file that no longer matches the manifest of
+// the sync that wrote it
+type ManifestDrift struct {
+	Repository string
+	// Path is the target file
+	Path string
+	// Missing is set when the file is gone; otherwise its content differs
+	Missing bool
+	// Expected and Actual are SHA-256 digests; Actual is empty when Missing
+	Expected string
+	Actual   string
+}
+
+// InitOptions describes the files `proto-sync init` scaffolds
+type InitOptions struct {
+	// ConfigPath receives Config, the rendered proto-sync.yaml
+	ConfigPath string
+	Config     []byte
+	// GoModPath gets the // Protobuf libraries marker when it lacks one
+	GoModPath string
+	// BufYamlPath is created with a single module at ModulePath; empty
+	// leaves buf.yaml alone
+	BufYamlPath string
+	ModulePath  string
+	// Force overwrites an existing config file and buf.yaml
+	Force bool
+}
+
+// InitResult reports what init did to one file
+type InitResult struct {
+	Path string
+	// Action is created, overwritten, updated, unchanged or missing
+	Action string
+}
+
+// CacheInfo describes the contents of proto-sync's file cache
+type CacheInfo struct {
+	Path      string
+	SizeBytes int64
+	FileCount int
+	Entries   []string
+}
+
+// Progress event names
+const (
+	ProgressRepoStart  = "repo_start"
+	ProgressFileCopied = "file_copied"
+	ProgressRepoDone   = "repo_done"
+)
+
+// ProgressEvent is a single live progress update emitted during a sync
+type ProgressEvent struct {
+	Event   string     `json:"event"`
+	Repo    string     `json:"repo,omitempty"`
+	Version string     `json:"version,omitempty"`
+	Name    string     `json:"name,omitempty"`
+	Change  ChangeType `json:"change,omitempty"`
+	Success *bool      `json:"success,omitempty"`
+	Error   string     `json:"error,omitempty"`
+	// Copied and Total count the files of a repository in file_copied
+	// events
+	Copied int `json:"copied,omitempty"`
+	Total  int `json:"total,omitempty"`
+}
+
+// CombineChange merges the changes of one file written to several targets,
+// preferring the first change that is not ChangeUnchanged
+func CombineChange(current, next ChangeType) ChangeType {
+	if current == "" || current == ChangeUnchanged {
+		return next
+	}
+	return current
+}
@@ -40,7 +40,7 @@ func TestSyncConfig(t *testing.T) {
 		TargetPath:       "proto",
 		BufYamlPath:      "buf.yaml",
 		GoModPath:        "../go.mod",
-		SpecificFile:     "test.proto",
+		SpecificFiles:    []string{"test.proto"},
 		DryRun:           true,
 		SingleRepo:       false,
 		ListVersions:     false,
@@ -71,3 +71,15 @@ func TestSyncResult(t *testing.T) {
 	assert.True(t, result.Success)
 	assert.NoError(t, result.Error)
 }
+
+func TestHasPendingChanges(t *testing.T) {
+	unchanged := []SyncResult{{FilesUpdated: []ProtoFile{{Name: "a.proto", Change: ChangeUnchanged}}}}
+	assert.False(t, HasPendingChanges(unchanged))
+	assert.False(t, HasPendingChanges(nil))
+
+	modified := append(unchanged, SyncResult{FilesUpdated: []ProtoFile{{Name: "b.proto", Change: ChangeModified}}})
+	assert.True(t, HasPendingChanges(modified))
+
+	assert.True(t, HasPendingChanges(append(unchanged, SyncResult{PrunedFiles: []ProtoFile{{Name: "old.proto"}}})))
+	assert.True(t, HasPendingChanges(append(unchanged, SyncResult{ChangesUnknown: true})))
+}
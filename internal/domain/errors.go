@@ -0,0 +1,83 @@
+package domain
+
+import "errors"
+
+// ErrorCode classifies why a repository failed to sync
+type ErrorCode string
+
+const (
+	ErrorCodeSyncFailed         ErrorCode = "sync_failed"
+	ErrorCodeInvalidConfig      ErrorCode = "invalid_config"
+	ErrorCodeDownloadFailed     ErrorCode = "download_failed"
+	ErrorCodeModuleNotFound     ErrorCode = "module_not_found"
+	ErrorCodeSourceNotFound     ErrorCode = "source_not_found"
+	ErrorCodeFileNotFound       ErrorCode = "file_not_found"
+	ErrorCodeCopyFailed         ErrorCode = "copy_failed"
+	ErrorCodeNoFilesMatched     ErrorCode = "no_files_matched"
+	ErrorCodeLocalEdits         ErrorCode = "local_edits"
+	ErrorCodeMergeConflict      ErrorCode = "merge_conflict"
+	ErrorCodeVerificationFailed ErrorCode = "verification_failed"
+	ErrorCodeSourceOutsideCache ErrorCode = "source_outside_cache"
+	ErrorCodeCancelled          ErrorCode = "cancelled"
+	ErrorCodeLintFailed         ErrorCode = "lint_failed"
+	ErrorCodeChecksumMismatch   ErrorCode = "checksum_mismatch"
+)
+
+// Sentinel errors for errors.Is. Any error carrying the same code matches,
+// whatever its message, e.g. errors.Is(err, ErrSourceDirMissing) holds for
+// every "source directory not found" failure.
+var (
+	ErrInvalidConfig    = newSentinel(ErrorCodeInvalidConfig, "invalid configuration")
+	ErrModuleNotFound   = newSentinel(ErrorCodeModuleNotFound, "module or version not found")
+	ErrDownloadFailed   = newSentinel(ErrorCodeDownloadFailed, "module download failed")
+	ErrSourceDirMissing = newSentinel(ErrorCodeSourceNotFound, "source directory not found")
+	ErrCopyFailed       = newSentinel(ErrorCodeCopyFailed, "copy failed")
+)
+
+func newSentinel(code ErrorCode, msg string) error {
+	return &CodedError{Code: code, Err: errors.New(msg)}
+}
+
+// CodedError attaches an ErrorCode to an error without changing its message
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches any CodedError with the same code, so errors compare equal to
+// the sentinel of their code
+func (e *CodedError) Is(target error) bool {
+	t, ok := target.(*CodedError)
+	return ok && t.Code == e.Code
+}
+
+// WithCode wraps err with code
+func WithCode(code ErrorCode, err error) error {
+	return &CodedError{Code: code, Err: err}
+}
+
+// WithDefaultCode wraps err with code unless it already carries a code
+func WithDefaultCode(code ErrorCode, err error) error {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return err
+	}
+	return WithCode(code, err)
+}
+
+// CodeOf returns the code attached to err, or ErrorCodeSyncFailed
+func CodeOf(err error) ErrorCode {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ErrorCodeSyncFailed
+}
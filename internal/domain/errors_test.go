@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	base := errors.New("boom")
+	coded := WithCode(ErrorCodeLocalEdits, base)
+
+	assert.Equal(t, "boom", coded.Error())
+	assert.ErrorIs(t, coded, base)
+	assert.Equal(t, ErrorCodeLocalEdits, CodeOf(fmt.Errorf("failed to copy: %w", coded)))
+	assert.Equal(t, ErrorCodeSyncFailed, CodeOf(base))
+}
+
+func TestSentinelErrors(t *testing.T) {
+	err := fmt.Errorf("failed to sync: %w", WithCode(ErrorCodeSourceNotFound, errors.New("source directory not found: /cache/api/proto")))
+
+	assert.ErrorIs(t, err, ErrSourceDirMissing)
+	assert.NotErrorIs(t, err, ErrModuleNotFound)
+	assert.NotErrorIs(t, errors.New("source directory not found"), ErrSourceDirMissing)
+}
+
+func TestWithDefaultCode(t *testing.T) {
+	coded := WithCode(ErrorCodeLocalEdits, errors.New("edited"))
+	assert.Equal(t, ErrorCodeLocalEdits, CodeOf(WithDefaultCode(ErrorCodeCopyFailed, fmt.Errorf("copy: %w", coded))))
+	assert.Equal(t, ErrorCodeCopyFailed, CodeOf(WithDefaultCode(ErrorCodeCopyFailed, errors.New("disk full"))))
+}
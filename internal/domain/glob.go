@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether name, a slash separated path relative to the
+// directory being synced, matches pattern.
+//
+// Patterns without a slash match the base name only, exactly like
+// filepath.Match, so `*.proto` selects protos at any depth. Patterns with a
+// slash are matched segment by segment against the whole relative path, and a
+// `**` segment matches zero or more directories (`api/**/*.proto`).
+func MatchGlob(pattern, name string) (bool, error) {
+	name = strings.TrimPrefix(name, "./")
+
+	if !strings.Contains(pattern, "/") {
+		return path.Match(pattern, path.Base(name))
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return true, nil
+			}
+			for i := 0; i <= len(name); i++ {
+				matched, err := matchSegments(pattern[1:], name[i:])
+				if err != nil || matched {
+					return matched, err
+				}
+			}
+			return false, nil
+		}
+
+		if len(name) == 0 {
+			return false, nil
+		}
+
+		matched, err := path.Match(pattern[0], name[0])
+		if err != nil || !matched {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0, nil
+}
+
+// ValidateGlob checks that pattern, optionally negated with a leading "!",
+// is well formed
+func ValidateGlob(pattern string) error {
+	pattern = strings.TrimPrefix(pattern, "!")
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MatchPatterns reports whether name is selected by patterns. A name is
+// selected when it matches at least one positive pattern (or there are no
+// positive patterns) and no pattern negated with a leading "!".
+func MatchPatterns(patterns []string, name string) (bool, error) {
+	selected := true
+	hasPositive := false
+
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		if !hasPositive {
+			hasPositive = true
+			selected = false
+		}
+		matched, err := MatchGlob(pattern, name)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			selected = true
+			break
+		}
+	}
+
+	if !selected {
+		return false, nil
+	}
+
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		matched, err := MatchGlob(strings.TrimPrefix(pattern, "!"), name)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.proto", name: "a.proto", want: true},
+		{pattern: "*.proto", name: "api/v1/a.proto", want: true},
+		{pattern: "*.proto", name: "a.proto3", want: false},
+		{pattern: "v1/*.proto", name: "v1/a.proto", want: true},
+		{pattern: "v1/*.proto", name: "api/v1/a.proto", want: false},
+		{pattern: "v1/*.proto", name: "v1/nested/a.proto", want: false},
+		{pattern: "**/*.proto", name: "a.proto", want: true},
+		{pattern: "**/*.proto", name: "api/v1/a.proto", want: true},
+		{pattern: "api/**/*.proto", name: "api/a.proto", want: true},
+		{pattern: "api/**/*.proto", name: "api/v1/beta/a.proto", want: true},
+		{pattern: "api/**/*.proto", name: "common/a.proto", want: false},
+		{pattern: "api/**", name: "api/v1/a.proto", want: true},
+		{pattern: "**/internal/*.proto", name: "api/internal/a.proto", want: true},
+		{pattern: "**/internal/*.proto", name: "api/internal/deep/a.proto", want: false},
+		{pattern: "./v1/*.proto", name: "./v1/a.proto", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			got, err := MatchGlob(tt.pattern, tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMatchPatterns(t *testing.T) {
+	patterns := []string{"**/*.proto", "!**/internal/**", "!*_test.proto"}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "api/v1/orders.proto", want: true},
+		{name: "api/internal/secret.proto", want: false},
+		{name: "api/v1/orders_test.proto", want: false},
+		{name: "README.md", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchPatterns(patterns, tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	onlyNegated, err := MatchPatterns([]string{"!legacy/**"}, "api/a.proto")
+	require.NoError(t, err)
+	assert.True(t, onlyNegated)
+}
+
+func TestValidateGlob(t *testing.T) {
+	assert.NoError(t, ValidateGlob("**/*.proto"))
+	assert.NoError(t, ValidateGlob("!api/[ab]*.proto"))
+	assert.Error(t, ValidateGlob("api/[broken/*.proto"))
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Logger defines the logging interface
 type Logger interface {
@@ -9,6 +12,9 @@ type Logger interface {
 	Warning(msg string, args ...interface{})
 	Error(msg string, args ...interface{})
 	Debug(msg string, args ...interface{})
+	// Plain logs a message at info level without a level prefix, for
+	// output meant to be read as is such as the dry-run preview
+	Plain(msg string, args ...interface{})
 }
 
 // FileRepository handles file system operations
@@ -16,29 +22,122 @@ type FileRepository interface {
 	ReadFile(path string) ([]byte, error)
 	WriteFile(path string, data []byte) error
 	CopyFile(src, dst string) error
+	// VerifyCopy returns an error when dst is not byte-identical to src
+	VerifyCopy(src, dst string) error
 	CreateDir(path string) error
 	FileExists(path string) bool
-	ListFiles(path string, pattern string) ([]ProtoFile, error)
+	IsDir(path string) bool
+	ModTime(path string) (time.Time, error)
+	ListFiles(path string, pattern string, opts ListOptions) ([]ProtoFile, error)
 	MakeWritable(path string) error
+	// DeleteFile removes a single file, even when it is read-only
+	DeleteFile(path string) error
+	ResolvePath(path string) (string, error)
+	DirSize(path string) (int64, int, error)
+	RemoveAll(path string) error
+	// TempDir creates a new, uniquely named directory in dir whose name
+	// starts with prefix
+	TempDir(dir, prefix string) (string, error)
+	// CopyDir copies the tree below src into dst, keeping file modes and
+	// modification times
+	CopyDir(src, dst string) error
+	// MoveDir moves the directory src to dst, copying and removing src
+	// when they are on different filesystems. dst must not exist or be an
+	// empty directory.
+	MoveDir(src, dst string) error
 }
 
 // GoModRepository handles go.mod operations
 type GoModRepository interface {
 	ParseProtobufLibraries(goModPath string) (*GoModInfo, error)
+	ParseVersionsFile(path string) (map[string]string, error)
+	// ParseGoSum returns the h1: hash of every module@version in a go.sum
+	// file, ignoring the /go.mod entries
+	ParseGoSum(goSumPath string) (map[string]string, error)
+	// HashModuleDir computes the go.sum h1: hash of an extracted module
+	HashModuleDir(dir, repo, version string) (string, error)
 	GetLatestVersion(repo string) (string, error)
 	ListVersions(repo string) ([]string, error)
-	DownloadModule(ctx context.Context, repo, version string) error
+	// DownloadModule downloads repo@version and returns the directory go
+	// extracted it to
+	DownloadModule(ctx context.Context, repo, version string, opts DownloadOptions) (string, error)
+	// GetModulePath predicts the module cache directory of repo@version
+	// without downloading it; prefer the directory DownloadModule returns
 	GetModulePath(repo, version string) (string, error)
+	GetModCacheDir() (string, error)
+}
+
+// ProgressReporter receives progress events as a sync runs
+type ProgressReporter interface {
+	Report(event ProgressEvent)
 }
 
 // BufRepository handles buf.yaml operations
 type BufRepository interface {
-	ParseBufYaml(bufYamlPath string) (*ModuleInfo, error)
+	// ParseBufModules returns every module declared in buf.yaml, in order.
+	// A buf v1 file yields a single module with an empty Path.
+	ParseBufModules(bufYamlPath string) ([]ModuleInfo, error)
+	// ParseDependencies returns the dependencies declared in buf.yaml and
+	// the buf.lock next to it that can be downloaded as Go modules
+	ParseDependencies(bufYamlPath string) ([]Repository, error)
+	// Generate runs `buf generate` in workdir
+	Generate(ctx context.Context, workdir string) error
+	// Lint runs `buf lint` on path, logging each issue
+	Lint(ctx context.Context, path string) error
+	// WriteBufYaml writes a v2 buf.yaml declaring a single module at
+	// modulePath, replacing any existing file
+	WriteBufYaml(bufYamlPath, modulePath string) error
+}
+
+// ShellRunner executes user-supplied shell commands
+type ShellRunner interface {
+	// Run executes command with stdin as its input and returns its stdout.
+	// env entries use the KEY=VALUE form and extend the current environment.
+	Run(ctx context.Context, command string, env []string, stdin []byte) ([]byte, error)
+	// Stream executes command, logging each line of its stdout and stderr
+	// prefixed with label as it is produced
+	Stream(ctx context.Context, label, command string, env []string) error
+}
+
+// CommandRunner executes external programs such as the go command
+type CommandRunner interface {
+	// Run executes name with args and returns its stdout
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+	// RunWithEnv is Run with env, in the KEY=VALUE form, extending the
+	// current environment
+	RunWithEnv(ctx context.Context, env []string, name string, args ...string) ([]byte, error)
 }
 
 // ProtoSyncService defines the main service interface
 type ProtoSyncService interface {
 	Sync(ctx context.Context, config *SyncConfig) ([]SyncResult, error)
+	// RunBeforeSync runs the --before-sync hook, or logs it in a dry run
+	RunBeforeSync(ctx context.Context, config *SyncConfig) error
+	Diff(ctx context.Context, config *SyncConfig) ([]FileDiff, error)
+	// Validate downloads every repository and checks the syntax of its
+	// source protos without touching the targets
+	Validate(ctx context.Context, config *SyncConfig) ([]FileValidation, error)
+	// CheckUpdates compares the pinned version of every repository with
+	// the newest one, without downloading anything
+	CheckUpdates(ctx context.Context, config *SyncConfig) ([]UpdateCheck, error)
+	// VerifyManifest compares the target files listed in a --manifest file
+	// with their recorded SHA-256 and returns those that drifted
+	VerifyManifest(ctx context.Context, manifestPath string) ([]ManifestDrift, error)
+	// Rollback restores the most recent backup of every target
+	Rollback(ctx context.Context, config *SyncConfig) ([]RollbackResult, error)
+	// Clean deletes every .proto file below the targets, or lists them in
+	// a dry run, and returns the files
+	Clean(ctx context.Context, config *SyncConfig) ([]ProtoFile, error)
 	ListVersions(ctx context.Context, repositories []Repository) (map[string][]string, error)
+	// DetectRepositories returns the protobuf libraries listed in the go.mod
+	// at config.GoModPath, or the buf dependencies when config.SourceOfTruth
+	// is SourceOfTruthBuf
+	DetectRepositories(config *SyncConfig) ([]Repository, error)
 	ValidateConfig(config *SyncConfig) error
+	// Init writes a starter proto-sync.yaml and buf.yaml and adds the
+	// // Protobuf libraries marker to go.mod
+	Init(ctx context.Context, opts *InitOptions) ([]InitResult, error)
+	ModuleCacheDir() (string, error)
+	CacheInfo(cacheDir string) (*CacheInfo, error)
+	CleanCache(cacheDir string) error
 }
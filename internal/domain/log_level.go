@@ -0,0 +1,38 @@
+package domain
+
+import "fmt"
+
+// LogLevel is the minimum severity a logger emits
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	// LogLevelSilent suppresses all output
+	LogLevelSilent
+)
+
+var logLevelNames = map[string]LogLevel{
+	"debug":  LogLevelDebug,
+	"info":   LogLevelInfo,
+	"warn":   LogLevelWarn,
+	"error":  LogLevelError,
+	"silent": LogLevelSilent,
+}
+
+// ParseLogLevel parses debug, info, warn, error or silent
+func ParseLogLevel(name string) (LogLevel, error) {
+	level, ok := logLevelNames[name]
+	if !ok {
+		return LogLevelInfo, fmt.Errorf("invalid log level %q: must be debug, info, warn, error or silent", name)
+	}
+	return level, nil
+}
+
+// Enables reports whether a message of severity msgLevel passes the
+// threshold l. Success messages have info severity.
+func (l LogLevel) Enables(msgLevel LogLevel) bool {
+	return msgLevel >= l && l != LogLevelSilent
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLevelEnables(t *testing.T) {
+	warn, err := ParseLogLevel("warn")
+	require.NoError(t, err)
+	assert.False(t, warn.Enables(LogLevelInfo))
+	assert.True(t, warn.Enables(LogLevelWarn))
+	assert.True(t, warn.Enables(LogLevelError))
+
+	assert.True(t, LogLevelDebug.Enables(LogLevelDebug))
+	assert.False(t, LogLevelSilent.Enables(LogLevelError))
+
+	_, err = ParseLogLevel("verbose")
+	assert.Error(t, err)
+}
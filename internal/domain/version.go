@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// IsStableVersion reports whether version is a release tag such as v1.2.3:
+// not a pre-release like v1.3.0-rc.1 and not a pseudo-version like
+// v0.0.0-20230101000000-abcdef123456
+func IsStableVersion(version string) bool {
+	return semver.IsValid(version) && semver.Prerelease(version) == "" && !module.IsPseudoVersion(version)
+}
+
+// LatestStableVersion returns the highest stable version in versions
+func LatestStableVersion(versions []string) (string, bool) {
+	latest := ""
+	for _, version := range versions {
+		if IsStableVersion(version) && (latest == "" || semver.Compare(version, latest) > 0) {
+			latest = version
+		}
+	}
+	return latest, latest != ""
+}
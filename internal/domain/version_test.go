@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStableVersion(t *testing.T) {
+	assert.True(t, IsStableVersion("v1.2.3"))
+	assert.True(t, IsStableVersion("v2.0.0+incompatible"))
+	assert.False(t, IsStableVersion("v1.3.0-rc.1"))
+	assert.False(t, IsStableVersion("v1.3.0-beta"))
+	assert.False(t, IsStableVersion("v0.0.0-20230101000000-abcdef123456"))
+	assert.False(t, IsStableVersion("v1.2.4-0.20230101000000-abcdef123456"))
+	assert.False(t, IsStableVersion("main"))
+}
+
+func TestLatestStableVersion(t *testing.T) {
+	latest, ok := LatestStableVersion([]string{"v1.2.0", "v1.10.0", "v2.0.0-rc.1", "v1.10.1-0.20230101000000-abcdef123456"})
+	assert.True(t, ok)
+	assert.Equal(t, "v1.10.0", latest)
+
+	_, ok = LatestStableVersion([]string{"v2.0.0-rc.1", "main"})
+	assert.False(t, ok)
+}
@@ -1,7 +1,12 @@
 package infrastructure
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/Francouer/proto-sync/internal/domain"
 	"gopkg.in/yaml.v3"
@@ -15,10 +20,28 @@ type BufRepositoryImpl struct {
 // BufConfig represents the structure of buf.yaml
 type BufConfig struct {
 	Version string `yaml:"version"`
+	// Name is the module name of a buf v1 file, which describes a single
+	// module rooted at the buf.yaml directory instead of listing modules
+	Name    string `yaml:"name,omitempty"`
 	Modules []struct {
 		Path string `yaml:"path"`
 		Name string `yaml:"name,omitempty"`
 	} `yaml:"modules"`
+	// Deps lists dependencies as NAME or NAME:REF
+	Deps []string `yaml:"deps,omitempty"`
+}
+
+// BufLock represents the structure of buf.lock. v1 files split each
+// dependency name into remote, owner and repository; v2 files use name.
+type BufLock struct {
+	Version string `yaml:"version"`
+	Deps    []struct {
+		Name       string `yaml:"name,omitempty"`
+		Remote     string `yaml:"remote,omitempty"`
+		Owner      string `yaml:"owner,omitempty"`
+		Repository string `yaml:"repository,omitempty"`
+		Commit     string `yaml:"commit,omitempty"`
+	} `yaml:"deps"`
 }
 
 // NewBufRepository creates a new buf repository
@@ -29,7 +52,7 @@ func NewBufRepository(logger domain.Logger, fileRepo domain.FileRepository) doma
 	}
 }
 
-func (b *BufRepositoryImpl) ParseBufYaml(bufYamlPath string) (*domain.ModuleInfo, error) {
+func (b *BufRepositoryImpl) ParseBufModules(bufYamlPath string) ([]domain.ModuleInfo, error) {
 	if !b.fileRepo.FileExists(bufYamlPath) {
 		return nil, fmt.Errorf("buf.yaml file not found at: %s", bufYamlPath)
 	}
@@ -44,18 +67,213 @@ func (b *BufRepositoryImpl) ParseBufYaml(bufYamlPath string) (*domain.ModuleInfo
 		return nil, fmt.Errorf("failed to parse buf.yaml: %w", err)
 	}
 
-	// Extract the first module path from buf.yaml
 	if len(config.Modules) == 0 {
+		if isBufV1(config.Version) {
+			// v1 files have no module path; callers pick the default
+			return []domain.ModuleInfo{{Name: config.Name}}, nil
+		}
 		return nil, fmt.Errorf("no modules found in %s", bufYamlPath)
 	}
 
-	module := config.Modules[0]
-	if module.Path == "" {
-		return nil, fmt.Errorf("module path is empty in %s", bufYamlPath)
+	modules := make([]domain.ModuleInfo, 0, len(config.Modules))
+	for i, module := range config.Modules {
+		if module.Path == "" {
+			return nil, fmt.Errorf("modules[%d].path is empty in %s", i, bufYamlPath)
+		}
+		modules = append(modules, domain.ModuleInfo{
+			Name: module.Name,
+			Path: module.Path,
+		})
+	}
+
+	return modules, nil
+}
+
+func (b *BufRepositoryImpl) ParseDependencies(bufYamlPath string) ([]domain.Repository, error) {
+	if !b.fileRepo.FileExists(bufYamlPath) {
+		return nil, fmt.Errorf("buf.yaml file not found at: %s", bufYamlPath)
+	}
+
+	data, err := b.fileRepo.ReadFile(bufYamlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read buf.yaml file: %w", err)
+	}
+
+	var config BufConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse buf.yaml: %w", err)
+	}
+
+	// buf.yaml may pin a ref; buf.lock adds the transitive dependencies
+	names := make([]string, 0, len(config.Deps))
+	refs := make(map[string]string)
+	for _, dep := range config.Deps {
+		name, ref := splitBufDependency(dep)
+		if _, seen := refs[name]; !seen {
+			names = append(names, name)
+		}
+		refs[name] = ref
+	}
+
+	lockPath := filepath.Join(filepath.Dir(bufYamlPath), "buf.lock")
+	if b.fileRepo.FileExists(lockPath) {
+		lockNames, err := b.parseBufLock(lockPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range lockNames {
+			if _, seen := refs[name]; !seen {
+				names = append(names, name)
+				refs[name] = ""
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no dependencies found in %s or %s", bufYamlPath, lockPath)
+	}
+
+	var repositories []domain.Repository
+	for _, name := range names {
+		if isBSRModule(name) {
+			// BSR modules aren't Go modules, so go mod download can't fetch them
+			b.logger.Warning("Skipping %s: Buf Schema Registry modules can't be downloaded as Go modules; pass the Go module that ships its protos with --repo", name)
+			continue
+		}
+		if strings.Count(name, "/") < 2 {
+			b.logger.Warning("Skipping %s: not a REMOTE/OWNER/REPOSITORY dependency name", name)
+			continue
+		}
+
+		// Without a ref the newest release is synced, like --repo NAME@latest
+		version := refs[name]
+		if version == "" {
+			version = "latest"
+		}
+		repositories = append(repositories, domain.Repository{
+			Name:    name,
+			Version: version,
+			URL:     fmt.Sprintf("https://%s", name),
+		})
+	}
+
+	return repositories, nil
+}
+
+// parseBufLock returns the dependency names recorded in buf.lock
+func (b *BufRepositoryImpl) parseBufLock(lockPath string) ([]string, error) {
+	data, err := b.fileRepo.ReadFile(lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read buf.lock file: %w", err)
+	}
+
+	var lock BufLock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse buf.lock: %w", err)
+	}
+
+	names := make([]string, 0, len(lock.Deps))
+	for i, dep := range lock.Deps {
+		name := dep.Name
+		if name == "" {
+			name = strings.Join([]string{dep.Remote, dep.Owner, dep.Repository}, "/")
+		}
+		if strings.Trim(name, "/") == "" {
+			return nil, fmt.Errorf("deps[%d] has no name in %s", i, lockPath)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// splitBufDependency splits a buf.yaml dependency such as
+// buf.build/acme/api:v1.2.0 into its name and ref
+func splitBufDependency(dep string) (string, string) {
+	slash := strings.LastIndex(dep, "/")
+	if colon := strings.LastIndex(dep, ":"); colon > slash {
+		return dep[:colon], dep[colon+1:]
+	}
+	return dep, ""
+}
+
+// isBSRModule reports whether name is hosted on the public Buf Schema
+// Registry or a private BSR instance
+func isBSRModule(name string) bool {
+	remote := strings.SplitN(name, "/", 2)[0]
+	return remote == "buf.build" || strings.HasSuffix(remote, ".buf.dev")
+}
+
+// isBufV1 reports whether version is one of the buf v1 schemas
+func isBufV1(version string) bool {
+	return version == "v1" || version == "v1beta1"
+}
+
+// starterBufYaml is the buf.yaml WriteBufYaml creates, with the lint and
+// breaking rules `buf config init` defaults to
+const starterBufYaml = `version: v2
+modules:
+  - path: %s
+lint:
+  use:
+    - STANDARD
+breaking:
+  use:
+    - FILE
+`
+
+func (b *BufRepositoryImpl) WriteBufYaml(bufYamlPath, modulePath string) error {
+	if err := b.fileRepo.CreateDir(filepath.Dir(bufYamlPath)); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", bufYamlPath, err)
+	}
+	if err := b.fileRepo.WriteFile(bufYamlPath, []byte(fmt.Sprintf(starterBufYaml, filepath.ToSlash(modulePath)))); err != nil {
+		return fmt.Errorf("failed to write buf.yaml: %w", err)
+	}
+	return nil
+}
+
+func (b *BufRepositoryImpl) Generate(ctx context.Context, workdir string) error {
+	b.logger.Info("Running buf generate in %s...", workdir)
+
+	cmd := exec.CommandContext(ctx, "buf", "generate")
+	cmd.Dir = workdir
+	if err := runStreaming(b.logger, cmd, "buf"); err != nil {
+		return fmt.Errorf("buf generate failed: %w", err)
+	}
+
+	b.logger.Success("buf generate completed")
+	return nil
+}
+
+// bufLintIssuesExitCode is the exit status of `buf lint` when it found
+// issues, as opposed to failing to run
+const bufLintIssuesExitCode = 100
+
+func (b *BufRepositoryImpl) Lint(ctx context.Context, path string) error {
+	b.logger.Info("Running buf lint on %s...", path)
+
+	output, err := exec.CommandContext(ctx, "buf", "lint", path).CombinedOutput()
+	if err == nil {
+		b.logger.Success("buf lint passed for %s", path)
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != bufLintIssuesExitCode {
+		return fmt.Errorf("failed to run buf lint on %s: %w: %s", path, err, strings.TrimSpace(string(output)))
+	}
+
+	// Issues are reported as file:line:column:message
+	files := make(map[string]bool)
+	issues := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		issues++
+		files[strings.SplitN(line, ":", 2)[0]] = true
+		b.logger.Error("  lint: %s", line)
 	}
 
-	return &domain.ModuleInfo{
-		Name: module.Name,
-		Path: module.Path,
-	}, nil
+	return domain.WithCode(domain.ErrorCodeLintFailed, fmt.Errorf("buf lint found %d issue(s) in %d file(s) under %s", issues, len(files), path))
 }
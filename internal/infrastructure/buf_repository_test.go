@@ -0,0 +1,163 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBufModules(t *testing.T) {
+	repo := NewBufRepository(nopLogger{}, NewFileRepository(nopLogger{}))
+
+	modules, err := repo.ParseBufModules(filepath.Join("testdata", "buf_v2.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ModuleInfo{
+		{Name: "buf.build/example/product-api", Path: "proto/product"},
+		{Path: "proto/user"},
+	}, modules)
+}
+
+func TestParseBufModulesV1(t *testing.T) {
+	repo := NewBufRepository(nopLogger{}, NewFileRepository(nopLogger{}))
+
+	modules, err := repo.ParseBufModules(filepath.Join("testdata", "buf_v1.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ModuleInfo{{Name: "buf.build/example/product-api"}}, modules)
+
+	// v2 files must still list their modules
+	path := filepath.Join(t.TempDir(), "buf.yaml")
+	writeTestFile(t, path, "version: v2\n")
+	_, err = repo.ParseBufModules(path)
+	assert.Error(t, err)
+}
+
+func TestParseBufModulesEmptyPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buf.yaml")
+	writeTestFile(t, path, "version: v2\nmodules:\n  - path: proto\n  - name: buf.build/example/api\n")
+
+	repo := NewBufRepository(nopLogger{}, NewFileRepository(nopLogger{}))
+	_, err := repo.ParseBufModules(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "modules[1].path")
+}
+
+func TestGenerateStreamsOutput(t *testing.T) {
+	fakeBinary(t, "buf", `echo "generating in $(pwd)"; echo "warning: deprecated" >&2`)
+	workdir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	repo := NewBufRepository(NewPlainLogger(&out, domain.LogLevelInfo), NewFileRepository(nopLogger{}))
+	require.NoError(t, repo.Generate(context.Background(), workdir))
+
+	assert.Contains(t, out.String(), "buf: generating in "+workdir)
+	assert.Contains(t, out.String(), "buf: warning: deprecated")
+}
+
+func TestGenerateFailure(t *testing.T) {
+	fakeBinary(t, "buf", `echo "bad plugin" >&2; exit 1`)
+
+	var out bytes.Buffer
+	repo := NewBufRepository(NewPlainLogger(&out, domain.LogLevelInfo), NewFileRepository(nopLogger{}))
+	err := repo.Generate(context.Background(), t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "buf generate failed")
+	assert.Contains(t, out.String(), "buf: bad plugin")
+}
+
+func TestLintReportsIssues(t *testing.T) {
+	fakeBinary(t, "buf", `echo "$2/a.proto:3:1:Package name should be suffixed"
+echo "$2/a.proto:9:3:Field name should be lower_snake_case"
+echo "$2/b.proto:1:1:Files must have a package defined"
+exit 100`)
+
+	var out bytes.Buffer
+	repo := NewBufRepository(NewPlainLogger(&out, domain.LogLevelInfo), NewFileRepository(nopLogger{}))
+	err := repo.Lint(context.Background(), "proto")
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrorCodeLintFailed, domain.CodeOf(err))
+	assert.Contains(t, err.Error(), "3 issue(s) in 2 file(s) under proto")
+	assert.Contains(t, out.String(), "lint: proto/b.proto:1:1:Files must have a package defined")
+}
+
+func TestLintCommandFailure(t *testing.T) {
+	fakeBinary(t, "buf", `echo "no buf.yaml found" >&2; exit 1`)
+
+	repo := NewBufRepository(nopLogger{}, NewFileRepository(nopLogger{}))
+	err := repo.Lint(context.Background(), "proto")
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrorCodeSyncFailed, domain.CodeOf(err))
+	assert.Contains(t, err.Error(), "no buf.yaml found")
+}
+
+func TestParseDependencies(t *testing.T) {
+	dir := t.TempDir()
+	bufYaml := filepath.Join(dir, "buf.yaml")
+	writeTestFile(t, bufYaml, "version: v2\nmodules:\n  - path: proto\ndeps:\n  - github.com/example/orders-protos:v1.2.0\n  - buf.build/googleapis/googleapis\n")
+	writeTestFile(t, filepath.Join(dir, "buf.lock"), `version: v2
+deps:
+  - name: buf.build/googleapis/googleapis
+    commit: 61b203b9a9164be9a834f58c37be6f62
+  - name: github.com/example/users-protos
+    commit: 1f4a9d2c
+`)
+
+	repo := NewBufRepository(nopLogger{}, NewFileRepository(nopLogger{}))
+	repositories, err := repo.ParseDependencies(bufYaml)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Repository{
+		{Name: "github.com/example/orders-protos", Version: "v1.2.0", URL: "https://github.com/example/orders-protos"},
+		{Name: "github.com/example/users-protos", Version: "latest", URL: "https://github.com/example/users-protos"},
+	}, repositories)
+}
+
+func TestParseDependenciesBufLockV1(t *testing.T) {
+	dir := t.TempDir()
+	bufYaml := filepath.Join(dir, "buf.yaml")
+	writeTestFile(t, bufYaml, "version: v1\nname: buf.build/example/product-api\n")
+	writeTestFile(t, filepath.Join(dir, "buf.lock"), `version: v1
+deps:
+  - remote: buf.build
+    owner: envoyproxy
+    repository: protoc-gen-validate
+    commit: 45685e052c7e406b9fbd441fc7a568a5
+  - remote: github.com
+    owner: example
+    repository: orders-protos
+    commit: 0a1b2c3d
+`)
+
+	repo := NewBufRepository(nopLogger{}, NewFileRepository(nopLogger{}))
+	repositories, err := repo.ParseDependencies(bufYaml)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Repository{
+		{Name: "github.com/example/orders-protos", Version: "latest", URL: "https://github.com/example/orders-protos"},
+	}, repositories)
+
+	// Without buf.lock or deps there is nothing to sync
+	writeTestFile(t, filepath.Join(dir, "buf.lock"), "version: v1\n")
+	_, err = repo.ParseDependencies(bufYaml)
+	assert.Error(t, err)
+}
+
+func TestSplitBufDependency(t *testing.T) {
+	for dep, want := range map[string][2]string{
+		"buf.build/acme/api":                {"buf.build/acme/api", ""},
+		"buf.build/acme/api:v1.2.0":         {"buf.build/acme/api", "v1.2.0"},
+		"localhost:8080/acme/api":           {"localhost:8080/acme/api", ""},
+		"github.com/acme/api:v2.0.0-rc.1":   {"github.com/acme/api", "v2.0.0-rc.1"},
+		"buf.example.buf.dev/acme/api:main": {"buf.example.buf.dev/acme/api", "main"},
+	} {
+		name, ref := splitBufDependency(dep)
+		assert.Equal(t, want, [2]string{name, ref}, dep)
+	}
+
+	assert.True(t, isBSRModule("buf.build/acme/api"))
+	assert.True(t, isBSRModule("acme.buf.dev/acme/api"))
+	assert.False(t, isBSRModule("github.com/acme/api"))
+}
@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+type CommandRunnerImpl struct {
+	logger domain.Logger
+}
+
+// NewCommandRunner creates a runner for external tools such as go
+func NewCommandRunner(logger domain.Logger) domain.CommandRunner {
+	return &CommandRunnerImpl{
+		logger: logger,
+	}
+}
+
+// Run executes name with args and returns its stdout. When the command
+// fails the error is an *exec.ExitError carrying stderr, and any stdout
+// written before the failure is returned as well.
+func (r *CommandRunnerImpl) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.RunWithEnv(ctx, nil, name, args...)
+}
+
+// RunWithEnv is Run with extra environment variables. Only args are
+// logged, since env may hold credentials.
+func (r *CommandRunnerImpl) RunWithEnv(ctx context.Context, env []string, name string, args ...string) ([]byte, error) {
+	r.logger.Debug("Running %s %v", name, args)
+	cmd := exec.CommandContext(ctx, name, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd.Output()
+}
@@ -0,0 +1,18 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandRunnerReturnsStderrOnFailure(t *testing.T) {
+	fakeBinary(t, "go", `echo partial; echo "go: bad things" >&2; exit 1`)
+
+	output, err := NewCommandRunner(nopLogger{}).Run(context.Background(), "go", "version")
+	require.Error(t, err)
+	assert.Equal(t, "partial\n", string(output))
+	assert.Equal(t, "go: bad things", commandStderr(err))
+}
@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// defaultTokenUser is sent with tokens given without a user; GitHub expects
+// it for app and fine-grained tokens, and other hosts ignore the name
+const defaultTokenUser = "x-access-token"
+
+// downloadEnv returns the environment `go mod download` of repo needs for
+// the credentials in opts. The token becomes a git http.extraHeader scoped
+// to the module's host, so it reaches no other server and is never part of
+// a command line.
+//
+// Modules needing credentials are private, so they are also added to
+// GOPRIVATE and GONOSUMDB: the public proxy and checksum database can't
+// serve them and would otherwise fail the download.
+func downloadEnv(repo string, opts domain.DownloadOptions) []string {
+	if opts.Netrc == "" && opts.Token == "" {
+		return nil
+	}
+
+	env := []string{
+		"GOPRIVATE=" + appendPattern(os.Getenv("GOPRIVATE"), repo),
+		"GONOSUMDB=" + appendPattern(os.Getenv("GONOSUMDB"), repo),
+	}
+	if opts.Netrc != "" {
+		env = append(env, "NETRC="+opts.Netrc)
+	}
+	if opts.Token == "" {
+		return env
+	}
+
+	user, token, found := strings.Cut(opts.Token, ":")
+	if !found {
+		user, token = defaultTokenUser, opts.Token
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(user + ":" + token))
+	host, _, _ := strings.Cut(repo, "/")
+
+	// Append to config the user already passes through the environment
+	index, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	return append(env,
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", index+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=http.https://%s/.extraheader", index, host),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=Authorization: Basic %s", index, credentials),
+		// Fail instead of waiting for a password when the token is rejected
+		"GIT_TERMINAL_PROMPT=0",
+	)
+}
+
+// appendPattern adds module to the comma-separated pattern list patterns,
+// keeping the patterns already there
+func appendPattern(patterns, module string) string {
+	if patterns == "" {
+		return module
+	}
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern == module {
+			return patterns
+		}
+	}
+	return patterns + "," + module
+}
+
+// redactToken hides the token of opts, and its encoded form, in command
+// output before it reaches an error or the log
+func redactToken(output string, opts domain.DownloadOptions) string {
+	if opts.Token == "" {
+		return output
+	}
+	user, token, found := strings.Cut(opts.Token, ":")
+	if !found {
+		user, token = defaultTokenUser, opts.Token
+	}
+	output = strings.ReplaceAll(output, base64.StdEncoding.EncodeToString([]byte(user+":"+token)), "[REDACTED]")
+	return strings.ReplaceAll(output, token, "[REDACTED]")
+}
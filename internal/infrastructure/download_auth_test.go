@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadEnvKeepsPrivatePatterns(t *testing.T) {
+	t.Setenv("GOPRIVATE", "corp.example.com/*")
+	t.Setenv("GONOSUMDB", "git.example.com/team/api")
+
+	env := downloadEnv("git.example.com/team/api", domain.DownloadOptions{Netrc: "/home/ci/.netrc"})
+	assert.Equal(t, []string{
+		"GOPRIVATE=corp.example.com/*,git.example.com/team/api",
+		"GONOSUMDB=git.example.com/team/api",
+		"NETRC=/home/ci/.netrc",
+	}, env)
+
+	// Public modules downloaded without credentials keep the defaults
+	assert.Empty(t, downloadEnv("github.com/example/api", domain.DownloadOptions{}))
+}
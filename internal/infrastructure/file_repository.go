@@ -1,23 +1,37 @@
 package infrastructure
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/Francouer/proto-sync/internal/domain"
 )
 
 type FileRepositoryImpl struct {
 	logger domain.Logger
+	// rename is os.Rename, replaceable to simulate moves across filesystems
+	rename func(oldPath, newPath string) error
+
+	mu          sync.Mutex
+	createdDirs map[string]struct{}
 }
 
 // NewFileRepository creates a new file repository
 func NewFileRepository(logger domain.Logger) domain.FileRepository {
 	return &FileRepositoryImpl{
-		logger: logger,
+		logger:      logger,
+		rename:      os.Rename,
+		createdDirs: make(map[string]struct{}),
 	}
 }
 
@@ -37,22 +51,80 @@ func (f *FileRepositoryImpl) CopyFile(src, dst string) error {
 	defer sourceFile.Close()
 
 	// Create destination directory if it doesn't exist
-	if err := f.CreateDir(filepath.Dir(dst)); err != nil {
+	dstDir := filepath.Dir(dst)
+	if err := f.ensureDir(dstDir); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
 	destFile, err := os.Create(dst)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The directory was removed after we cached it, create it again
+		f.forgetDir(dstDir)
+		if err := f.ensureDir(dstDir); err != nil {
+			return fmt.Errorf("failed to create destination directory: %w", err)
+		}
+		destFile, err = os.Create(dst)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create destination file %s: %w", dst, err)
 	}
 	defer destFile.Close()
 
+	// Copying *os.File to *os.File lets the kernel move the bytes
+	// (copy_file_range/sendfile) without a userspace buffer
 	_, err = io.Copy(destFile, sourceFile)
 	if err != nil {
 		return fmt.Errorf("failed to copy file from %s to %s: %w", src, dst, err)
 	}
 
-	return destFile.Sync()
+	if err := destFile.Sync(); err != nil {
+		return err
+	}
+
+	// Keep the source mtime so make-style incremental builds only rebuild
+	// protos whose content actually moved forward
+	info, err := sourceFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat source file %s: %w", src, err)
+	}
+	if err := os.Chtimes(dst, info.ModTime(), info.ModTime()); err != nil {
+		return fmt.Errorf("failed to preserve modification time of %s: %w", dst, err)
+	}
+
+	return nil
+}
+
+// VerifyCopy checks that dst is byte-identical to src by comparing their
+// SHA-256 hashes
+func (f *FileRepositoryImpl) VerifyCopy(src, dst string) error {
+	srcHash, err := f.hashFile(src)
+	if err != nil {
+		return err
+	}
+	dstHash, err := f.hashFile(dst)
+	if err != nil {
+		return err
+	}
+
+	if srcHash != dstHash {
+		return fmt.Errorf("copy verification failed for %s: source sha256 %s, destination sha256 %s", dst, srcHash, dstHash)
+	}
+	return nil
+}
+
+// hashFile returns the hex-encoded SHA-256 of the file at path
+func (f *FileRepositoryImpl) hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s for verification: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 func (f *FileRepositoryImpl) CreateDir(path string) error {
@@ -62,26 +134,62 @@ func (f *FileRepositoryImpl) CreateDir(path string) error {
 	return os.MkdirAll(path, 0o755)
 }
 
+// ensureDir creates path once per repository instance, so copying many files
+// into the same directory only touches the filesystem for the first one
+func (f *FileRepositoryImpl) ensureDir(path string) error {
+	f.mu.Lock()
+	_, ok := f.createdDirs[path]
+	f.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	if err := f.CreateDir(path); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.createdDirs[path] = struct{}{}
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *FileRepositoryImpl) forgetDir(path string) {
+	f.mu.Lock()
+	delete(f.createdDirs, path)
+	f.mu.Unlock()
+}
+
 func (f *FileRepositoryImpl) FileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
 
-func (f *FileRepositoryImpl) ListFiles(dirPath string, pattern string) ([]domain.ProtoFile, error) {
-	var files []domain.ProtoFile
+func (f *FileRepositoryImpl) ModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
 
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+func (f *FileRepositoryImpl) IsDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
 
-		if info.IsDir() {
-			return nil
-		}
+func (f *FileRepositoryImpl) ListFiles(dirPath string, pattern string, opts domain.ListOptions) ([]domain.ProtoFile, error) {
+	var files []domain.ProtoFile
 
-		// Check if file matches pattern
+	err := f.walkFiles(dirPath, opts, func(path string, info os.FileInfo) error {
+		// Check if file matches pattern; patterns with a slash or `**` are
+		// matched against the path relative to dirPath
 		if pattern != "" {
-			matched, err := filepath.Match(pattern, info.Name())
+			rel, err := filepath.Rel(dirPath, path)
+			if err != nil {
+				return err
+			}
+			matched, err := domain.MatchGlob(pattern, filepath.ToSlash(rel))
 			if err != nil {
 				return err
 			}
@@ -109,15 +217,259 @@ func (f *FileRepositoryImpl) ListFiles(dirPath string, pattern string) ([]domain
 	return files, err
 }
 
-func (f *FileRepositoryImpl) MakeWritable(path string) error {
-	info, err := os.Stat(path)
+// walkFiles calls visit for every non-directory below root in lexical
+// order. With FollowSymlinks, symlinked files are reported with the info of
+// their target and symlinked directories are walked as if they were in
+// place; a link leading back into a directory being walked is an error.
+func (f *FileRepositoryImpl) walkFiles(root string, opts domain.ListOptions, visit func(path string, info os.FileInfo) error) error {
+	if !opts.FollowSymlinks {
+		return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return visit(path, info)
+		})
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
 	if err != nil {
 		return err
 	}
+	return f.walkFollowing(realRoot, root, nil, visit)
+}
+
+// walkFollowing walks the real directory dir, reporting paths below logical
+// instead. jumps holds the real parent directories of the symlinks followed
+// to get here, used to detect cycles.
+func (f *FileRepositoryImpl) walkFollowing(dir, logical string, jumps []string, visit func(path string, info os.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		logicalPath := filepath.Join(logical, rel)
+
+		if d.Type()&fs.ModeSymlink == 0 {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return visit(logicalPath, info)
+		}
 
-	// Add write permission to user
-	mode := info.Mode()
-	mode |= 0o200 // Add write permission for owner
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			f.logger.Warning("Skipping broken symlink %s: %v", logicalPath, err)
+			return nil
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			f.logger.Debug("Following symlink %s -> %s", logicalPath, resolved)
+			return visit(logicalPath, info)
+		}
+
+		jumps := append(jumps[:len(jumps):len(jumps)], filepath.Dir(path))
+		for _, jump := range jumps {
+			if isWithinDir(resolved, jump) {
+				return fmt.Errorf("symlink cycle: %s points to %s, which contains it", logicalPath, resolved)
+			}
+		}
 
-	return os.Chmod(path, mode)
+		f.logger.Info("Following symlinked directory %s -> %s", logicalPath, resolved)
+		return f.walkFollowing(resolved, logicalPath, jumps, visit)
+	})
+}
+
+// isWithinDir reports whether path is dir itself or located below it
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// MakeWritable lets the owner write path: it adds the owner write bit on
+// Unix and clears the read-only attribute on Windows
+func (f *FileRepositoryImpl) MakeWritable(path string) error {
+	if err := makeWritable(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return fmt.Errorf("%w (%s)", err, makeWritableHint)
+	}
+	return nil
+}
+
+func (f *FileRepositoryImpl) DeleteFile(path string) error {
+	// Synced protos are read-only; removing them must not depend on the
+	// platform ignoring file permissions
+	if err := f.MakeWritable(path); err != nil {
+		return fmt.Errorf("failed to make %s writable: %w", path, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+	return nil
+}
+
+// ResolvePath returns the absolute path with all symlinks evaluated
+func (f *FileRepositoryImpl) ResolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path for %s: %w", path, err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve symlinks for %s: %w", path, err)
+	}
+
+	return resolved, nil
+}
+
+// DirSize returns the total size in bytes and the number of regular files
+// below path
+func (f *FileRepositoryImpl) DirSize(path string) (int64, int, error) {
+	var size int64
+	var count int
+
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+			count++
+		}
+		return nil
+	})
+
+	return size, count, err
+}
+
+func (f *FileRepositoryImpl) RemoveAll(path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+
+	// Anything cached below path is gone as well
+	f.forgetTree(path)
+	return nil
+}
+
+// forgetTree drops path and every directory below it from the cache
+func (f *FileRepositoryImpl) forgetTree(path string) {
+	f.mu.Lock()
+	for dir := range f.createdDirs {
+		if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+			delete(f.createdDirs, dir)
+		}
+	}
+	f.mu.Unlock()
+}
+
+func (f *FileRepositoryImpl) TempDir(dir, prefix string) (string, error) {
+	path, err := os.MkdirTemp(dir, prefix+"*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory in %s: %w", dir, err)
+	}
+	// MkdirTemp only grants the owner access; the directory may become a
+	// target that other users read
+	if err := os.Chmod(path, 0o755); err != nil {
+		return "", fmt.Errorf("failed to set permissions of %s: %w", path, err)
+	}
+	return path, nil
+}
+
+func (f *FileRepositoryImpl) CopyDir(src, dst string) error {
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			return os.Chmod(target, info.Mode().Perm()|0o200)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+
+		if err := f.CopyFile(path, target); err != nil {
+			return err
+		}
+		return os.Chmod(target, info.Mode().Perm())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
+
+func (f *FileRepositoryImpl) MoveDir(src, dst string) error {
+	if !f.IsDir(src) {
+		return fmt.Errorf("failed to move %s: not a directory", src)
+	}
+	// os.Rename replaces an empty directory on Unix but not on Windows;
+	// removing it first behaves the same everywhere
+	if entries, err := os.ReadDir(dst); err == nil {
+		if len(entries) > 0 {
+			return fmt.Errorf("failed to move %s to %s: destination is not empty", src, dst)
+		}
+		if err := os.Remove(dst); err != nil {
+			return fmt.Errorf("failed to replace empty directory %s: %w", dst, err)
+		}
+	} else if f.FileExists(dst) {
+		return fmt.Errorf("failed to move %s to %s: destination is not a directory", src, dst)
+	}
+
+	// Directories cached below either path no longer match the disk
+	defer f.forgetTree(src)
+	defer f.forgetTree(dst)
+
+	err := f.rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		if err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", src, dst, err)
+		}
+		return nil
+	}
+
+	// rename(2) can't move between filesystems; copy, then remove src
+	// once the copy is complete
+	f.logger.Debug("%s and %s are on different filesystems, copying", src, dst)
+	if err := f.CopyDir(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		return fmt.Errorf("failed to move %s to %s: %w", src, dst, err)
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return fmt.Errorf("failed to remove %s after copying it to %s: %w", src, dst, err)
+	}
+	return nil
 }
@@ -0,0 +1,346 @@
+package infrastructure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopLogger discards all log output in tests
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, args ...interface{})    {}
+func (nopLogger) Success(msg string, args ...interface{}) {}
+func (nopLogger) Warning(msg string, args ...interface{}) {}
+func (nopLogger) Error(msg string, args ...interface{})   {}
+func (nopLogger) Debug(msg string, args ...interface{})   {}
+func (nopLogger) Plain(msg string, args ...interface{})   {}
+
+func writeTestFile(t testing.TB, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src", "a.proto")
+	dst := filepath.Join(dir, "dst", "nested", "a.proto")
+	writeTestFile(t, src, "syntax = \"proto3\";\n")
+
+	repo := NewFileRepository(nopLogger{})
+	require.NoError(t, repo.CopyFile(src, dst))
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "syntax = \"proto3\";\n", string(data))
+}
+
+func TestCopyFileRecreatesRemovedDir(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.proto")
+	dstDir := filepath.Join(dir, "dst")
+	writeTestFile(t, src, "package a;")
+
+	repo := NewFileRepository(nopLogger{})
+	require.NoError(t, repo.CopyFile(src, filepath.Join(dstDir, "a.proto")))
+	require.NoError(t, os.RemoveAll(dstDir))
+
+	require.NoError(t, repo.CopyFile(src, filepath.Join(dstDir, "a.proto")))
+	assert.FileExists(t, filepath.Join(dstDir, "a.proto"))
+}
+
+// setupCopyBenchmark creates a synthetic source tree of protos spread over a
+// handful of packages, mimicking a large schema repository
+func setupCopyBenchmark(b *testing.B, count int) (string, []string) {
+	b.Helper()
+	srcDir := b.TempDir()
+	content := "syntax = \"proto3\";\n\nmessage Example {\n  string id = 1;\n}\n"
+
+	names := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		name := filepath.Join(fmt.Sprintf("pkg%d", i%10), fmt.Sprintf("file_%04d.proto", i))
+		writeTestFile(b, filepath.Join(srcDir, name), content)
+		names = append(names, name)
+	}
+	return srcDir, names
+}
+
+// naiveCopyFile mirrors the previous CopyFile behaviour: a MkdirAll for
+// every file before copying it
+func naiveCopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+func BenchmarkCopyFiles2000(b *testing.B) {
+	srcDir, names := setupCopyBenchmark(b, 2000)
+
+	b.Run("naive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dstDir := b.TempDir()
+			for _, name := range names {
+				if err := naiveCopyFile(filepath.Join(srcDir, name), filepath.Join(dstDir, name)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("cached-dirs", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dstDir := b.TempDir()
+			repo := NewFileRepository(nopLogger{})
+			for _, name := range names {
+				if err := repo.CopyFile(filepath.Join(srcDir, name), filepath.Join(dstDir, name)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func TestListFilesPathPattern(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "root.proto"), "")
+	writeTestFile(t, filepath.Join(dir, "v1", "a.proto"), "")
+	writeTestFile(t, filepath.Join(dir, "v1", "nested", "b.proto"), "")
+	writeTestFile(t, filepath.Join(dir, "v2", "c.proto"), "")
+
+	repo := NewFileRepository(nopLogger{})
+	names := func(pattern string) []string {
+		files, err := repo.ListFiles(dir, pattern, domain.ListOptions{})
+		require.NoError(t, err)
+		var result []string
+		for _, file := range files {
+			rel, err := filepath.Rel(dir, file.Path)
+			require.NoError(t, err)
+			result = append(result, filepath.ToSlash(rel))
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"root.proto", "v1/a.proto", "v1/nested/b.proto", "v2/c.proto"}, names("*.proto"))
+	assert.Equal(t, []string{"v1/a.proto"}, names("v1/*.proto"))
+	assert.Equal(t, []string{"v1/a.proto", "v1/nested/b.proto"}, names("v1/**/*.proto"))
+}
+
+func TestListFilesFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	src, shared := filepath.Join(dir, "src"), filepath.Join(dir, "shared")
+	writeTestFile(t, filepath.Join(src, "root.proto"), "")
+	writeTestFile(t, filepath.Join(shared, "common", "types.proto"), "types")
+	require.NoError(t, os.Symlink(shared, filepath.Join(src, "linked")))
+	require.NoError(t, os.Symlink(filepath.Join(shared, "common", "types.proto"), filepath.Join(src, "alias.proto")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing"), filepath.Join(src, "broken.proto")))
+
+	repo := NewFileRepository(nopLogger{})
+	names := func(opts domain.ListOptions) []string {
+		files, err := repo.ListFiles(src, "*.proto", opts)
+		require.NoError(t, err)
+		var result []string
+		for _, file := range files {
+			rel, err := filepath.Rel(src, file.Path)
+			require.NoError(t, err)
+			result = append(result, filepath.ToSlash(rel))
+		}
+		return result
+	}
+
+	// By default symlinks are listed as they are and directories aren't
+	// entered
+	assert.Equal(t, []string{"alias.proto", "broken.proto", "root.proto"}, names(domain.ListOptions{}))
+	assert.Equal(t, []string{"alias.proto", "linked/common/types.proto", "root.proto"}, names(domain.ListOptions{FollowSymlinks: true}))
+
+	files, err := repo.ListFiles(src, "alias.proto", domain.ListOptions{FollowSymlinks: true})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, int64(len("types")), files[0].Size)
+}
+
+func TestListFilesSymlinkCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "src", "a", "a.proto"), "")
+	require.NoError(t, os.Symlink(filepath.Join(dir, "src"), filepath.Join(dir, "src", "a", "loop")))
+
+	_, err := NewFileRepository(nopLogger{}).ListFiles(filepath.Join(dir, "src"), "*.proto", domain.ListOptions{FollowSymlinks: true})
+	assert.ErrorContains(t, err, "symlink cycle")
+
+	// Two directories linking to each other
+	other := t.TempDir()
+	writeTestFile(t, filepath.Join(other, "x", "x.proto"), "")
+	writeTestFile(t, filepath.Join(other, "y", "y.proto"), "")
+	require.NoError(t, os.Symlink(filepath.Join(other, "y"), filepath.Join(other, "x", "to-y")))
+	require.NoError(t, os.Symlink(filepath.Join(other, "x"), filepath.Join(other, "y", "to-x")))
+
+	_, err = NewFileRepository(nopLogger{}).ListFiles(filepath.Join(other, "x"), "*.proto", domain.ListOptions{FollowSymlinks: true})
+	assert.ErrorContains(t, err, "symlink cycle")
+}
+
+func TestVerifyCopy(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src", "a.proto")
+	dst := filepath.Join(dir, "dst", "a.proto")
+	writeTestFile(t, src, "syntax = \"proto3\";\n")
+
+	repo := NewFileRepository(nopLogger{})
+	require.NoError(t, repo.CopyFile(src, dst))
+	require.NoError(t, repo.VerifyCopy(src, dst))
+
+	// Corrupt the destination
+	corrupted := []byte("syntax = \"proto2\";\n")
+	require.NoError(t, os.WriteFile(dst, corrupted, 0o644))
+
+	err := repo.VerifyCopy(src, dst)
+	require.Error(t, err)
+	srcSum := sha256.Sum256([]byte("syntax = \"proto3\";\n"))
+	dstSum := sha256.Sum256(corrupted)
+	assert.Contains(t, err.Error(), hex.EncodeToString(srcSum[:]))
+	assert.Contains(t, err.Error(), hex.EncodeToString(dstSum[:]))
+}
+
+func TestCopyFilePreservesModTime(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src", "a.proto")
+	dst := filepath.Join(dir, "dst", "a.proto")
+	writeTestFile(t, src, "package a;")
+	writeTestFile(t, dst, "package old;")
+
+	modTime := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	require.NoError(t, os.Chtimes(src, modTime, modTime))
+
+	// Synced files are read-only until proto-sync makes them writable
+	require.NoError(t, os.Chmod(dst, 0o444))
+
+	repo := NewFileRepository(nopLogger{})
+	require.NoError(t, repo.MakeWritable(dst))
+	require.NoError(t, repo.CopyFile(src, dst))
+
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.True(t, modTime.Equal(info.ModTime()), "got %s", info.ModTime())
+
+	got, err := repo.ModTime(dst)
+	require.NoError(t, err)
+	assert.True(t, modTime.Equal(got))
+}
+
+func TestDeleteFileReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "synced.proto")
+	writeTestFile(t, path, "package synced;")
+	require.NoError(t, os.Chmod(path, 0o444))
+
+	repo := NewFileRepository(nopLogger{})
+	require.NoError(t, repo.DeleteFile(path))
+	assert.NoFileExists(t, path)
+
+	assert.Error(t, repo.DeleteFile(path))
+}
+
+func TestCopyDir(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "proto")
+	writeTestFile(t, filepath.Join(src, "a.proto"), "package a;")
+	writeTestFile(t, filepath.Join(src, "nested", "b.proto"), "package b;")
+	require.NoError(t, os.Chmod(filepath.Join(src, "a.proto"), 0o444))
+
+	repo := NewFileRepository(nopLogger{})
+	staging, err := repo.TempDir(dir, ".proto.staging-")
+	require.NoError(t, err)
+	assert.Equal(t, dir, filepath.Dir(staging))
+
+	require.NoError(t, repo.CopyDir(src, staging))
+	info, err := os.Stat(filepath.Join(staging, "a.proto"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o444), info.Mode().Perm())
+	data, err := os.ReadFile(filepath.Join(staging, "nested", "b.proto"))
+	require.NoError(t, err)
+	assert.Equal(t, "package b;", string(data))
+}
+
+func TestMoveDir(t *testing.T) {
+	crossDevice := func(oldPath, newPath string) error {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: syscall.EXDEV}
+	}
+
+	for name, rename := range map[string]func(string, string) error{
+		"same filesystem":    os.Rename,
+		"across filesystems": crossDevice,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "staging")
+			writeTestFile(t, filepath.Join(src, "a.proto"), "package a;")
+			writeTestFile(t, filepath.Join(src, "nested", "b.proto"), "package b;")
+			repo := &FileRepositoryImpl{logger: nopLogger{}, rename: rename, createdDirs: make(map[string]struct{})}
+
+			// An empty destination is replaced
+			dst := filepath.Join(dir, "proto")
+			require.NoError(t, os.Mkdir(dst, 0o755))
+			require.NoError(t, repo.MoveDir(src, dst))
+			assert.NoDirExists(t, src)
+			data, err := os.ReadFile(filepath.Join(dst, "nested", "b.proto"))
+			require.NoError(t, err)
+			assert.Equal(t, "package b;", string(data))
+
+			// A destination with content is left alone
+			other := filepath.Join(dir, "other")
+			writeTestFile(t, filepath.Join(other, "keep.proto"), "package keep;")
+			assert.ErrorContains(t, repo.MoveDir(dst, other), "destination is not empty")
+			assert.FileExists(t, filepath.Join(dst, "a.proto"))
+			assert.FileExists(t, filepath.Join(other, "keep.proto"))
+
+			assert.Error(t, repo.MoveDir(filepath.Join(dir, "missing"), filepath.Join(dir, "moved")))
+		})
+	}
+}
+
+func TestMakeWritable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "synced.proto")
+	writeTestFile(t, path, "package synced;")
+	require.NoError(t, os.Chmod(path, 0o444))
+
+	repo := NewFileRepository(nopLogger{})
+	require.NoError(t, repo.MakeWritable(path))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0o200)
+
+	// Missing files need no hint
+	err = repo.MakeWritable(filepath.Join(dir, "missing.proto"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.NotContains(t, err.Error(), makeWritableHint)
+}
@@ -4,27 +4,55 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Francouer/proto-sync/internal/domain"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	"gopkg.in/yaml.v3"
 )
 
 type GoModRepositoryImpl struct {
-	logger domain.Logger
+	logger     domain.Logger
+	runner     domain.CommandRunner
+	httpClient *http.Client
+
+	// modCache caches GOMODCACHE after the first successful lookup
+	modCacheMu sync.Mutex
+	modCache   string
+}
+
+// moduleDownload is the JSON object printed by `go mod download -json`
+type moduleDownload struct {
+	Path     string `json:"Path"`
+	Version  string `json:"Version"`
+	Error    string `json:"Error"`
+	Dir      string `json:"Dir"`
+	Sum      string `json:"Sum"`
+	GoModSum string `json:"GoModSum"`
 }
 
-// NewGoModRepository creates a new Go module repository
-func NewGoModRepository(logger domain.Logger) domain.GoModRepository {
+// NewGoModRepository creates a new Go module repository running the go
+// command through runner and querying GOPROXY with httpClient. A nil
+// httpClient uses a default client with a 30s timeout; pass one with custom
+// TLS settings for proxies signed by a private CA.
+func NewGoModRepository(logger domain.Logger, runner domain.CommandRunner, httpClient *http.Client) domain.GoModRepository {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: proxyRequestTimeout}
+	}
 	return &GoModRepositoryImpl{
-		logger: logger,
+		logger:     logger,
+		runner:     runner,
+		httpClient: httpClient,
 	}
 }
 
@@ -38,11 +66,32 @@ func (g *GoModRepositoryImpl) ParseProtobufLibraries(goModPath string) (*domain.
 	g.logger.Info("Parsing protobuf libraries from %s...", goModPath)
 
 	var repositories []domain.Repository
+	// positions maps the go.mod module path of each entry to its index, so a
+	// replace overrides a require of the same module
+	positions := make(map[string]int)
+	addRepository := func(modulePath string, repo domain.Repository) {
+		if i, ok := positions[modulePath]; ok {
+			repositories[i] = repo
+		} else {
+			positions[modulePath] = len(repositories)
+			repositories = append(repositories, repo)
+		}
+		if repo.LocalPath != "" {
+			g.logger.Info("Found protobuf library: %s => %s (local)", repo.Name, repo.LocalPath)
+		} else {
+			g.logger.Info("Found protobuf library: %s@%s", repo.Name, repo.Version)
+		}
+	}
+
 	foundComment := false
+	inRequireBlock := false
 	scanner := bufio.NewScanner(file)
 
-	// Regex to match replace directive
+	// Regexes to match replace and require directives, and require block entries
 	replaceRegex := regexp.MustCompile(`^\s*replace\s+([^\s]+)\s+([^\s]+)\s*=>\s*([^\s]+)\s+([^\s]+)`)
+	localReplaceRegex := regexp.MustCompile(`^\s*replace\s+([^\s]+)(?:\s+([^\s]+))?\s*=>\s*([./][^\s]*)\s*$`)
+	requireRegex := regexp.MustCompile(`^\s*require\s+([^\s(]+)\s+([^\s]+)`)
+	requireEntryRegex := regexp.MustCompile(`^([^\s]+)\s+([^\s/]+)`)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -53,32 +102,72 @@ func (g *GoModRepositoryImpl) ParseProtobufLibraries(goModPath string) (*domain.
 			continue
 		}
 
-		// If we found the comment, look for replace directives
+		// Track require blocks, the comment may sit inside one
+		if strings.HasPrefix(line, "require (") || line == "require(" {
+			inRequireBlock = true
+			continue
+		}
+		if inRequireBlock && line == ")" {
+			inRequireBlock = false
+			if foundComment {
+				break
+			}
+			continue
+		}
+
+		// If we found the comment, look for replace and require directives
 		if foundComment {
 			// Stop at empty lines or other comments
 			if line == "" || (strings.HasPrefix(line, "//") && !strings.Contains(strings.ToLower(line), "protobuf")) {
 				break
 			}
 
-			// Parse replace directive
-			matches := replaceRegex.FindStringSubmatch(line)
-			if len(matches) == 5 {
-				remoteRepo := matches[3]
-				remoteVersion := matches[4]
-
-				// Extract just the repository path (remove github.com/ prefix if present)
-				if strings.HasPrefix(remoteRepo, "github.com/") {
-					remoteRepo = strings.TrimPrefix(remoteRepo, "github.com/")
+			// Parse filesystem replace directive; like the go command,
+			// relative directories are resolved against go.mod
+			if matches := localReplaceRegex.FindStringSubmatch(line); len(matches) == 4 {
+				localPath := matches[3]
+				if !filepath.IsAbs(localPath) {
+					localPath = filepath.Join(filepath.Dir(goModPath), localPath)
 				}
-
-				repo := domain.Repository{
-					Name:    fmt.Sprintf("github.com/%s", remoteRepo),
-					Version: remoteVersion,
-					URL:     fmt.Sprintf("https://github.com/%s", remoteRepo),
+				modulePath := qualifyModulePath(matches[1])
+				version := matches[2]
+				if i, ok := positions[matches[1]]; ok && version == "" {
+					version = repositories[i].Version
 				}
+				addRepository(matches[1], domain.Repository{
+					Name:      modulePath,
+					Version:   version,
+					URL:       fmt.Sprintf("https://%s", modulePath),
+					LocalPath: localPath,
+				})
+				continue
+			}
+
+			// Parse replace directive
+			if matches := replaceRegex.FindStringSubmatch(line); len(matches) == 5 {
+				modulePath := qualifyModulePath(matches[3])
+				addRepository(matches[1], domain.Repository{
+					Name:    modulePath,
+					Version: matches[4],
+					URL:     fmt.Sprintf("https://%s", modulePath),
+				})
+				continue
+			}
 
-				repositories = append(repositories, repo)
-				g.logger.Info("Found protobuf library: %s@%s", repo.Name, repo.Version)
+			// Parse require directive or require block entry
+			var matches []string
+			if inRequireBlock {
+				matches = requireEntryRegex.FindStringSubmatch(line)
+			} else {
+				matches = requireRegex.FindStringSubmatch(line)
+			}
+			if len(matches) == 3 {
+				modulePath := qualifyModulePath(matches[1])
+				addRepository(matches[1], domain.Repository{
+					Name:    modulePath,
+					Version: matches[2],
+					URL:     fmt.Sprintf("https://%s", modulePath),
+				})
 			}
 		}
 	}
@@ -100,37 +189,96 @@ func (g *GoModRepositoryImpl) ParseProtobufLibraries(goModPath string) (*domain.
 	}, nil
 }
 
-func (g *GoModRepositoryImpl) GetLatestVersion(repo string) (string, error) {
-	g.logger.Info("Checking latest version for %s...", repo)
+// defaultModuleHost is assumed for replace targets without a host, e.g.
+// `org/api`
+const defaultModuleHost = "github.com"
+
+// qualifyModulePath returns path with a host. Like the go command, a first
+// path element containing a dot is treated as the host (gitlab.com,
+// bitbucket.org, git.company.internal, ...).
+func qualifyModulePath(path string) string {
+	host, _, _ := strings.Cut(path, "/")
+	if strings.Contains(host, ".") {
+		return path
+	}
+	return defaultModuleHost + "/" + path
+}
 
-	// Try using go list first
-	cmd := exec.Command("go", "list", "-m", "-versions", repo)
-	output, err := cmd.Output()
-	if err == nil {
-		versions := strings.Fields(string(output))
-		if len(versions) > 1 {
-			return versions[len(versions)-1], nil
+// ParseVersionsFile reads a `module: version` map used to pin proto library
+// versions independently of go.mod
+func (g *GoModRepositoryImpl) ParseVersionsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versions file %s: %w", path, err)
+	}
+
+	versions := make(map[string]string)
+	if err := yaml.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("failed to parse versions file %s: %w", path, err)
+	}
+
+	for module, version := range versions {
+		if version == "" {
+			return nil, fmt.Errorf("empty version for %s in %s", module, path)
 		}
 	}
 
-	// Fallback: try to get latest from go proxy
-	encodedRepo := strings.ReplaceAll(repo, "/", "%2F")
-	proxyURL := fmt.Sprintf("https://proxy.golang.org/%s/@latest", encodedRepo)
+	return versions, nil
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(proxyURL)
+func (g *GoModRepositoryImpl) ParseGoSum(goSumPath string) (map[string]string, error) {
+	file, err := os.Open(goSumPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch latest version for %s: %w", repo, err)
+		return nil, fmt.Errorf("failed to open go.sum file: %w", err)
+	}
+	defer file.Close()
+
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed go.sum line %d in %s", lineNum, goSumPath)
+		}
+		// Lines for a module's go.mod alone don't cover its files
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+"@"+fields[1]] = fields[2]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read go.sum file: %w", err)
+	}
+
+	return sums, nil
+}
+
+func (g *GoModRepositoryImpl) HashModuleDir(dir, repo, version string) (string, error) {
+	hash, err := dirhash.HashDir(dir, repo+"@"+version, dirhash.Hash1)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s@%s: %w", repo, version, err)
 	}
-	defer resp.Body.Close()
+	return hash, nil
+}
+
+func (g *GoModRepositoryImpl) GetLatestVersion(repo string) (string, error) {
+	g.logger.Info("Checking latest version for %s...", repo)
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch latest version for %s: HTTP %d", repo, resp.StatusCode)
+	// Try using go list first
+	versions, listErr := g.goListVersions(repo)
+	if len(versions) > 0 {
+		return versions[len(versions)-1], nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	// Fallback: ask the GOPROXY proxies
+	body, err := g.fetchVersionInfo(repo, "@latest", listErr)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", fmt.Errorf("failed to fetch latest version for %s: %w", repo, err)
 	}
 
 	var versionInfo struct {
@@ -148,40 +296,43 @@ func (g *GoModRepositoryImpl) GetLatestVersion(repo string) (string, error) {
 	return versionInfo.Version, nil
 }
 
-func (g *GoModRepositoryImpl) ListVersions(repo string) ([]string, error) {
-	g.logger.Info("Listing available versions for %s...", repo)
-
-	// Try using go list first
-	cmd := exec.Command("go", "list", "-m", "-versions", repo)
-	output, err := cmd.Output()
-	if err == nil {
-		versions := strings.Fields(string(output))
-		if len(versions) > 1 {
-			return versions[1:], nil // Skip the first element which is the module name
+// goListVersions returns the tagged versions `go list -m -versions` reports
+// for repo; go resolves them through GOPROXY, version control or the module
+// cache as configured
+func (g *GoModRepositoryImpl) goListVersions(repo string) ([]string, error) {
+	output, err := g.runner.Run(context.Background(), "go", "list", "-m", "-versions", repo)
+	if err != nil {
+		g.logger.Debug("go list -m -versions %s failed: %v", repo, err)
+		if stderr := commandStderr(err); stderr != "" {
+			return nil, fmt.Errorf("go list -m -versions %s: %s", repo, stderr)
 		}
+		return nil, fmt.Errorf("go list -m -versions %s: %w", repo, err)
 	}
 
-	// Fallback: try to get from go proxy
-	encodedRepo := strings.ReplaceAll(repo, "/", "%2F")
-	proxyURL := fmt.Sprintf("https://proxy.golang.org/%s/@v/list", encodedRepo)
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(proxyURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch versions for %s: %w", repo, err)
+	// The first field is the module path itself
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		return nil, nil
 	}
-	defer resp.Body.Close()
+	return fields[1:], nil
+}
+
+func (g *GoModRepositoryImpl) ListVersions(repo string) ([]string, error) {
+	g.logger.Info("Listing available versions for %s...", repo)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch versions for %s: HTTP %d", repo, resp.StatusCode)
+	// Try using go list first
+	versions, listErr := g.goListVersions(repo)
+	if len(versions) > 0 {
+		return versions, nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	// Fallback: ask the GOPROXY proxies
+	body, err := g.fetchVersionInfo(repo, "@v/list", listErr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to fetch versions for %s: %w", repo, err)
 	}
 
-	versions := strings.Split(strings.TrimSpace(string(body)), "\n")
+	versions = strings.Split(strings.TrimSpace(string(body)), "\n")
 	if len(versions) == 1 && versions[0] == "" {
 		return []string{}, nil
 	}
@@ -189,23 +340,112 @@ func (g *GoModRepositoryImpl) ListVersions(repo string) ([]string, error) {
 	return versions, nil
 }
 
-func (g *GoModRepositoryImpl) DownloadModule(ctx context.Context, repo, version string) error {
+func (g *GoModRepositoryImpl) DownloadModule(ctx context.Context, repo, version string, opts domain.DownloadOptions) (string, error) {
 	moduleWithVersion := fmt.Sprintf("%s@%s", repo, version)
 	g.logger.Info("Downloading %s...", moduleWithVersion)
 
-	cmd := exec.CommandContext(ctx, "go", "mod", "download", moduleWithVersion)
-	output, err := cmd.CombinedOutput()
+	delay := opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		info, err := g.downloadOnce(ctx, moduleWithVersion, downloadEnv(repo, opts), opts)
+		if err == nil {
+			// go may resolve branch names or queries to a different concrete version
+			if info.Version != "" && info.Version != version {
+				g.logger.Info("Resolved %s to %s@%s", moduleWithVersion, repo, info.Version)
+			}
+			return info.Dir, nil
+		}
+
+		if isPermanentDownloadError(err) {
+			return "", domain.WithCode(domain.ErrorCodeModuleNotFound, err)
+		}
+		if attempt >= opts.Retries || ctx.Err() != nil {
+			return "", err
+		}
+
+		g.logger.Warning("Download of %s failed (attempt %d of %d), retrying in %s: %v", moduleWithVersion, attempt+1, opts.Retries+1, delay, err)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("download of %s cancelled: %w", moduleWithVersion, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// downloadOnce runs `go mod download -json` a single time
+func (g *GoModRepositoryImpl) downloadOnce(ctx context.Context, moduleWithVersion string, env []string, opts domain.DownloadOptions) (moduleDownload, error) {
+	output, err := g.runner.RunWithEnv(ctx, env, "go", "mod", "download", "-json", moduleWithVersion)
+
+	var info moduleDownload
+	if jsonErr := json.Unmarshal(output, &info); jsonErr != nil && err == nil {
+		return info, fmt.Errorf("failed to parse download result for %s: %w", moduleWithVersion, jsonErr)
+	}
+
 	if err != nil {
-		return fmt.Errorf("failed to download %s: %w\nOutput: %s", moduleWithVersion, err, string(output))
+		if info.Error != "" {
+			return info, fmt.Errorf("failed to download %s: %s", moduleWithVersion, redactToken(info.Error, opts))
+		}
+		return info, fmt.Errorf("failed to download %s: %w\nOutput: %s", moduleWithVersion, err, redactToken(commandStderr(err), opts))
+	}
+
+	if info.Dir == "" {
+		return info, fmt.Errorf("go mod download did not report a directory for %s", moduleWithVersion)
 	}
 
-	return nil
+	return info, nil
+}
+
+// permanentDownloadErrors are substrings of go command errors that retrying
+// cannot fix
+var permanentDownloadErrors = []string{
+	"not found",
+	"unknown revision",
+	"invalid version",
+	"no matching versions",
+	"malformed module path",
+}
+
+func isPermanentDownloadError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, marker := range permanentDownloadErrors {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 func (g *GoModRepositoryImpl) GetModulePath(repo, version string) (string, error) {
-	// Get GOMODCACHE
-	cmd := exec.Command("go", "env", "GOMODCACHE")
-	output, err := cmd.Output()
+	gomodcache, err := g.GetModCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	// The module cache stores upper-case letters as "!" plus lower case
+	escapedPath, err := module.EscapePath(repo)
+	if err != nil {
+		return "", fmt.Errorf("invalid module path %s: %w", repo, err)
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("invalid module version %s: %w", version, err)
+	}
+
+	modulePath := filepath.Join(gomodcache, filepath.FromSlash(escapedPath)+"@"+escapedVersion)
+
+	return modulePath, nil
+}
+
+// GetModCacheDir returns GOMODCACHE, running `go env` only on the first
+// successful call
+func (g *GoModRepositoryImpl) GetModCacheDir() (string, error) {
+	g.modCacheMu.Lock()
+	defer g.modCacheMu.Unlock()
+	if g.modCache != "" {
+		return g.modCache, nil
+	}
+
+	output, err := g.runner.Run(context.Background(), "go", "env", "GOMODCACHE")
 	if err != nil {
 		return "", fmt.Errorf("failed to get GOMODCACHE: %w", err)
 	}
@@ -215,8 +455,16 @@ func (g *GoModRepositoryImpl) GetModulePath(repo, version string) (string, error
 		return "", fmt.Errorf("GOMODCACHE is empty")
 	}
 
-	moduleWithVersion := fmt.Sprintf("%s@%s", repo, version)
-	modulePath := filepath.Join(gomodcache, moduleWithVersion)
+	g.logger.Debug("Using GOMODCACHE: %s", gomodcache)
+	g.modCache = gomodcache
+	return gomodcache, nil
+}
 
-	return modulePath, nil
+// commandStderr extracts stderr captured by exec.Cmd.Output
+func commandStderr(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return strings.TrimSpace(string(exitErr.Stderr))
+	}
+	return ""
 }
@@ -0,0 +1,134 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// defaultGoProxy is the go command's default GOPROXY
+const defaultGoProxy = "https://proxy.golang.org,direct"
+
+// proxyRequestTimeout bounds each request of the default HTTP client
+const proxyRequestTimeout = 30 * time.Second
+
+// proxyEntry is one element of a GOPROXY list
+type proxyEntry struct {
+	url string
+	// fallbackOnError is set when the entry is followed by "|", which tries
+	// the next entry after any error; "," only moves on after 404 and 410
+	fallbackOnError bool
+}
+
+// parseGoProxy splits a GOPROXY value into its entries
+func parseGoProxy(value string) []proxyEntry {
+	if strings.TrimSpace(value) == "" {
+		value = defaultGoProxy
+	}
+
+	var entries []proxyEntry
+	for value != "" {
+		end := strings.IndexAny(value, ",|")
+		entry := proxyEntry{url: value}
+		rest := ""
+		if end >= 0 {
+			entry = proxyEntry{url: value[:end], fallbackOnError: value[end] == '|'}
+			rest = value[end+1:]
+		}
+		if entry.url = strings.TrimRight(strings.TrimSpace(entry.url), "/"); entry.url != "" {
+			entries = append(entries, entry)
+		}
+		value = rest
+	}
+	return entries
+}
+
+// httpProxies returns the entries that can be queried over HTTP: "direct"
+// fetches from version control and "off" forbids anything after it
+func httpProxies(entries []proxyEntry) []proxyEntry {
+	var proxies []proxyEntry
+	for _, entry := range entries {
+		if entry.url == "off" {
+			break
+		}
+		if entry.url != "direct" {
+			proxies = append(proxies, entry)
+		}
+	}
+	return proxies
+}
+
+// goProxySetting returns the effective GOPROXY, including values set with
+// `go env -w`, falling back to the environment when go can't be run
+func (g *GoModRepositoryImpl) goProxySetting() string {
+	output, err := g.runner.Run(context.Background(), "go", "env", "GOPROXY")
+	if value := strings.TrimSpace(string(output)); err == nil && value != "" {
+		return value
+	}
+	return os.Getenv("GOPROXY")
+}
+
+// fetchVersionInfo asks the GOPROXY proxies for path (e.g. "@v/list") of
+// repo after `go list` found nothing. When GOPROXY allows no HTTP proxy, as
+// with off or direct, it fails with listErr instead of trying.
+func (g *GoModRepositoryImpl) fetchVersionInfo(repo, path string, listErr error) ([]byte, error) {
+	setting := g.goProxySetting()
+	proxies := httpProxies(parseGoProxy(setting))
+	if len(proxies) == 0 {
+		if listErr != nil {
+			return nil, fmt.Errorf("GOPROXY=%s and go list returned no versions: %w", setting, listErr)
+		}
+		return nil, fmt.Errorf("GOPROXY=%s and go list returned no versions", setting)
+	}
+	return g.fetchFromProxy(repo, path, proxies)
+}
+
+// fetchFromProxy requests path for repo from each proxy in turn, following
+// the go command's fallback rules
+func (g *GoModRepositoryImpl) fetchFromProxy(repo, path string, proxies []proxyEntry) ([]byte, error) {
+	escapedRepo, err := module.EscapePath(repo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module path %s: %w", repo, err)
+	}
+
+	var failures []string
+	for _, proxy := range proxies {
+		body, status, err := g.proxyGet(proxy.url + "/" + escapedRepo + "/" + path)
+		if err == nil {
+			return body, nil
+		}
+		g.logger.Debug("GOPROXY %s failed for %s: %v", proxy.url, repo, err)
+		failures = append(failures, fmt.Sprintf("%s: %v", proxy.url, err))
+
+		if !proxy.fallbackOnError && status != http.StatusNotFound && status != http.StatusGone {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("all proxies failed for %s (%s)", repo, strings.Join(failures, "; "))
+}
+
+// proxyGet fetches url, returning the HTTP status alongside any error
+func (g *GoModRepositoryImpl) proxyGet(url string) ([]byte, int, error) {
+	resp, err := g.httpClient.Get(url)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
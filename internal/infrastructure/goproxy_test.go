@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoProxy(t *testing.T) {
+	assert.Equal(t, []proxyEntry{{url: "https://proxy.golang.org"}, {url: "direct"}}, parseGoProxy(""))
+	assert.Equal(t, []proxyEntry{
+		{url: "https://a.example", fallbackOnError: true},
+		{url: "https://b.example"},
+		{url: "off"},
+	}, parseGoProxy("https://a.example/|https://b.example, off"))
+}
+
+// proxyServer serves body for /github.com/example/api/@v/list and status for
+// everything else
+func proxyServer(t *testing.T, status int, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// failingGoList makes `go list` fail so lookups fall back to GOPROXY
+var failingGoList = &fakeRunner{respond: respondWith("", errors.New("exit status 1"))}
+
+func TestListVersionsFallsThroughOnNotFound(t *testing.T) {
+	missing := proxyServer(t, http.StatusNotFound, "")
+	found := proxyServer(t, http.StatusOK, "v1.0.0\nv1.1.0\n")
+	t.Setenv("GOPROXY", missing.URL+","+found.URL+",direct")
+
+	repo := NewGoModRepository(nopLogger{}, failingGoList, missing.Client())
+	versions, err := repo.ListVersions("github.com/example/api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0"}, versions)
+}
+
+func TestListVersionsCommaStopsOnServerError(t *testing.T) {
+	broken := proxyServer(t, http.StatusInternalServerError, "")
+	found := proxyServer(t, http.StatusOK, "v1.0.0\n")
+
+	t.Setenv("GOPROXY", broken.URL+","+found.URL)
+	repo := NewGoModRepository(nopLogger{}, failingGoList, broken.Client())
+	_, err := repo.ListVersions("github.com/example/api")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all proxies failed for github.com/example/api")
+	assert.Contains(t, err.Error(), "HTTP 500")
+
+	t.Setenv("GOPROXY", broken.URL+"|"+found.URL)
+	versions, err := repo.ListVersions("github.com/example/api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0"}, versions)
+}
+
+func TestGetLatestVersionFromProxy(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		_, _ = w.Write([]byte(`{"Version":"v1.2.3"}`))
+	}))
+	defer server.Close()
+	t.Setenv("GOPROXY", server.URL)
+
+	repo := NewGoModRepository(nopLogger{}, failingGoList, server.Client())
+	version, err := repo.GetLatestVersion("github.com/Example/API")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", version)
+	assert.Equal(t, "/github.com/!example/!a!p!i/@latest", requested)
+}
+
+func TestListVersionsSkipsHTTPWithoutProxy(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("v1.0.0\n"))
+	}))
+	defer server.Close()
+
+	for _, setting := range []string{"off", "direct", "direct,off", "off," + server.URL} {
+		t.Run(setting, func(t *testing.T) {
+			t.Setenv("GOPROXY", setting)
+			repo := NewGoModRepository(nopLogger{}, failingGoList, server.Client())
+
+			_, err := repo.ListVersions("github.com/example/api")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "GOPROXY="+setting+" and go list returned no versions")
+			assert.Contains(t, err.Error(), "exit status 1")
+
+			_, err = repo.GetLatestVersion("github.com/example/api")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "GOPROXY="+setting+" and go list returned no versions")
+		})
+	}
+	assert.Zero(t, requests)
+}
+
+func TestListVersionsUsesGoListWhenProxyOff(t *testing.T) {
+	t.Setenv("GOPROXY", "off")
+	runner := &fakeRunner{respond: respondWith("github.com/example/api v1.0.0 v1.1.0\n", nil)}
+
+	repo := NewGoModRepository(nopLogger{}, runner, nil)
+	versions, err := repo.ListVersions("github.com/example/api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0"}, versions)
+}
+
+func TestGoProxySettingPrefersGoEnv(t *testing.T) {
+	t.Setenv("GOPROXY", "https://ignored.example")
+	runner := &fakeRunner{respond: func(args []string) ([]byte, error) {
+		if args[0] == "env" {
+			return []byte("off\n"), nil
+		}
+		return nil, errors.New("exit status 1")
+	}}
+
+	repo := NewGoModRepository(nopLogger{}, runner, nil)
+	_, err := repo.ListVersions("github.com/example/api")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GOPROXY=off and go list returned no versions")
+}
@@ -0,0 +1,77 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// jsonLogEntry is one line written by JSONLogger
+type jsonLogEntry struct {
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+}
+
+// JSONLogger writes one JSON object per log call, for log aggregation
+// systems that cannot parse colored output
+type JSONLogger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level domain.LogLevel
+	now   func() time.Time
+}
+
+// NewJSONLogger creates a logger writing newline-delimited JSON to out for
+// messages at level and above
+func NewJSONLogger(out io.Writer, level domain.LogLevel) domain.Logger {
+	return &JSONLogger{out: out, level: level, now: time.Now}
+}
+
+func (l *JSONLogger) Info(msg string, args ...interface{}) {
+	l.write(domain.LogLevelInfo, "info", msg, args...)
+}
+
+func (l *JSONLogger) Success(msg string, args ...interface{}) {
+	l.write(domain.LogLevelInfo, "success", msg, args...)
+}
+
+func (l *JSONLogger) Warning(msg string, args ...interface{}) {
+	l.write(domain.LogLevelWarn, "warning", msg, args...)
+}
+
+func (l *JSONLogger) Error(msg string, args ...interface{}) {
+	l.write(domain.LogLevelError, "error", msg, args...)
+}
+
+func (l *JSONLogger) Debug(msg string, args ...interface{}) {
+	l.write(domain.LogLevelDebug, "debug", msg, args...)
+}
+
+// Plain has no prefix to drop in JSON, so it is logged like Info
+func (l *JSONLogger) Plain(msg string, args ...interface{}) {
+	l.write(domain.LogLevelInfo, "info", msg, args...)
+}
+
+func (l *JSONLogger) write(msgLevel domain.LogLevel, level, msg string, args ...interface{}) {
+	if !l.level.Enables(msgLevel) {
+		return
+	}
+
+	data, err := json.Marshal(jsonLogEntry{
+		Level:     level,
+		Message:   fmt.Sprintf(msg, args...),
+		Timestamp: l.now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(append(data, '\n'))
+}
@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &JSONLogger{out: &buf, level: domain.LogLevelInfo, now: func() time.Time {
+		return time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("CEST", 2*60*60))
+	}}
+
+	logger.Debug("hidden below info")
+	logger.Info("Downloading %s@%s...", "github.com/example/api", "v1.2.3")
+	logger.Error("failed: %v", "quote \" and newline\n")
+	logger.Plain("  - %s", "orders.proto")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var entry map[string]string
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, map[string]string{
+		"level":     "info",
+		"message":   "Downloading github.com/example/api@v1.2.3...",
+		"timestamp": "2024-05-06T05:08:09Z",
+	}, entry)
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "failed: quote \" and newline\n", entry["message"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &entry))
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "  - orders.proto", entry["message"])
+}
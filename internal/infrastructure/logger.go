@@ -2,13 +2,21 @@ package infrastructure
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sync"
 
 	"github.com/Francouer/proto-sync/internal/domain"
 	"github.com/fatih/color"
 )
 
 type ColorLogger struct {
+	// mu keeps lines from concurrent repositories from interleaving
+	mu sync.Mutex
+
+	out   io.Writer
+	level domain.LogLevel
+
 	infoColor    *color.Color
 	successColor *color.Color
 	warningColor *color.Color
@@ -16,9 +24,26 @@ type ColorLogger struct {
 	debugColor   *color.Color
 }
 
-// NewColorLogger creates a new colorful logger
-func NewColorLogger() domain.Logger {
+// NewColorLogger creates a new colorful logger emitting messages at level
+// and above
+func NewColorLogger(level domain.LogLevel) domain.Logger {
+	return newColorLogger(os.Stderr, level)
+}
+
+// NewPlainLogger creates a logger with the ColorLogger layout but without
+// color escape codes, for writing to files
+func NewPlainLogger(out io.Writer, level domain.LogLevel) domain.Logger {
+	l := newColorLogger(out, level)
+	for _, c := range []*color.Color{l.infoColor, l.successColor, l.warningColor, l.errorColor, l.debugColor} {
+		c.DisableColor()
+	}
+	return l
+}
+
+func newColorLogger(out io.Writer, level domain.LogLevel) *ColorLogger {
 	return &ColorLogger{
+		out:          out,
+		level:        level,
 		infoColor:    color.New(color.FgBlue, color.Bold),
 		successColor: color.New(color.FgGreen, color.Bold),
 		warningColor: color.New(color.FgYellow, color.Bold),
@@ -28,26 +53,50 @@ func NewColorLogger() domain.Logger {
 }
 
 func (l *ColorLogger) Info(msg string, args ...interface{}) {
-	prefix := l.infoColor.Sprint("[INFO]")
-	fmt.Fprintf(os.Stderr, "%s %s\n", prefix, fmt.Sprintf(msg, args...))
+	if l.level.Enables(domain.LogLevelInfo) {
+		l.write(l.infoColor.Sprint("[INFO]"), msg, args...)
+	}
 }
 
 func (l *ColorLogger) Success(msg string, args ...interface{}) {
-	prefix := l.successColor.Sprint("[SUCCESS]")
-	fmt.Fprintf(os.Stderr, "%s %s\n", prefix, fmt.Sprintf(msg, args...))
+	if l.level.Enables(domain.LogLevelInfo) {
+		l.write(l.successColor.Sprint("[SUCCESS]"), msg, args...)
+	}
 }
 
 func (l *ColorLogger) Warning(msg string, args ...interface{}) {
-	prefix := l.warningColor.Sprint("[WARNING]")
-	fmt.Fprintf(os.Stderr, "%s %s\n", prefix, fmt.Sprintf(msg, args...))
+	if l.level.Enables(domain.LogLevelWarn) {
+		l.write(l.warningColor.Sprint("[WARNING]"), msg, args...)
+	}
 }
 
 func (l *ColorLogger) Error(msg string, args ...interface{}) {
-	prefix := l.errorColor.Sprint("[ERROR]")
-	fmt.Fprintf(os.Stderr, "%s %s\n", prefix, fmt.Sprintf(msg, args...))
+	if l.level.Enables(domain.LogLevelError) {
+		l.write(l.errorColor.Sprint("[ERROR]"), msg, args...)
+	}
 }
 
 func (l *ColorLogger) Debug(msg string, args ...interface{}) {
-	prefix := l.debugColor.Sprint("[DEBUG]")
-	fmt.Fprintf(os.Stderr, "%s %s\n", prefix, fmt.Sprintf(msg, args...))
+	if l.level.Enables(domain.LogLevelDebug) {
+		l.write(l.debugColor.Sprint("[DEBUG]"), msg, args...)
+	}
+}
+
+func (l *ColorLogger) Plain(msg string, args ...interface{}) {
+	if l.level.Enables(domain.LogLevelInfo) {
+		line := fmt.Sprintf(msg, args...) + "\n"
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		fmt.Fprint(l.out, line)
+	}
+}
+
+// write prints one log line while holding the lock
+func (l *ColorLogger) write(prefix, msg string, args ...interface{}) {
+	line := fmt.Sprintf("%s %s\n", prefix, fmt.Sprintf(msg, args...))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprint(l.out, line)
 }
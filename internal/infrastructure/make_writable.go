@@ -0,0 +1,17 @@
+//go:build !windows
+
+package infrastructure
+
+import "os"
+
+// makeWritableHint is added to MakeWritable errors
+const makeWritableHint = "check that you own the file, or run chmod u+w on it"
+
+// makeWritable adds the owner write bit to the permissions of path
+func makeWritable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(path, info.Mode()|0o200)
+}
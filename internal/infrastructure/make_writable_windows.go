@@ -0,0 +1,33 @@
+//go:build windows
+
+package infrastructure
+
+import (
+	"os"
+	"syscall"
+)
+
+// makeWritableHint is added to MakeWritable errors
+const makeWritableHint = "clear the read-only attribute with attrib -r, and check that no other program has the file open"
+
+// makeWritable clears the read-only attribute of path, which is what keeps
+// Windows from overwriting files copied out of the module cache. Windows
+// has no permission bits, so the other attributes are kept as they are.
+func makeWritable(path string) error {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return &os.PathError{Op: "chmod", Path: path, Err: err}
+	}
+
+	attrs, err := syscall.GetFileAttributes(name)
+	if err != nil {
+		return &os.PathError{Op: "chmod", Path: path, Err: err}
+	}
+	if attrs&syscall.FILE_ATTRIBUTE_READONLY == 0 {
+		return nil
+	}
+	if err := syscall.SetFileAttributes(name, attrs&^syscall.FILE_ATTRIBUTE_READONLY); err != nil {
+		return &os.PathError{Op: "chmod", Path: path, Err: err}
+	}
+	return nil
+}
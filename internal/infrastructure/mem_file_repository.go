@@ -0,0 +1,494 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// MemFileRepository is a FileRepository backed by maps instead of the disk,
+// for unit testing the service layer. It mirrors the behavior of
+// FileRepositoryImpl that the service relies on: writing needs an existing
+// parent directory, read-only files can't be overwritten until MakeWritable
+// is called, and CopyFile creates the destination directory and keeps the
+// source modification time.
+type MemFileRepository struct {
+	mu    sync.Mutex
+	files map[string]*memFile
+	dirs  map[string]struct{}
+	// tempDirs numbers the directories created by TempDir
+	tempDirs int
+}
+
+type memFile struct {
+	data     []byte
+	modTime  time.Time
+	readOnly bool
+}
+
+// NewMemFileRepository creates an empty in-memory file repository. The
+// concrete type is returned so tests can seed files with AddFile.
+func NewMemFileRepository() *MemFileRepository {
+	return &MemFileRepository{
+		files: make(map[string]*memFile),
+		dirs:  make(map[string]struct{}),
+	}
+}
+
+// AddFile creates path with data, along with its parent directories
+func (m *MemFileRepository) AddFile(path string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	m.mkdirAll(filepath.Dir(path))
+	m.files[path] = &memFile{data: append([]byte(nil), data...), modTime: time.Now()}
+}
+
+// SetReadOnly marks path read-only, like the protos proto-sync installs
+func (m *MemFileRepository) SetReadOnly(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return notExist("chmod", path)
+	}
+	file.readOnly = true
+	return nil
+}
+
+// IsReadOnly reports whether path exists and is read-only
+func (m *MemFileRepository) IsReadOnly(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, ok := m.files[filepath.Clean(path)]
+	return ok && file.readOnly
+}
+
+// Files returns the path of every file in sorted order
+func (m *MemFileRepository) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	paths := make([]string, 0, len(m.files))
+	for path := range m.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (m *MemFileRepository) ReadFile(path string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, notExist("open", path)
+	}
+	return append([]byte(nil), file.data...), nil
+}
+
+func (m *MemFileRepository) WriteFile(path string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.write("open", filepath.Clean(path), data, time.Now())
+}
+
+func (m *MemFileRepository) CopyFile(src, dst string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source, ok := m.files[filepath.Clean(src)]
+	if !ok {
+		return fmt.Errorf("failed to open source file %s: %w", src, notExist("open", src))
+	}
+
+	dst = filepath.Clean(dst)
+	if err := m.mkdirAll(filepath.Dir(dst)); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	if err := m.write("open", dst, source.data, source.modTime); err != nil {
+		return fmt.Errorf("failed to create destination file %s: %w", dst, err)
+	}
+	return nil
+}
+
+func (m *MemFileRepository) VerifyCopy(src, dst string) error {
+	srcData, err := m.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for verification: %w", src, err)
+	}
+	dstData, err := m.ReadFile(dst)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for verification: %w", dst, err)
+	}
+
+	if !bytes.Equal(srcData, dstData) {
+		srcHash, dstHash := sha256.Sum256(srcData), sha256.Sum256(dstData)
+		return fmt.Errorf("copy verification failed for %s: source sha256 %s, destination sha256 %s", dst, hex.EncodeToString(srcHash[:]), hex.EncodeToString(dstHash[:]))
+	}
+	return nil
+}
+
+func (m *MemFileRepository) CreateDir(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(filepath.Clean(path))
+}
+
+func (m *MemFileRepository) FileExists(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	_, isFile := m.files[path]
+	return isFile || m.isDir(path)
+}
+
+func (m *MemFileRepository) IsDir(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.isDir(filepath.Clean(path))
+}
+
+func (m *MemFileRepository) ModTime(path string) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return time.Time{}, notExist("stat", path)
+	}
+	return file.modTime, nil
+}
+
+// ListFiles walks dirPath in the same lexical order as filepath.Walk and
+// applies the same pattern rules as FileRepositoryImpl. There are no
+// symlinks in memory, so opts has no effect.
+func (m *MemFileRepository) ListFiles(dirPath string, pattern string, opts domain.ListOptions) ([]domain.ProtoFile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	root := filepath.Clean(dirPath)
+	if _, isFile := m.files[root]; !isFile && !m.isDir(root) {
+		return nil, notExist("lstat", dirPath)
+	}
+
+	var files []domain.ProtoFile
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		for _, name := range m.children(dir) {
+			path := filepath.Join(dir, name)
+			if m.isDir(path) {
+				if err := walk(path); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if pattern != "" {
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+				matched, err := domain.MatchGlob(pattern, filepath.ToSlash(rel))
+				if err != nil {
+					return err
+				}
+				if !matched {
+					continue
+				}
+			}
+			if pattern == "" && !strings.HasSuffix(name, ".proto") {
+				continue
+			}
+
+			file := m.files[path]
+			files = append(files, domain.ProtoFile{
+				Name:         name,
+				Path:         path,
+				Size:         int64(len(file.data)),
+				ModifiedTime: file.modTime,
+			})
+		}
+		return nil
+	}
+
+	if !m.isDir(root) {
+		// Walking a file visits just that file
+		file := m.files[root]
+		return []domain.ProtoFile{{Name: filepath.Base(root), Path: root, Size: int64(len(file.data)), ModifiedTime: file.modTime}}, nil
+	}
+	err := walk(root)
+	return files, err
+}
+
+func (m *MemFileRepository) MakeWritable(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	file, ok := m.files[path]
+	if !ok {
+		if m.isDir(path) {
+			return nil
+		}
+		return notExist("stat", path)
+	}
+	file.readOnly = false
+	return nil
+}
+
+func (m *MemFileRepository) DeleteFile(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if _, ok := m.files[path]; !ok {
+		return fmt.Errorf("failed to delete %s: %w", path, notExist("remove", path))
+	}
+	delete(m.files, path)
+	return nil
+}
+
+// ResolvePath returns the absolute form of path; there are no symlinks in
+// memory
+func (m *MemFileRepository) ResolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path for %s: %w", path, err)
+	}
+	if !m.FileExists(path) && !m.FileExists(absPath) {
+		return "", fmt.Errorf("failed to resolve symlinks for %s: %w", path, notExist("lstat", path))
+	}
+	return absPath, nil
+}
+
+func (m *MemFileRepository) DirSize(path string) (int64, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if file, ok := m.files[path]; ok {
+		return int64(len(file.data)), 1, nil
+	}
+	if !m.isDir(path) {
+		return 0, 0, notExist("lstat", path)
+	}
+
+	var size int64
+	var count int
+	for filePath, file := range m.files {
+		if isBelow(path, filePath) {
+			size += int64(len(file.data))
+			count++
+		}
+	}
+	return size, count, nil
+}
+
+func (m *MemFileRepository) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	for filePath := range m.files {
+		if filePath == path || isBelow(path, filePath) {
+			delete(m.files, filePath)
+		}
+	}
+	for dir := range m.dirs {
+		if dir == path || isBelow(path, dir) {
+			delete(m.dirs, dir)
+		}
+	}
+	return nil
+}
+
+func (m *MemFileRepository) TempDir(dir, prefix string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dir = filepath.Clean(dir)
+	if !m.isDir(dir) {
+		return "", fmt.Errorf("failed to create temporary directory in %s: %w", dir, notExist("mkdirtemp", dir))
+	}
+	for {
+		m.tempDirs++
+		path := filepath.Join(dir, fmt.Sprintf("%s%d", prefix, m.tempDirs))
+		if _, isFile := m.files[path]; !isFile && !m.isDir(path) {
+			m.dirs[path] = struct{}{}
+			return path, nil
+		}
+	}
+}
+
+func (m *MemFileRepository) CopyDir(src, dst string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	src, dst = filepath.Clean(src), filepath.Clean(dst)
+	if !m.isDir(src) {
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, notExist("open", src))
+	}
+	if err := m.mkdirAll(dst); err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	dirs := make(map[string]struct{})
+	for dir := range m.dirs {
+		if isBelow(src, dir) {
+			dirs[dst+strings.TrimPrefix(dir, src)] = struct{}{}
+		}
+	}
+	files := make(map[string]*memFile)
+	for path, file := range m.files {
+		if isBelow(src, path) {
+			copied := *file
+			copied.data = append([]byte(nil), file.data...)
+			files[dst+strings.TrimPrefix(path, src)] = &copied
+		}
+	}
+	for dir := range dirs {
+		m.dirs[dir] = struct{}{}
+	}
+	for path, file := range files {
+		m.files[path] = file
+	}
+	return nil
+}
+
+func (m *MemFileRepository) MoveDir(src, dst string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	src, dst = filepath.Clean(src), filepath.Clean(dst)
+	if !m.isDir(src) {
+		return fmt.Errorf("failed to move %s: not a directory", src)
+	}
+	if _, isFile := m.files[dst]; isFile {
+		return fmt.Errorf("failed to move %s to %s: destination is not a directory", src, dst)
+	}
+	if m.isDir(dst) && len(m.children(dst)) > 0 {
+		return fmt.Errorf("failed to move %s to %s: destination is not empty", src, dst)
+	}
+	if !m.isDir(filepath.Dir(dst)) {
+		return fmt.Errorf("failed to move %s to %s: %w", src, dst, notExist("rename", dst))
+	}
+
+	moves := func(path string) bool { return path == src || isBelow(src, path) }
+	dirs := make(map[string]struct{})
+	for dir := range m.dirs {
+		if moves(dir) {
+			delete(m.dirs, dir)
+			dirs[dst+strings.TrimPrefix(dir, src)] = struct{}{}
+		}
+	}
+	files := make(map[string]*memFile)
+	for path, file := range m.files {
+		if moves(path) {
+			delete(m.files, path)
+			files[dst+strings.TrimPrefix(path, src)] = file
+		}
+	}
+	for dir := range dirs {
+		m.dirs[dir] = struct{}{}
+	}
+	for path, file := range files {
+		m.files[path] = file
+	}
+	return nil
+}
+
+// write stores data at path, which must be clean. The caller holds the lock.
+func (m *MemFileRepository) write(op, path string, data []byte, modTime time.Time) error {
+	if !m.isDir(filepath.Dir(path)) {
+		return notExist(op, path)
+	}
+	if m.isDir(path) {
+		return &fs.PathError{Op: op, Path: path, Err: fmt.Errorf("is a directory")}
+	}
+	if file, ok := m.files[path]; ok && file.readOnly {
+		return &fs.PathError{Op: op, Path: path, Err: fs.ErrPermission}
+	}
+
+	m.files[path] = &memFile{data: append([]byte(nil), data...), modTime: modTime}
+	return nil
+}
+
+// mkdirAll creates path and its parents. The caller holds the lock.
+func (m *MemFileRepository) mkdirAll(path string) error {
+	for dir := path; !isRootDir(dir); dir = filepath.Dir(dir) {
+		if _, isFile := m.files[dir]; isFile {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: fmt.Errorf("not a directory")}
+		}
+		m.dirs[dir] = struct{}{}
+	}
+	return nil
+}
+
+func (m *MemFileRepository) isDir(path string) bool {
+	if isRootDir(path) {
+		return true
+	}
+	_, ok := m.dirs[path]
+	return ok
+}
+
+// children returns the sorted names of the entries directly in dir
+func (m *MemFileRepository) children(dir string) []string {
+	seen := make(map[string]struct{})
+	add := func(path string) {
+		if path != dir && filepath.Dir(path) == dir {
+			seen[filepath.Base(path)] = struct{}{}
+		}
+	}
+	for path := range m.files {
+		add(path)
+	}
+	for path := range m.dirs {
+		add(path)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isRootDir reports whether path is the root or the working directory,
+// which always exist
+func isRootDir(path string) bool {
+	return path == "." || path == string(filepath.Separator) || filepath.Dir(path) == path
+}
+
+// isBelow reports whether path is inside dir
+func isBelow(dir, path string) bool {
+	if isRootDir(dir) && dir == "." {
+		return !filepath.IsAbs(path)
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+func notExist(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+}
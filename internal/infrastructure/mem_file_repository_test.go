@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemFileRepositoryCopyFile(t *testing.T) {
+	repo := NewMemFileRepository()
+	repo.AddFile("/src/a.proto", []byte("a"))
+
+	require.NoError(t, repo.CopyFile("/src/a.proto", "/dst/nested/a.proto"))
+	assert.True(t, repo.IsDir("/dst/nested"))
+	data, err := repo.ReadFile("/dst/nested/a.proto")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+
+	srcTime, err := repo.ModTime("/src/a.proto")
+	require.NoError(t, err)
+	dstTime, err := repo.ModTime("/dst/nested/a.proto")
+	require.NoError(t, err)
+	assert.Equal(t, srcTime, dstTime)
+
+	err = repo.CopyFile("/src/missing.proto", "/dst/missing.proto")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestMemFileRepositoryReadOnly(t *testing.T) {
+	repo := NewMemFileRepository()
+	repo.AddFile("/src/a.proto", []byte("new"))
+	repo.AddFile("/dst/a.proto", []byte("old"))
+	require.NoError(t, repo.SetReadOnly("/dst/a.proto"))
+
+	err := repo.CopyFile("/src/a.proto", "/dst/a.proto")
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+
+	require.NoError(t, repo.MakeWritable("/dst/a.proto"))
+	require.NoError(t, repo.CopyFile("/src/a.proto", "/dst/a.proto"))
+	assert.False(t, repo.IsReadOnly("/dst/a.proto"))
+}
+
+func TestMemFileRepositoryDirectories(t *testing.T) {
+	repo := NewMemFileRepository()
+	repo.AddFile("/dst/a.proto", []byte("a"))
+
+	err := repo.WriteFile("/missing/a.proto", []byte("a"))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.Error(t, repo.CreateDir("/dst/a.proto/nested"))
+
+	require.NoError(t, repo.CreateDir("/dst/empty"))
+	assert.True(t, repo.FileExists("/dst/empty"))
+	assert.False(t, repo.IsDir("/dst/a.proto"))
+
+	size, count, err := repo.DirSize("/dst")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, repo.RemoveAll("/dst"))
+	assert.False(t, repo.FileExists("/dst/a.proto"))
+	assert.False(t, repo.FileExists("/dst/empty"))
+}
+
+func TestMemFileRepositoryMoveDir(t *testing.T) {
+	repo := NewMemFileRepository()
+	repo.AddFile("/proto/a.proto", []byte("a"))
+	repo.AddFile("/proto/nested/b.proto", []byte("b"))
+
+	staging, err := repo.TempDir("/", ".proto.staging-")
+	require.NoError(t, err)
+	require.NoError(t, repo.CopyDir("/proto", staging))
+	assert.True(t, repo.FileExists(staging+"/nested/b.proto"))
+
+	assert.ErrorContains(t, repo.MoveDir(staging, "/proto"), "destination is not empty")
+
+	require.NoError(t, repo.CreateDir("/old"))
+	require.NoError(t, repo.MoveDir("/proto", "/old"))
+	require.NoError(t, repo.MoveDir(staging, "/proto"))
+	assert.Equal(t, []string{"/old/a.proto", "/old/nested/b.proto", "/proto/a.proto", "/proto/nested/b.proto"}, repo.Files())
+}
+
+func TestMemFileRepositoryListFiles(t *testing.T) {
+	repo := NewMemFileRepository()
+	repo.AddFile("/src/b.proto", nil)
+	repo.AddFile("/src/a-b.proto", nil)
+	repo.AddFile("/src/a/nested.proto", nil)
+	repo.AddFile("/src/README.md", nil)
+
+	files, err := repo.ListFiles("/src", "", domain.ListOptions{})
+	require.NoError(t, err)
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	// Same order as filepath.Walk: directory entries sorted by name
+	assert.Equal(t, []string{"/src/a/nested.proto", "/src/a-b.proto", "/src/b.proto"}, paths)
+
+	files, err = repo.ListFiles("/src", "a/*.proto", domain.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "nested.proto", files[0].Name)
+
+	_, err = repo.ListFiles("/missing", "*.proto", domain.ListOptions{})
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// MultiLogger forwards every call to each of its loggers
+type MultiLogger struct {
+	loggers []domain.Logger
+}
+
+// NewMultiLogger creates a logger writing to all of loggers
+func NewMultiLogger(loggers ...domain.Logger) domain.Logger {
+	return &MultiLogger{loggers: loggers}
+}
+
+func (m *MultiLogger) Info(msg string, args ...interface{}) {
+	for _, l := range m.loggers {
+		l.Info(msg, args...)
+	}
+}
+
+func (m *MultiLogger) Success(msg string, args ...interface{}) {
+	for _, l := range m.loggers {
+		l.Success(msg, args...)
+	}
+}
+
+func (m *MultiLogger) Warning(msg string, args ...interface{}) {
+	for _, l := range m.loggers {
+		l.Warning(msg, args...)
+	}
+}
+
+func (m *MultiLogger) Error(msg string, args ...interface{}) {
+	for _, l := range m.loggers {
+		l.Error(msg, args...)
+	}
+}
+
+func (m *MultiLogger) Debug(msg string, args ...interface{}) {
+	for _, l := range m.loggers {
+		l.Debug(msg, args...)
+	}
+}
+
+func (m *MultiLogger) Plain(msg string, args ...interface{}) {
+	for _, l := range m.loggers {
+		l.Plain(msg, args...)
+	}
+}
+
+// OpenLogFile opens path for appending, creating it and its directory when
+// missing
+func OpenLogFile(path string, fileRepo domain.FileRepository) (*os.File, error) {
+	if err := fileRepo.CreateDir(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("failed to create log directory for %s: %w", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", path, err)
+	}
+	return file, nil
+}
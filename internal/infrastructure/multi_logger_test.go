@@ -0,0 +1,41 @@
+package infrastructure
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiLoggerWritesPlainFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "nested", "sync.log")
+	file, err := OpenLogFile(path, NewFileRepository(nopLogger{}))
+	require.NoError(t, err)
+
+	var console bytes.Buffer
+	logger := NewMultiLogger(NewPlainLogger(&console, domain.LogLevelInfo), NewPlainLogger(file, domain.LogLevelInfo))
+	logger.Info("Downloading %s", "github.com/example/api")
+	logger.Debug("not logged")
+	logger.Plain("  - %s", "orders.proto")
+	require.NoError(t, file.Close())
+
+	// Reopening appends instead of truncating
+	file, err = OpenLogFile(path, NewFileRepository(nopLogger{}))
+	require.NoError(t, err)
+	NewPlainLogger(file, domain.LogLevelInfo).Warning("second run")
+	require.NoError(t, file.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[INFO] Downloading github.com/example/api\n  - orders.proto\n[WARNING] second run\n", string(data))
+	assert.Equal(t, "[INFO] Downloading github.com/example/api\n  - orders.proto\n", console.String())
+
+	// Plain output follows the info threshold
+	console.Reset()
+	NewPlainLogger(&console, domain.LogLevelError).Plain("hidden")
+	assert.Empty(t, console.String())
+}
@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+type ShellRunnerImpl struct {
+	logger domain.Logger
+}
+
+// NewShellRunner creates a runner for user-supplied shell commands
+func NewShellRunner(logger domain.Logger) domain.ShellRunner {
+	return &ShellRunnerImpl{
+		logger: logger,
+	}
+}
+
+func (s *ShellRunnerImpl) Run(ctx context.Context, command string, env []string, stdin []byte) ([]byte, error) {
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	s.logger.Debug("Running command: %s", command)
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("command %q failed: %w\nOutput: %s", command, err, msg)
+		}
+		return nil, fmt.Errorf("command %q failed: %w", command, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func (s *ShellRunnerImpl) Stream(ctx context.Context, label, command string, env []string) error {
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), env...)
+
+	s.logger.Debug("Running command: %s", command)
+	if err := runStreaming(s.logger, cmd, label); err != nil {
+		return fmt.Errorf("command %q failed: %w", command, err)
+	}
+	return nil
+}
+
+// runStreaming runs cmd, logging each line of its combined output prefixed
+// with label as it is produced
+func runStreaming(logger domain.Logger, cmd *exec.Cmd, label string) error {
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if line := strings.TrimRight(scanner.Text(), " \t"); line != "" {
+				logger.Info("  %s: %s", label, line)
+			}
+		}
+		// Keep draining so the command never blocks on a full pipe
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+
+	err := cmd.Run()
+	writer.Close()
+	<-done
+	return err
+}
+
+// shellCommand wraps command in the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
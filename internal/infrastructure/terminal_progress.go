@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// TerminalProgress redraws a single "copied X/Y files" line while a
+// repository's protos are copied
+type TerminalProgress struct {
+	mu     sync.Mutex
+	out    io.Writer
+	active bool
+}
+
+// NewTerminalProgress creates a progress display writing to out, which
+// should be a terminal
+func NewTerminalProgress(out io.Writer) domain.ProgressReporter {
+	return &TerminalProgress{out: out}
+}
+
+// IsTerminal reports whether f is a character device such as a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (t *TerminalProgress) Report(event domain.ProgressEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event.Event {
+	case domain.ProgressFileCopied:
+		if event.Total == 0 {
+			return
+		}
+		// \r returns to the line start and \033[K clears what was there
+		fmt.Fprintf(t.out, "\r\033[K  copied %d/%d files", event.Copied, event.Total)
+		t.active = true
+		if event.Copied == event.Total {
+			t.finish()
+		}
+	case domain.ProgressRepoDone:
+		t.finish()
+	}
+}
+
+// finish ends the progress line so following log lines start fresh
+func (t *TerminalProgress) finish() {
+	if t.active {
+		fmt.Fprintln(t.out)
+		t.active = false
+	}
+}
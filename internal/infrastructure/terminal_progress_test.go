@@ -0,0 +1,21 @@
+package infrastructure
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalProgress(t *testing.T) {
+	var buf bytes.Buffer
+	progress := NewTerminalProgress(&buf)
+
+	progress.Report(domain.ProgressEvent{Event: domain.ProgressRepoStart, Repo: "github.com/example/api"})
+	progress.Report(domain.ProgressEvent{Event: domain.ProgressFileCopied, Name: "a.proto", Copied: 1, Total: 2})
+	progress.Report(domain.ProgressEvent{Event: domain.ProgressFileCopied, Name: "b.proto", Copied: 2, Total: 2})
+	progress.Report(domain.ProgressEvent{Event: domain.ProgressRepoDone, Repo: "github.com/example/api"})
+
+	assert.Equal(t, "\r\033[K  copied 1/2 files\r\033[K  copied 2/2 files\n", buf.String())
+}
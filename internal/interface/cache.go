@@ -0,0 +1,71 @@
+package interfaces
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func (c *CLIHandler) createCacheCommand() *cobra.Command {
+	var cacheDir string
+
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clean proto-sync's file cache",
+	}
+	cacheCmd.PersistentFlags().StringVar(&cacheDir, "file-cache-dir", os.Getenv("PROTO_SYNC_FILE_CACHE_DIR"), "proto-sync file cache directory")
+
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "info",
+		Short: "Show the size and contents of the file cache",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.handleCacheInfo(cacheDir)
+		},
+	})
+
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "clean",
+		Short: "Remove the file cache (never touches GOMODCACHE)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.service.CleanCache(cacheDir)
+		},
+	})
+
+	return cacheCmd
+}
+
+func (c *CLIHandler) handleCacheInfo(cacheDir string) error {
+	info, err := c.service.CacheInfo(cacheDir)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Plain("Cache directory: %s", info.Path)
+	c.logger.Plain("Size: %s in %d file(s)", formatBytes(info.SizeBytes), info.FileCount)
+	if len(info.Entries) == 0 {
+		c.logger.Plain("No cached modules")
+		return nil
+	}
+
+	c.logger.Plain("Cached modules:")
+	for _, entry := range info.Entries {
+		c.logger.Plain("  - %s", entry)
+	}
+	return nil
+}
+
+// formatBytes renders a byte count using binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
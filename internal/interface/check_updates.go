@@ -0,0 +1,90 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (c *CLIHandler) createCheckUpdatesCommand() *cobra.Command {
+	var config domain.SyncConfig
+
+	cmd := &cobra.Command{
+		Use:   "check-updates",
+		Short: "Compare pinned versions with the newest ones; exits 7 when any repository is behind",
+		Long:  "Compare the version of every repository in go.mod, or given with --repo, with the newest available one. Nothing is downloaded or copied.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return c.handleCheckUpdates(cmd.Context(), &config)
+		},
+	}
+	c.addFlags(cmd, &config)
+
+	return cmd
+}
+
+func (c *CLIHandler) handleCheckUpdates(ctx context.Context, config *domain.SyncConfig) error {
+	if err := c.validateRequiredTools("go"); err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, config)
+	defer cancel()
+
+	checks, err := c.service.CheckUpdates(ctx, config)
+	if err != nil {
+		c.logger.Error("Checking for updates failed: %v", err)
+		return err
+	}
+
+	if err := writeUpdateChecks(os.Stdout, checks); err != nil {
+		return err
+	}
+	return updateCheckError(checks)
+}
+
+// writeUpdateChecks prints one table row per repository
+func writeUpdateChecks(w io.Writer, checks []domain.UpdateCheck) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tCURRENT\tLATEST\tSTATUS")
+	for _, check := range checks {
+		latest, status := check.Latest, "up to date"
+		switch {
+		case check.Repository.LocalPath != "":
+			latest, status = "-", "local replace "+check.Repository.LocalPath
+		case check.Error != nil:
+			latest, status = "?", "error: "+check.Error.Error()
+		case check.UpdateAvailable:
+			status = "update available"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", check.Repository.Name, check.Current, latest, status)
+	}
+	return tw.Flush()
+}
+
+// updateCheckError fails the command when a latest version couldn't be
+// resolved, or with ExitUpdatesAvailable when a repository is outdated
+func updateCheckError(checks []domain.UpdateCheck) error {
+	outdated, failed := 0, 0
+	for _, check := range checks {
+		switch {
+		case check.Error != nil:
+			failed++
+		case check.UpdateAvailable:
+			outdated++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("could not check %d of %d repositories for updates", failed, len(checks))
+	}
+	if outdated > 0 {
+		return &ExitError{Code: ExitUpdatesAvailable, Err: fmt.Errorf("%d of %d repositories have newer versions", outdated, len(checks))}
+	}
+	return nil
+}
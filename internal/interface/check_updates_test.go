@@ -0,0 +1,36 @@
+package interfaces
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteUpdateChecks(t *testing.T) {
+	checks := []domain.UpdateCheck{
+		{Repository: domain.Repository{Name: "github.com/example/api"}, Current: "v1.0.0", Latest: "v1.2.0", UpdateAvailable: true},
+		{Repository: domain.Repository{Name: "github.com/example/users"}, Current: "v0.3.0", Latest: "v0.3.0"},
+		{Repository: domain.Repository{Name: "github.com/example/local", LocalPath: "../local"}, Current: "v0.1.0"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeUpdateChecks(&buf, checks))
+	assert.Equal(t, "REPOSITORY                CURRENT  LATEST  STATUS\n"+
+		"github.com/example/api    v1.0.0   v1.2.0  update available\n"+
+		"github.com/example/users  v0.3.0   v0.3.0  up to date\n"+
+		"github.com/example/local  v0.1.0   -       local replace ../local\n", buf.String())
+
+	var exitErr *ExitError
+	require.ErrorAs(t, updateCheckError(checks), &exitErr)
+	assert.Equal(t, ExitUpdatesAvailable, exitErr.Code)
+	assert.NoError(t, updateCheckError(checks[1:]))
+
+	checks = append(checks, domain.UpdateCheck{Repository: domain.Repository{Name: "github.com/example/broken"}, Error: errors.New("not found")})
+	err := updateCheckError(checks)
+	require.Error(t, err)
+	assert.NotErrorAs(t, err, &exitErr)
+}
@@ -0,0 +1,66 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (c *CLIHandler) createCleanCommand() *cobra.Command {
+	var config domain.SyncConfig
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Delete the synced protos from the target directories",
+		Long: `Delete every .proto file below the buf.yaml module path, or below each
+--target. Protected files, files matched by .protosyncignore and --backup
+copies are kept.
+Preview with --dry-run; deleting needs --yes.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !config.DryRun && !yes {
+				return usageError(fmt.Errorf("clean deletes every .proto file in the targets; pass --yes to confirm or --dry-run to preview"))
+			}
+			cmd.SilenceUsage = true
+			c.writeLogBanner(cmd, nil)
+			return c.handleClean(cmd.Context(), &config)
+		},
+	}
+	cmd.Flags().StringVarP(&config.BufYamlPath, "buf-yaml", "b", getEnvOrDefault("BUF_YAML_PATH", "buf.yaml"), "Path to buf.yaml file")
+	cmd.Flags().StringArrayVar(&config.Targets, "target", nil, "Target directory to clean, overriding buf.yaml (repeatable)")
+	cmd.Flags().StringVar(&config.Module, "module", "", "buf.yaml module (name or path) to clean")
+	cmd.Flags().StringVar(&config.DefaultModulePath, "default-module-path", "", "Target path for buf v1 files without modules (required unless --target is set)")
+	cmd.Flags().StringArrayVar(&config.Protect, "protect", nil, "Glob of target files that are never deleted (repeatable, buf config files are always protected)")
+	cmd.Flags().BoolVarP(&config.DryRun, "dry-run", "d", false, "List the files that would be deleted without deleting them")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Confirm deleting the files")
+
+	return cmd
+}
+
+func (c *CLIHandler) handleClean(ctx context.Context, config *domain.SyncConfig) error {
+	files, err := c.service.Clean(ctx, config)
+
+	verb := "Deleted"
+	if config.DryRun {
+		verb = "Would delete"
+	}
+	if len(files) > 0 {
+		c.logger.Plain("%s %d proto file(s):", verb, len(files))
+		for _, file := range files {
+			c.logger.Plain("  - %s", file.Path)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		c.logger.Info("No proto files to delete")
+	} else if !config.DryRun {
+		c.logger.Success("Cleaned %d proto file(s)", len(files))
+	}
+	return nil
+}
@@ -2,8 +2,14 @@ package interfaces
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/Francouer/proto-sync/internal/domain"
 	"github.com/spf13/cobra"
@@ -12,6 +18,23 @@ import (
 type CLIHandler struct {
 	service domain.ProtoSyncService
 	logger  domain.Logger
+	output  outputOptions
+	// logFile receives a banner describing each run when --log-file is set
+	logFile io.Writer
+	// terminalProgress shows copy progress when stderr is a terminal
+	terminalProgress domain.ProgressReporter
+}
+
+// outputOptions holds flags that only affect how results are reported
+type outputOptions struct {
+	describeChanges  bool
+	describeTemplate string
+	printConfig      bool
+	progressFile     string
+	progressFD       int
+	format           string
+	jsonErrorsOnly   bool
+	noProgress       bool
 }
 
 // NewCLIHandler creates a new CLI handler
@@ -32,132 +55,410 @@ func (c *CLIHandler) CreateRootCommand() *cobra.Command {
 		Long: `Proto Sync automatically detects protobuf libraries from go.mod or allows manual specification.
 It downloads specific versions and copies proto files to local directories with colorful logging.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// Flags parsed fine, so later errors are not usage errors
+			cmd.SilenceUsage = true
 			return c.handleSync(cmd.Context(), &config)
 		},
+		// Errors are reported by main so exit codes can be honoured
+		SilenceErrors: true,
 	}
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError(err)
+	})
 
 	// Add flags
 	c.addFlags(rootCmd, &config)
+	// Read by main before parsing to set up the logger; registered here so
+	// every command accepts it
+	rootCmd.PersistentFlags().String("work-dir", "", "Run as if started in this directory: relative paths such as --go-mod, --buf-yaml, --target and --log-file resolve against it")
+	rootCmd.PersistentFlags().String("log-file", os.Getenv("LOG_FILE"), "Also append logs, without colors, to this file")
+	rootCmd.PersistentFlags().String("log-level", getEnvOrDefault("LOG_LEVEL", "info"), "Minimum log level: debug, info, warn, error or silent")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Only log errors, e.g. for cron jobs; same as --log-level error")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "log-level")
+	rootCmd.PersistentFlags().String("log-format", getEnvOrDefault("LOG_FORMAT", LogFormatText), "Log format: text (colored) or json (one object per line)")
 
 	// Add subcommands
 	rootCmd.AddCommand(c.createListVersionsCommand(&config))
+	rootCmd.AddCommand(c.createCacheCommand())
+	rootCmd.AddCommand(c.createDiffCommand())
+	rootCmd.AddCommand(c.createRollbackCommand())
+	rootCmd.AddCommand(c.createCleanCommand())
+	rootCmd.AddCommand(c.createValidateCommand())
+	rootCmd.AddCommand(c.createCheckUpdatesCommand())
+	rootCmd.AddCommand(c.createInitCommand())
+	rootCmd.AddCommand(c.createVerifyManifestCommand())
 
 	return rootCmd
 }
 
 func (c *CLIHandler) addFlags(cmd *cobra.Command, config *domain.SyncConfig) {
 	// Set default values from environment variables or defaults
-	defaultRepo := os.Getenv("REPO_NAME")
+	var repoFlags, versionFlags []string
+	if repo := os.Getenv("REPO_NAME"); repo != "" {
+		repoFlags = []string{repo}
+	}
 	defaultSourcePath := getEnvOrDefault("SOURCE_PATH_IN_REPO", "schemas/api/v1")
 	defaultBufYaml := getEnvOrDefault("BUF_YAML_PATH", "buf.yaml")
 	defaultGoMod := getEnvOrDefault("GO_MOD_PATH", "../go.mod")
-	defaultProtoFile := os.Getenv("PROTO_FILE_NAME")
+	var defaultProtoFiles []string
+	if file := os.Getenv("PROTO_FILE_NAME"); file != "" {
+		defaultProtoFiles = []string{file}
+	}
 
-	cmd.Flags().StringVarP(&config.SpecifiedVersion, "version", "v", "", "Specify version to download (default: auto-detect from go.mod)")
-	cmd.Flags().StringVarP(&defaultRepo, "repo", "r", defaultRepo, "Repository name (default: auto-detect from go.mod)")
+	cmd.Flags().StringArrayVarP(&versionFlags, "version", "v", nil, "Version to download, or a range such as ^1.2.0 or '>=1.2, <2.0'; repeat once per --repo to pin each (default: auto-detect from go.mod)")
+	cmd.Flags().StringArrayVarP(&repoFlags, "repo", "r", repoFlags, "Repository name, optionally as NAME@VERSION and with its own target as NAME,target=DIR (repeatable, default: auto-detect from go.mod)")
 	cmd.Flags().StringVarP(&config.SourcePath, "source", "s", defaultSourcePath, "Source path in repository")
 	cmd.Flags().StringVarP(&config.BufYamlPath, "buf-yaml", "b", defaultBufYaml, "Path to buf.yaml file")
 	cmd.Flags().StringVarP(&config.GoModPath, "go-mod", "g", defaultGoMod, "Path to go.mod file")
-	cmd.Flags().StringVarP(&config.SpecificFile, "proto-file", "f", defaultProtoFile, "Download only specific proto file")
-	cmd.Flags().BoolVarP(&config.DryRun, "dry-run", "d", false, "Show what would be done without executing")
+	cmd.Flags().StringVar(&config.SourceOfTruth, "source-of-truth", domain.SourceOfTruthGoMod, "Where to auto-detect repositories from: gomod (go.mod requirements) or buf (buf.yaml and buf.lock deps)")
+	cmd.Flags().StringVar(&config.VersionsFile, "versions-file", os.Getenv("VERSIONS_FILE"), "YAML file mapping module paths to versions, overriding go.mod")
+	cmd.Flags().StringArrayVarP(&config.SpecificFiles, "proto-file", "f", defaultProtoFiles, "Download only this proto file, or the files matching a glob (repeatable)")
+	cmd.Flags().BoolVarP(&config.DryRun, "dry-run", "d", false, "Show what would be done without executing; exits 6 when files would change or be pruned, or could not be compared")
+	cmd.Flags().BoolVar(&config.DryRunDiff, "dry-run-diff", false, "Dry run that downloads modules missing from the cache to tell modified target files from identical ones")
 	cmd.Flags().BoolVar(&config.SingleRepo, "single-repo", false, "Process only the first repository found")
-
-	// Handle repository parsing after flags are parsed
+	cmd.Flags().StringArrayVar(&config.Targets, "target", nil, "Target directory to copy protos into, overriding buf.yaml; {version}, {repo} and {repoBase} expand per repository (repeatable)")
+	cmd.Flags().BoolVar(&config.Latest, "latest", false, "Sync the newest available version of each repository, ignoring go.mod")
+	cmd.Flags().BoolVar(&config.StableOnly, "stable-only", false, "With --latest or module@latest, skip pre-releases and pseudo-versions")
+	cmd.Flags().BoolVar(&config.LatestPatch, "latest-patch", false, "Upgrade each repository to the newest patch release of its go.mod major.minor")
+	cmd.Flags().BoolVar(&config.DereferenceSymlinks, "dereference-symlinks", false, "Follow symlinked files and directories in the source, failing on symlink cycles")
+	cmd.Flags().BoolVar(&config.Recursive, "recursive", false, "Preserve source subdirectories under the target instead of flattening files")
+	cmd.Flags().BoolVar(&config.Lint, "lint", false, "Run buf lint on the targets after syncing and fail if it reports issues")
+	cmd.Flags().BoolVar(&config.Generate, "generate", false, "Run buf generate next to buf.yaml after every repository synced successfully")
+	cmd.Flags().BoolVar(&config.Prune, "prune", false, "Delete target protos that no synced repository provides anymore")
+	cmd.Flags().BoolVar(&config.Force, "force", false, "Rewrite target files even when they are already identical to the source")
+	cmd.Flags().BoolVar(&config.Backup, "backup", false, "Copy target files into <target>/.proto-sync-backup/<timestamp>/, as .proto.bak files, before replacing them")
+	cmd.Flags().BoolVar(&config.Atomic, "atomic", false, "Copy into a staging directory beside each target and swap it into place only when every repository synced")
+	cmd.Flags().StringVar(&config.DefaultModulePath, "default-module-path", "", "Target path for buf v1 files without modules (default: the buf.yaml directory, except with --prune)")
+	cmd.Flags().StringVar(&config.Module, "module", "", "buf.yaml module (name or path) to sync into when buf.yaml declares several")
+	cmd.Flags().IntVar(&config.Retries, "retries", 2, "Number of times a failed module download is retried")
+	cmd.Flags().DurationVar(&config.RetryDelay, "retry-delay", time.Second, "Wait before the first download retry, doubled for each further retry")
+	cmd.Flags().StringVar(&config.Netrc, "netrc", "", "Credentials file the go command uses for private module proxies and hosts (sets NETRC for downloads)")
+	// The token's default is read from the environment in PreRunE so help
+	// output never shows it
+	cmd.Flags().StringVar(&config.Token, "token", "", "Token, or USER:TOKEN, for git over HTTPS to private module hosts (default: $PROTO_SYNC_TOKEN)")
+	cmd.Flags().DurationVar(&config.Timeout, "timeout", 0, "Abort the whole run after this long, e.g. 10m; repositories finished by then keep their results (default: no limit)")
+	cmd.Flags().IntVar(&config.Concurrency, "concurrency", 1, "Number of repositories to download and sync in parallel")
+	cmd.Flags().BoolVar(&config.FailFast, "fail-fast", false, "Stop at the first repository that fails, interrupting any still running")
+	cmd.Flags().Bool("keep-going", true, "Process every repository even after failures (default)")
+	cmd.MarkFlagsMutuallyExclusive("fail-fast", "keep-going")
+	cmd.Flags().BoolVar(&config.SortRepos, "sort-repos", false, "Process repositories sorted by module path instead of go.mod order")
+	cmd.Flags().BoolVar(&c.output.printConfig, "print-config", false, "Print the resolved configuration and exit")
+	cmd.Flags().BoolVar(&c.output.describeChanges, "describe-changes", false, "Print a commit message describing the synced changes to stdout")
+	cmd.Flags().StringVar(&c.output.progressFile, "progress-file", "", "Stream newline-delimited JSON progress events to this file")
+	cmd.Flags().StringVar(&c.output.format, "output", outputText, "Summary format: text or json (json prints a single object to stdout)")
+	cmd.Flags().BoolVar(&c.output.jsonErrorsOnly, "json-errors-only", false, "Keep stdout empty on success and print a JSON array of failures otherwise")
+	cmd.Flags().BoolVar(&c.output.noProgress, "no-progress", false, "Disable the copy progress display shown when stderr is a terminal")
+	cmd.Flags().IntVar(&c.output.progressFD, "progress-fd", 0, "Stream newline-delimited JSON progress events to this open file descriptor")
+	cmd.Flags().StringVar(&c.output.describeTemplate, "describe-template", "", "Go text/template used by --describe-changes")
+	cmd.Flags().StringArrayVar(&config.Include, "include", nil, "Glob restricting synced files by path relative to the source, applied before --exclude (repeatable)")
+	cmd.Flags().StringArrayVar(&config.Exclude, "exclude", nil, "Glob of source files to skip, matched against the path relative to the source (repeatable)")
+	cmd.Flags().StringSliceVar(&config.FilePatterns, "pattern", nil, "Globs selecting files relative to the source path, comma-separated or repeated, e.g. '*.proto,*.proto3'; supports ** and !negation (default *.proto)")
+	cmd.Flags().StringArrayVar(&config.SourceRules, "source-rule", nil, "Version-conditional source path, e.g. '<2.0.0=api/v1' (repeatable, first match wins)")
+	cmd.Flags().StringVar(&config.FileCacheDir, "file-cache-dir", os.Getenv("PROTO_SYNC_FILE_CACHE_DIR"), "Directory caching proto files by module@version; cache hits skip the download")
+	cmd.Flags().StringVar(&config.StateFile, "state-file", "", "JSON file recording hashes of synced files, used to detect local edits")
+	cmd.Flags().BoolVar(&config.ProtectEdits, "protect-edits", false, "Fail instead of warning when a target file has local edits (requires --state-file)")
+	cmd.Flags().StringVar(&config.Transform, "transform", "", "Shell command each proto is piped through (stdin to stdout) before it is written; the file name is in $PROTO_SYNC_FILE")
+	cmd.Flags().StringVar(&config.Manifest, "manifest", "", "Write the synced repository versions and file SHA-256 hashes to this JSON file after a successful sync, e.g. proto-sync.manifest.json")
+	cmd.Flags().StringVar(&config.BeforeSync, "before-sync", "", "Shell command run before anything is downloaded, with the configuration in $PROTO_SYNC_* variables; its failure aborts the sync")
+	cmd.Flags().StringVar(&config.AfterSync, "after-sync", "", "Shell command run once every repository synced, with $PROTO_SYNC_TARGET and $PROTO_SYNC_REPOS set; its failure fails the run (skipped in dry-run)")
+	cmd.Flags().StringArrayVar(&config.MergeFiles, "merge-file", nil, "Shared file that must be identical across all synced repositories (repeatable)")
+	cmd.Flags().StringArrayVar(&config.Protect, "protect", nil, "Glob of target files that are never pruned (repeatable, buf config files are always protected)")
+	cmd.Flags().BoolVar(&config.CheckPackagePath, "check-package-path", false, "Warn when a copied proto's package does not match its directory under the target")
+	cmd.Flags().BoolVar(&config.Verify, "verify", false, "Check every copied file against its source by SHA-256")
+	cmd.Flags().BoolVar(&config.VerifyCount, "verify-count", false, "Re-list the source after copying and fail if files vanished or were missed")
+	cmd.Flags().BoolVar(&config.VerifySum, "verify-sum", false, "Check every downloaded module against the go.sum next to --go-mod")
+	cmd.Flags().BoolVar(&config.SourceReadonlyCheck, "source-readonly-check", false, "Fail if a resolved source path is outside GOMODCACHE")
+	var since string
+	cmd.Flags().StringVar(&since, "since", "", "Only copy source files modified after this time: RFC3339, a date (2006-01-02) or a duration ago such as 24h or 7d")
+
+	configFile := defaultConfigFile
+	cmd.Flags().StringVar(&configFile, "config", defaultConfigFile, "Config file providing defaults for flags (ignored when the default file is missing)")
+
+	// Handle config file and repository parsing after flags are parsed
 	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
-		if defaultRepo != "" {
-			repo := domain.Repository{
-				Name: defaultRepo,
-				URL:  fmt.Sprintf("https://%s", defaultRepo),
+		fileCfg, err := loadConfigFile(configFile, cmd.Flags().Changed("config"))
+		if err != nil {
+			return err
+		}
+		if fileCfg != nil {
+			applyConfigFile(cmd, fileCfg, config)
+		}
+
+		if err := applyRepoFlags(config, repoFlags, versionFlags); err != nil {
+			return usageError(err)
+		}
+		if config.DryRunDiff {
+			config.DryRun = true
+		}
+		if config.Token == "" {
+			config.Token = os.Getenv("PROTO_SYNC_TOKEN")
+		}
+		if since != "" {
+			if config.Since, err = parseSince(since, time.Now()); err != nil {
+				return usageError(err)
 			}
-			config.Repositories = []domain.Repository{repo}
 		}
+
+		c.writeLogBanner(cmd, config)
 		return nil
 	}
 }
 
-func (c *CLIHandler) createListVersionsCommand(config *domain.SyncConfig) *cobra.Command {
-	return &cobra.Command{
-		Use:   "list-versions",
-		Short: "List available versions for all repositories",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return c.handleListVersions(cmd.Context(), config)
-		},
+// applyRepoFlags turns every --repo into a repository to sync. A single
+// --version applies to all repositories; repeated ones pair up with --repo
+// by position. Versions given inline as module@version always win.
+func applyRepoFlags(config *domain.SyncConfig, repoFlags, versionFlags []string) error {
+	inline := false
+	if len(repoFlags) > 0 {
+		config.Repositories = nil
+		for _, value := range repoFlags {
+			repo, err := parseRepoFlag(value)
+			if err != nil {
+				return err
+			}
+			inline = inline || repo.Version != ""
+			config.Repositories = append(config.Repositories, repo)
+		}
+	}
+
+	switch {
+	case len(versionFlags) == 1 && !inline:
+		config.SpecifiedVersion = versionFlags[0]
+		return nil
+	case len(versionFlags) > 1 && len(versionFlags) != len(repoFlags):
+		return fmt.Errorf("--version was given %d times but --repo %d times; give --version once for every --repo, or once for all", len(versionFlags), len(repoFlags))
+	}
+
+	for i := range config.Repositories {
+		repo := &config.Repositories[i]
+		if repo.Version != "" || len(versionFlags) == 0 {
+			continue
+		}
+		repo.Version = versionFlags[0]
+		if len(versionFlags) > 1 {
+			repo.Version = versionFlags[i]
+		}
 	}
+
+	if (inline || len(versionFlags) > 1) && (config.Latest || config.LatestPatch) {
+		return fmt.Errorf("--latest and --latest-patch cannot be combined with --version or module@version")
+	}
+	return nil
+}
+
+// parseRepoFlag parses --repo NAME[@VERSION][,target=DIR]
+func parseRepoFlag(value string) (domain.Repository, error) {
+	module, options, _ := strings.Cut(value, ",")
+	if strings.Count(module, "@") > 1 {
+		return domain.Repository{}, fmt.Errorf("--repo %q has more than one @", value)
+	}
+	name, version, inline := strings.Cut(module, "@")
+	if name == "" {
+		return domain.Repository{}, fmt.Errorf("--repo %q has no repository name", value)
+	}
+	if inline && version == "" {
+		return domain.Repository{}, fmt.Errorf("--repo %q has no version after @", value)
+	}
+	repo := domain.Repository{
+		Name:    name,
+		Version: version,
+		URL:     fmt.Sprintf("https://%s", name),
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		if option == "" {
+			continue
+		}
+		key, dir, _ := strings.Cut(option, "=")
+		if key != "target" || dir == "" {
+			return domain.Repository{}, fmt.Errorf("invalid --repo option %q, expected target=DIR", option)
+		}
+		repo.TargetPath = dir
+	}
+	return repo, nil
 }
 
 func (c *CLIHandler) handleSync(ctx context.Context, config *domain.SyncConfig) error {
 	// Validate that required tools are available
-	if err := c.validateRequiredTools(); err != nil {
+	if err := c.validateRequiredTools(requiredTools(config)...); err != nil {
 		return err
 	}
 
+	if c.output.printConfig {
+		return c.printConfig(os.Stdout, config)
+	}
+
+	if err := validateOutputFormat(c.output.format); err != nil {
+		return usageError(err)
+	}
+	if c.output.format == outputJSON && (c.output.describeChanges || config.DryRun) {
+		return usageError(fmt.Errorf("--output json cannot be combined with --describe-changes or --dry-run, which also write to stdout"))
+	}
+	if c.output.jsonErrorsOnly && (c.output.format == outputJSON || c.output.describeChanges || config.DryRun) {
+		return usageError(fmt.Errorf("--json-errors-only cannot be combined with --output json, --describe-changes or --dry-run, which also write to stdout"))
+	}
+
+	var describeTmpl *template.Template
+	if c.output.describeChanges {
+		tmpl, err := parseDescribeTemplate(c.output.describeTemplate)
+		if err != nil {
+			return err
+		}
+		describeTmpl = tmpl
+	}
+
+	progress, closeProgress, err := c.openProgressReporter()
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		defer closeProgress()
+	}
+	config.Progress, config.ProgressBar = c.progressReporters(progress, config.DryRun)
+
+	ctx, cancel := withTimeout(ctx, config)
+	defer cancel()
+
+	// The hook must pass before anything is downloaded, and only runs for
+	// configurations Sync would accept
+	if config.BeforeSync != "" {
+		if err := c.service.ValidateConfig(config); err != nil {
+			return usageError(fmt.Errorf("invalid configuration: %w", err))
+		}
+		if err := c.service.RunBeforeSync(ctx, config); err != nil {
+			c.logger.Error("Sync aborted: %v", err)
+			return &ExitError{Code: ExitFailure, Err: err}
+		}
+	}
+
 	results, err := c.service.Sync(ctx, config)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		c.logger.Error("Sync stopped: %v", context.Cause(ctx))
+	}
 	if err != nil {
 		c.logger.Error("Sync failed: %v", err)
-		return err
+		if c.output.jsonErrorsOnly {
+			report := []errorReport{{Code: domain.CodeOf(err), Message: err.Error()}}
+			if writeErr := writeErrorReport(os.Stdout, report); writeErr != nil {
+				return writeErr
+			}
+		}
+		return &ExitError{Code: failureExitCode(err), Err: err}
 	}
 
 	if config.DryRun {
+		// Repositories that failed, e.g. a --dry-run-diff download, were
+		// never compared, so they can't pass as in sync
+		if err := syncError(results); err != nil {
+			return err
+		}
+		if domain.HasPendingChanges(results) {
+			c.logger.Info("Dry run found pending changes")
+			return &ExitError{Code: ExitChangesPending}
+		}
 		return nil
 	}
 
+	if describeTmpl != nil {
+		if err := writeChangeDescription(os.Stdout, describeTmpl, results); err != nil {
+			return err
+		}
+	}
+
+	if c.output.format == outputJSON {
+		if err := writeSyncReport(os.Stdout, results); err != nil {
+			return err
+		}
+		return syncError(results)
+	}
+
+	if c.output.jsonErrorsOnly {
+		if err := writeErrorReport(os.Stdout, buildErrorReport(results)); err != nil {
+			return err
+		}
+	}
+
 	// Print summary
 	successCount := 0
 	for _, result := range results {
 		if result.Success {
 			successCount++
 		}
+		c.logger.Info("%s", formatTimings(result))
 	}
 
-	c.logger.Info("Sync completed: %d/%d repositories processed successfully", successCount, len(results))
-	return nil
+	c.logger.Info("Sync completed: %d/%d repositories processed successfully, %s synced", successCount, len(results), formatBytes(domain.TotalBytes(results)))
+	return syncError(results)
 }
 
-func (c *CLIHandler) handleListVersions(ctx context.Context, config *domain.SyncConfig) error {
-	if err := c.validateRequiredTools(); err != nil {
-		return err
-	}
-
-	// Get repositories from go.mod if not specified
-	repositories := config.Repositories
-	if len(repositories) == 0 {
-		if err := c.service.ValidateConfig(config); err != nil {
-			return fmt.Errorf("invalid configuration: %w", err)
-		}
+// formatTimings describes how long each phase of a repository's sync took
+func formatTimings(result domain.SyncResult) string {
+	timings := result.Timings
+	return fmt.Sprintf("%s@%s took %s (download %s, copy %s)", result.Repository.Name, result.Repository.Version,
+		timings.Total.Round(time.Millisecond), timings.Download.Round(time.Millisecond), timings.Copy.Round(time.Millisecond))
+}
 
-		// We need to parse go.mod to get repositories
-		// For now, we'll return an error asking user to specify repositories
-		return fmt.Errorf("no repositories specified. Use --repo flag or ensure go.mod has '// Protobuf libraries' section")
+// withTimeout bounds ctx by --timeout. Repositories still running when it
+// expires are interrupted and those not started are reported as not
+// processed, while finished ones keep their results.
+func withTimeout(ctx context.Context, config *domain.SyncConfig) (context.Context, context.CancelFunc) {
+	if config.Timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeoutCause(ctx, config.Timeout, fmt.Errorf("--timeout of %s exceeded", config.Timeout))
+}
 
-	versions, err := c.service.ListVersions(ctx, repositories)
-	if err != nil {
-		return fmt.Errorf("failed to list versions: %w", err)
+// syncError turns failed repositories, a run that found nothing to sync
+// or a failed --lint into the command's error
+func syncError(results []domain.SyncResult) error {
+	if err := syncFailure(results); err != nil {
+		return err
 	}
-
-	// Print versions
-	for repo, versionList := range versions {
-		fmt.Printf("--- Versions for %s ---\n", repo)
-		for _, version := range versionList {
-			fmt.Println(version)
-		}
-		fmt.Println()
+	if nothingSynced(results) {
+		return &ExitError{Code: ExitNothingToSync, Err: fmt.Errorf("nothing to sync: no repository provided any proto file")}
 	}
+	return lintError(results)
+}
 
+// lintError fails the command when --lint found issues in synced protos,
+// which are left in place
+func lintError(results []domain.SyncResult) error {
+	if domain.LintFailed(results) {
+		return fmt.Errorf("buf lint failed for the synced protos")
+	}
 	return nil
 }
 
-func (c *CLIHandler) validateRequiredTools() error {
-	// Check if go is available
-	if !c.isCommandAvailable("go") {
-		return fmt.Errorf("go is required but not installed")
+// toolInstallHints tells users how to install each external tool
+var toolInstallHints = map[string]string{
+	"go":  "install Go from https://go.dev/dl/",
+	"buf": "install buf from https://buf.build/docs/installation",
+}
+
+// requiredTools returns the external binaries a run with config shells out
+// to: go for downloads, plus buf for features that invoke it
+func requiredTools(config *domain.SyncConfig) []string {
+	tools := []string{"go"}
+	if (config.Generate || config.Lint) && !config.DryRun {
+		tools = append(tools, "buf")
+	}
+	return tools
+}
+
+// validateRequiredTools fails with an actionable message when any of tools
+// is not on PATH, so runs don't fail halfway with a confusing exec error
+func (c *CLIHandler) validateRequiredTools(tools ...string) error {
+	for _, tool := range tools {
+		if !c.isCommandAvailable(tool) {
+			return fmt.Errorf("%s is required but was not found on PATH; %s", tool, toolInstallHints[tool])
+		}
 	}
 	return nil
 }
 
 func (c *CLIHandler) isCommandAvailable(command string) bool {
-	// This is a simple check - in a real implementation you might want to use exec.LookPath
-	return true // Assume tools are available for now
+	_, err := exec.LookPath(command)
+	return err == nil
 }
 
 func getEnvOrDefault(key, defaultValue string) string {
@@ -176,15 +477,105 @@ Automatically detects protobuf libraries from go.mod using // Protobuf libraries
 
 Options:
     -h, --help              Show this help message
-    -v, --version VERSION   Specify version to download (default: auto-detect from go.mod)
-    -r, --repo REPO         Repository name (default: auto-detect from go.mod)
+    -v, --version VERSION   Version to download, or a semver range resolved to the
+                           highest match, e.g. ^1.2.0 or '>=1.2, <2.0'
+                           (default: auto-detect from go.mod); repeat it once
+                           per --repo to pin each repository separately
+    -r, --repo REPO         Repository name (repeatable, default: auto-detect from
+                           go.mod); REPO@VERSION pins its version over --version
+                           and @latest picks the newest release; add ,target=DIR
+                           to sync it into DIR instead of buf.yaml's path
     -s, --source PATH       Source path in repository (default: schemas/api/v1)
     -b, --buf-yaml PATH     Path to buf.yaml file (default: buf.yaml)
     -g, --go-mod PATH       Path to go.mod file (default: ../go.mod)
-    -f, --proto-file FILE   Download only specific proto file (e.g., product_availability.proto)
-    -d, --dry-run          Show what would be done without executing
+    --source-of-truth SRC  Auto-detect repositories from gomod (default) or buf;
+                           buf reads buf.yaml and buf.lock deps and skips Buf
+                           Schema Registry modules, which aren't Go modules
+    --versions-file PATH   YAML map of module: version overriding go.mod versions
+    -f, --proto-file FILE   Download only specific proto file or glob (e.g., product_*.proto); repeatable
+    -d, --dry-run          Show what would be done without executing; exits 6
+                           when files would change or be pruned, and for modules
+                           not in the module cache, whose files dry-run can't
+                           compare without downloading (see --dry-run-diff)
+    --dry-run-diff         Dry run that downloads missing modules and marks each
+                           file as new, modified or identical
     --list-versions        List available versions for all repos and exit
     --single-repo          Process only the first repository found
+    --lint                 Run buf lint on the targets after syncing
+    --generate             Run buf generate next to buf.yaml after a successful sync
+    --prune                Delete orphaned target protos no repository provides
+    --backup               Back up replaced files to <target>/.proto-sync-backup/<timestamp>/
+    --atomic               Stage targets and swap them in only when every repository synced
+    --force                Rewrite targets that are already identical (skipped by default)
+    --default-module-path DIR
+                           Target for buf v1 files without modules (default: buf.yaml dir;
+                           required with --prune and clean)
+    --module NAME          buf.yaml module (name or path) to sync into when there are several
+    --retries N            Retry failed module downloads N times (default 2)
+    --retry-delay D        Wait before the first retry, doubled each time (default 1s)
+    --netrc FILE           Credentials for private module proxies and hosts
+    --token TOKEN          Token (or USER:TOKEN) for git over HTTPS to private
+                           module hosts; prefer $PROTO_SYNC_TOKEN, it is never logged.
+                           With either, the module is added to GOPRIVATE and GONOSUMDB
+    --timeout D            Abort the whole run after D, e.g. 10m (default: no limit);
+                           repositories finished by then keep their results
+    --concurrency N        Process N repositories in parallel (default 1)
+    --fail-fast            Stop at the first failed repository; with --concurrency
+                           repositories already running are interrupted and the
+                           rest are reported as not processed
+    --keep-going           Process every repository even after failures (default)
+    --sort-repos           Process repositories sorted by module path
+    --target DIR           Copy protos into DIR instead of the buf.yaml path (repeatable);
+                           {version}, {repo} and {repoBase} expand per repository
+    --latest               Sync the newest version of each repository, ignoring go.mod
+    --stable-only          With --latest, pick the newest release, skipping
+                           pre-releases and pseudo-versions
+    --latest-patch         Use the newest patch release of each go.mod major.minor
+    --recursive            Preserve source subdirectories under the target
+    --dereference-symlinks Follow symlinks in the source; cycles abort the sync
+    --config FILE          Config file with flag defaults (default proto-sync.yaml)
+    --print-config         Print the resolved configuration and exit
+    --describe-changes     Print a commit message describing the synced changes
+    --describe-template T  Go text/template used by --describe-changes
+    --work-dir DIR         Resolve relative paths (go.mod, buf.yaml, targets, config
+                           and log files) against DIR instead of the current directory
+    --log-file PATH        Also append uncolored logs to PATH
+    --log-level LEVEL      Minimum log level: debug, info (default), warn, error, silent
+    --since TIME           Only copy files modified after TIME: RFC3339, a date
+                           (2006-01-02) or a duration ago (24h, 7d, 2w, 1d12h);
+                           orphan detection is skipped
+    -q, --quiet            Only log errors, including dry-run previews and the
+                           summary; same as --log-level error
+    --log-format FORMAT    Log format: text (default, colored) or json
+    --output FORMAT        Summary format: text (default) or json
+    --json-errors-only     Print only a JSON array of failures to stdout
+    --progress-file PATH   Stream newline-delimited JSON progress events to PATH
+    --progress-fd N        Stream newline-delimited JSON progress events to fd N
+    --no-progress          Don't show the copy progress display on terminals
+    --pattern GLOB         Select files by glob, e.g. 'v1/**/*.proto' or '!**/internal/**';
+                           separate several with commas: '*.proto,*.proto3'
+    --include GLOB         Only sync source files matching glob, e.g. 'api/**'
+    --exclude GLOB         Skip source files matching glob, e.g. '**/internal/*.proto'
+    --source-rule RULE     Version-conditional source path, e.g. '>=2.0.0=schemas/api/v1'
+    --file-cache-dir DIR   Cache proto files by module@version and reuse them
+    --state-file PATH      Record synced file hashes to detect local edits
+    --protect-edits        Refuse to overwrite locally edited files
+    --transform CMD        Pipe each proto through CMD before writing it
+    --manifest FILE        Record synced versions and file SHA-256 hashes in FILE
+                           (e.g. proto-sync.manifest.json) after a successful sync
+    --before-sync CMD      Run CMD before downloading anything, with the
+                           configuration in PROTO_SYNC_* variables; a failing
+                           CMD aborts the sync (dry-run only logs it)
+    --after-sync CMD       Run CMD once every repository synced, with
+                           PROTO_SYNC_TARGET and PROTO_SYNC_REPOS set; a failing
+                           CMD fails the run (skipped in dry-run)
+    --merge-file FILE      Fail if shared FILE differs between repositories (repeatable)
+    --protect GLOB         Never prune target files matching GLOB (repeatable)
+    --check-package-path   Warn when a proto's package doesn't match its target path
+    --verify               Check every copied file against its source by SHA-256
+    --verify-count         Fail if the source changed while files were copied
+    --verify-sum           Check downloaded modules against the go.sum next to go.mod
+    --source-readonly-check Fail if a resolved source path is outside GOMODCACHE
 
 Environment Variables:
     REPO_NAME              Repository name (overrides auto-detection)
@@ -192,14 +583,47 @@ Environment Variables:
     BUF_YAML_PATH          Path to buf.yaml file
     GO_MOD_PATH            Path to go.mod file
     PROTO_FILE_NAME        Specific proto file to download
+    VERSIONS_FILE          YAML file with module version overrides
+    PROTO_SYNC_FILE_CACHE_DIR  Directory caching proto files by module@version
+    LOG_FORMAT             Log format: text or json
+    LOG_LEVEL              Minimum log level: debug, info, warn, error or silent
+    LOG_FILE               File logs are also appended to
+
+Exit Codes:
+    0    Success
+    1    Failure
+    2    Invalid flags or configuration
+    3    Download failed, or a module or version was not found
+    4    Partial success: some repositories failed
+    5    Nothing to sync
+    6    --dry-run or diff found pending changes, or --dry-run could not
+         compare a module that isn't downloaded yet
+    7    check-updates found newer versions
+    8    verify-manifest found edited or deleted target files
+    9    A module has no proto files at the source path
+    10   Protos could not be written to a target
 
 Examples:
     proto-sync                                          # Auto-detect and download from go.mod
     proto-sync --version v0.12.0 --single-repo        # Download specific version of first repo
     proto-sync --repo github.com/my-org/my-api         # Use specific repository
+    proto-sync -r github.com/org/a -v v1.2.0 -r github.com/org/b -v v0.3.0 # Sync two pinned repositories
+    proto-sync -r github.com/org/a@v1.2.0 -r github.com/org/b@latest # Same, with inline versions
     proto-sync --proto-file product_availability.proto # Download only product_availability.proto
+    proto-sync -f orders.proto -f users.proto          # Download only orders.proto and users.proto
+    proto-sync --target 'proto/{version}'              # Keep each synced version in its own directory
     proto-sync --dry-run                               # Preview what would be done
-    proto-sync list-versions                           # List available versions for all repos`
+    proto-sync list-versions                           # List available versions for all repos
+    proto-sync list-versions --limit 5 --prerelease=false # Five newest releases of each repo
+    proto-sync cache info --file-cache-dir DIR         # Show size and contents of the file cache
+    proto-sync cache clean --file-cache-dir DIR        # Remove the file cache
+    proto-sync diff -r github.com/org/api -v v1.2.3    # Show content changes, exit 6 if any
+    proto-sync rollback                                # Restore the newest --backup over the target
+    proto-sync clean --dry-run                         # List the protos clean --yes would delete
+    proto-sync validate -r github.com/org/api -v v1.3.0 # Check the syntax of upstream protos
+    proto-sync check-updates                           # Compare go.mod versions with the newest, exit 7 if behind
+    proto-sync init --buf-yaml-create -r github.com/org/api@v1.2.0 # Scaffold proto-sync.yaml and buf.yaml
+    proto-sync verify-manifest                         # Check targets against proto-sync.manifest.json, exit 8 on drift`
 
 	fmt.Println(usage)
 }
@@ -0,0 +1,129 @@
+package interfaces
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequiredTools(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries require a POSIX system")
+	}
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "go"), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", binDir)
+
+	handler := &CLIHandler{}
+	assert.NoError(t, handler.validateRequiredTools("go"))
+
+	err := handler.validateRequiredTools("go", "buf")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "buf is required")
+	assert.Contains(t, err.Error(), "https://buf.build/docs/installation")
+}
+
+func TestRequiredToolsGenerate(t *testing.T) {
+	assert.Equal(t, []string{"go"}, requiredTools(&domain.SyncConfig{}))
+	assert.Equal(t, []string{"go", "buf"}, requiredTools(&domain.SyncConfig{Generate: true}))
+	assert.Equal(t, []string{"go"}, requiredTools(&domain.SyncConfig{Generate: true, DryRun: true}))
+}
+
+func TestParseRepoFlag(t *testing.T) {
+	repo, err := parseRepoFlag("github.com/example/api")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Repository{Name: "github.com/example/api", URL: "https://github.com/example/api"}, repo)
+
+	repo, err = parseRepoFlag("github.com/example/api,target=third_party/api")
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/example/api", repo.Name)
+	assert.Equal(t, "third_party/api", repo.TargetPath)
+
+	for _, value := range []string{",target=proto", "github.com/example/api,target=", "github.com/example/api,dir=proto"} {
+		_, err := parseRepoFlag(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestApplyRepoFlags(t *testing.T) {
+	config := &domain.SyncConfig{}
+	require.NoError(t, applyRepoFlags(config, []string{"github.com/example/a", "github.com/example/b"}, []string{"v1.2.0", "^0.3.0"}))
+	require.Len(t, config.Repositories, 2)
+	assert.Equal(t, "v1.2.0", config.Repositories[0].Version)
+	assert.Equal(t, "^0.3.0", config.Repositories[1].Version)
+	assert.Empty(t, config.SpecifiedVersion)
+
+	config = &domain.SyncConfig{}
+	require.NoError(t, applyRepoFlags(config, []string{"github.com/example/a", "github.com/example/b"}, []string{"v1.2.0"}))
+	assert.Equal(t, "v1.2.0", config.SpecifiedVersion)
+	assert.Empty(t, config.Repositories[1].Version)
+
+	err := applyRepoFlags(&domain.SyncConfig{}, []string{"github.com/example/a"}, []string{"v1.2.0", "v1.3.0"})
+	assert.ErrorContains(t, err, "--version was given 2 times but --repo 1 times")
+
+	err = applyRepoFlags(&domain.SyncConfig{Latest: true}, []string{"github.com/example/a", "github.com/example/b"}, []string{"v1.2.0", "v1.3.0"})
+	assert.ErrorContains(t, err, "cannot be combined with --version")
+}
+
+func TestParseRepoFlagInlineVersion(t *testing.T) {
+	repo, err := parseRepoFlag("github.com/example/api@v1.2.3,target=proto/api")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Repository{Name: "github.com/example/api", Version: "v1.2.3", URL: "https://github.com/example/api", TargetPath: "proto/api"}, repo)
+
+	_, err = parseRepoFlag("github.com/example/api@v1@v2")
+	assert.ErrorContains(t, err, "more than one @")
+	_, err = parseRepoFlag("github.com/example/api@")
+	assert.ErrorContains(t, err, "no version after @")
+}
+
+func TestApplyRepoFlagsInlineVersionWins(t *testing.T) {
+	config := &domain.SyncConfig{}
+	require.NoError(t, applyRepoFlags(config, []string{"github.com/example/a@v1.0.0", "github.com/example/b"}, []string{"v2.0.0"}))
+	assert.Empty(t, config.SpecifiedVersion)
+	assert.Equal(t, "v1.0.0", config.Repositories[0].Version)
+	assert.Equal(t, "v2.0.0", config.Repositories[1].Version)
+
+	config = &domain.SyncConfig{}
+	require.NoError(t, applyRepoFlags(config, []string{"github.com/example/a@latest", "github.com/example/b"}, []string{"v1.0.0", "v2.0.0"}))
+	assert.Equal(t, "latest", config.Repositories[0].Version)
+	assert.Equal(t, "v2.0.0", config.Repositories[1].Version)
+
+	err := applyRepoFlags(&domain.SyncConfig{LatestPatch: true}, []string{"github.com/example/a@v1.0.0"}, nil)
+	assert.ErrorContains(t, err, "cannot be combined with --version or module@version")
+}
+
+func TestPatternFlagSplitsCommas(t *testing.T) {
+	var config domain.SyncConfig
+	cmd := &cobra.Command{}
+	(&CLIHandler{}).addFlags(cmd, &config)
+
+	require.NoError(t, cmd.ParseFlags([]string{"--pattern", "*.proto,*.proto3", "--pattern", "!**/internal/**"}))
+	assert.Equal(t, []string{"*.proto", "*.proto3", "!**/internal/**"}, config.FilePatterns)
+}
+
+func TestTokenFromEnvironment(t *testing.T) {
+	t.Setenv("PROTO_SYNC_TOKEN", "s3cr3t")
+	var config domain.SyncConfig
+	cmd := &cobra.Command{}
+	(&CLIHandler{}).addFlags(cmd, &config)
+
+	// Help output must not reveal the token
+	assert.NotContains(t, cmd.Flags().FlagUsages(), "s3cr3t")
+
+	require.NoError(t, cmd.ParseFlags(nil))
+	require.NoError(t, cmd.PreRunE(cmd, nil))
+	assert.Equal(t, "s3cr3t", config.Token)
+}
+
+func TestRedactArgs(t *testing.T) {
+	args := []string{"proto-sync", "--token", "s3cr3t", "-r", "github.com/org/api", "--token=0th3r"}
+	assert.Equal(t, []string{"proto-sync", "--token", "[REDACTED]", "-r", "github.com/org/api", "--token=[REDACTED]"}, redactArgs(args))
+	assert.Equal(t, "s3cr3t", args[2])
+}
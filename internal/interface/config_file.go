@@ -0,0 +1,100 @@
+package interfaces
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is loaded from the working directory when present
+const defaultConfigFile = "proto-sync.yaml"
+
+// fileConfig is the content of a proto-sync.yaml file
+type fileConfig struct {
+	SourcePath   string           `yaml:"sourcePath,omitempty"`
+	TargetPath   string           `yaml:"targetPath,omitempty"`
+	GoModPath    string           `yaml:"goModPath,omitempty"`
+	BufYamlPath  string           `yaml:"bufYamlPath,omitempty"`
+	Repositories []fileRepository `yaml:"repositories,omitempty"`
+	Exclude      []string         `yaml:"exclude,omitempty"`
+}
+
+// fileRepository lists a repository in proto-sync.yaml; Version may be left
+// empty when --version is given, and SourcePath and TargetPath override the
+// global source and target paths for this repository
+type fileRepository struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version,omitempty"`
+	SourcePath string `yaml:"sourcePath,omitempty"`
+	TargetPath string `yaml:"targetPath,omitempty"`
+}
+
+// loadConfigFile reads path into a fileConfig. A missing file is only an
+// error when it was requested explicitly.
+func loadConfigFile(path string, explicit bool) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var cfg fileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for i, repo := range cfg.Repositories {
+		if repo.Name == "" {
+			return nil, fmt.Errorf("invalid config file %s: repositories[%d] has no name", path, i)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// applyConfigFile copies file values into config for every setting not
+// given explicitly by a flag or its environment variable
+func applyConfigFile(cmd *cobra.Command, cfg *fileConfig, config *domain.SyncConfig) {
+	unset := func(flag, env string) bool {
+		return !cmd.Flags().Changed(flag) && (env == "" || os.Getenv(env) == "")
+	}
+
+	if cfg.SourcePath != "" && unset("source", "SOURCE_PATH_IN_REPO") {
+		config.SourcePath = cfg.SourcePath
+	}
+	if cfg.TargetPath != "" && unset("target", "") {
+		config.Targets = []string{cfg.TargetPath}
+	}
+	if cfg.GoModPath != "" && unset("go-mod", "GO_MOD_PATH") {
+		config.GoModPath = cfg.GoModPath
+	}
+	if cfg.BufYamlPath != "" && unset("buf-yaml", "BUF_YAML_PATH") {
+		config.BufYamlPath = cfg.BufYamlPath
+	}
+	if len(cfg.Exclude) > 0 && unset("exclude", "") {
+		config.Exclude = cfg.Exclude
+	}
+	if len(cfg.Repositories) > 0 && unset("repo", "REPO_NAME") {
+		config.Repositories = nil
+		for _, repo := range cfg.Repositories {
+			config.Repositories = append(config.Repositories, domain.Repository{
+				Name:       repo.Name,
+				Version:    repo.Version,
+				URL:        fmt.Sprintf("https://%s", repo.Name),
+				SourcePath: repo.SourcePath,
+				TargetPath: repo.TargetPath,
+			})
+		}
+	}
+}
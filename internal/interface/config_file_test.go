@@ -0,0 +1,84 @@
+package interfaces
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "proto-sync.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `sourcePath: api/v2
+targetPath: third_party/proto
+repositories:
+  - name: github.com/example/api
+    version: v1.2.0
+  - name: github.com/example/users
+    sourcePath: proto
+exclude:
+  - "*_test.proto"
+`)
+
+	cfg, err := loadConfigFile(path, true)
+	require.NoError(t, err)
+	assert.Equal(t, "api/v2", cfg.SourcePath)
+	assert.Equal(t, []fileRepository{
+		{Name: "github.com/example/api", Version: "v1.2.0"},
+		{Name: "github.com/example/users", SourcePath: "proto"},
+	}, cfg.Repositories)
+
+	missing, err := loadConfigFile(filepath.Join(t.TempDir(), "proto-sync.yaml"), false)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	_, err = loadConfigFile(filepath.Join(t.TempDir(), "other.yaml"), true)
+	assert.Error(t, err)
+}
+
+func TestLoadConfigFileMalformed(t *testing.T) {
+	_, err := loadConfigFile(writeConfigFile(t, "sourcePath: [unterminated\n"), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse config file")
+
+	_, err = loadConfigFile(writeConfigFile(t, "sourcePth: typo\n"), false)
+	assert.Error(t, err)
+}
+
+func TestApplyConfigFileFlagsWin(t *testing.T) {
+	var config domain.SyncConfig
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&config.SourcePath, "source", "schemas/api/v1", "")
+	cmd.Flags().StringVar(&config.GoModPath, "go-mod", "../go.mod", "")
+	require.NoError(t, cmd.Flags().Parse([]string{"--source", "from/flag"}))
+
+	applyConfigFile(cmd, &fileConfig{SourcePath: "from/file", GoModPath: "go.mod"}, &config)
+
+	assert.Equal(t, "from/flag", config.SourcePath)
+	assert.Equal(t, "go.mod", config.GoModPath)
+}
+
+func TestApplyConfigFileRepositorySourcePath(t *testing.T) {
+	var config domain.SyncConfig
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&config.SourcePath, "source", "schemas/api/v1", "")
+
+	applyConfigFile(cmd, &fileConfig{Repositories: []fileRepository{
+		{Name: "github.com/example/api", Version: "v1.2.0"},
+		{Name: "github.com/example/users", SourcePath: "proto"},
+	}}, &config)
+
+	require.Len(t, config.Repositories, 2)
+	assert.Empty(t, config.Repositories[0].SourcePath)
+	assert.Equal(t, "proto", config.Repositories[1].SourcePath)
+}
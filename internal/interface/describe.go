@@ -0,0 +1,101 @@
+package interfaces
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// defaultDescribeTemplate renders a commit message suitable for `git commit -F -`,
+// listing only the repositories that changed a file
+const defaultDescribeTemplate = `Sync proto files
+{{range .Repositories}}{{if or .Added .Modified .Deleted}}
+{{.Name}}@{{.Version}}: {{len .Added}} added, {{len .Modified}} modified, {{len .Deleted}} deleted
+{{- range .Added}}
+  A {{.}}
+{{- end}}
+{{- range .Modified}}
+  M {{.}}
+{{- end}}
+{{- range .Deleted}}
+  D {{.}}
+{{- end}}
+{{end}}{{end}}`
+
+// changeDescription is the data passed to the describe-changes template
+type changeDescription struct {
+	Repositories []repositoryChanges
+	Added        int
+	Modified     int
+	Deleted      int
+	Unchanged    int
+}
+
+// repositoryChanges lists the changed files synced from one repository.
+// Deleted holds the orphans --prune removed from its targets.
+type repositoryChanges struct {
+	Name      string
+	Version   string
+	Added     []string
+	Modified  []string
+	Deleted   []string
+	Unchanged []string
+}
+
+func parseDescribeTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultDescribeTemplate
+	}
+
+	tmpl, err := template.New("describe-changes").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid describe template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func buildChangeDescription(results []domain.SyncResult) changeDescription {
+	var desc changeDescription
+
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+
+		repo := repositoryChanges{
+			Name:    result.Repository.Name,
+			Version: result.Repository.Version,
+		}
+		for _, file := range result.FilesUpdated {
+			switch file.Change {
+			case domain.ChangeAdded:
+				repo.Added = append(repo.Added, file.Name)
+			case domain.ChangeModified:
+				repo.Modified = append(repo.Modified, file.Name)
+			default:
+				repo.Unchanged = append(repo.Unchanged, file.Name)
+			}
+		}
+
+		for _, file := range result.PrunedFiles {
+			repo.Deleted = append(repo.Deleted, file.Name)
+		}
+
+		desc.Added += len(repo.Added)
+		desc.Modified += len(repo.Modified)
+		desc.Deleted += len(repo.Deleted)
+		desc.Unchanged += len(repo.Unchanged)
+		desc.Repositories = append(desc.Repositories, repo)
+	}
+
+	return desc
+}
+
+func writeChangeDescription(w io.Writer, tmpl *template.Template, results []domain.SyncResult) error {
+	if err := tmpl.Execute(w, buildChangeDescription(results)); err != nil {
+		return fmt.Errorf("failed to render change description: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package interfaces
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteChangeDescription(t *testing.T) {
+	results := []domain.SyncResult{
+		{
+			Repository: domain.Repository{Name: "github.com/example/api", Version: "v1.2.0"},
+			FilesUpdated: []domain.ProtoFile{
+				{Name: "new.proto", Change: domain.ChangeAdded},
+				{Name: "order.proto", Change: domain.ChangeModified},
+				{Name: "same.proto", Change: domain.ChangeUnchanged},
+			},
+			PrunedFiles: []domain.ProtoFile{{Name: "old.proto"}},
+			Success:     true,
+		},
+		{
+			Repository:   domain.Repository{Name: "github.com/example/users", Version: "v0.3.0"},
+			FilesUpdated: []domain.ProtoFile{{Name: "users.proto", Change: domain.ChangeUnchanged}},
+			Success:      true,
+		},
+		{
+			Repository: domain.Repository{Name: "github.com/example/broken", Version: "v0.1.0"},
+			Error:      errors.New("download failed"),
+		},
+	}
+
+	tmpl, err := parseDescribeTemplate("")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeChangeDescription(&buf, tmpl, results))
+
+	expected := "Sync proto files\n" +
+		"\n" +
+		"github.com/example/api@v1.2.0: 1 added, 1 modified, 1 deleted\n" +
+		"  A new.proto\n" +
+		"  M order.proto\n" +
+		"  D old.proto\n"
+	assert.Equal(t, expected, buf.String())
+
+	desc := buildChangeDescription(results)
+	assert.Len(t, desc.Repositories, 2)
+	assert.Equal(t, 1, desc.Deleted)
+	assert.Equal(t, 2, desc.Unchanged)
+}
+
+func TestParseDescribeTemplateInvalid(t *testing.T) {
+	_, err := parseDescribeTemplate("{{.Repositories")
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (c *CLIHandler) createDiffCommand() *cobra.Command {
+	var config domain.SyncConfig
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show how syncing would change the target protos; exits 6 when anything differs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return c.handleDiff(cmd.Context(), &config)
+		},
+	}
+	c.addFlags(cmd, &config)
+
+	return cmd
+}
+
+func (c *CLIHandler) handleDiff(ctx context.Context, config *domain.SyncConfig) error {
+	if err := c.validateRequiredTools(requiredTools(config)...); err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, config)
+	defer cancel()
+
+	diffs, err := c.service.Diff(ctx, config)
+	if err != nil {
+		c.logger.Error("Diff failed: %v", err)
+		return err
+	}
+
+	changed := writeFileDiffs(os.Stdout, diffs)
+	c.logger.Info("%d of %d file(s) would change", changed, len(diffs))
+
+	if changed > 0 {
+		return &ExitError{Code: ExitChangesPending}
+	}
+	return nil
+}
+
+// writeFileDiffs prints a status line per file followed by the unified diff
+// of each changed file, returning the number of changed files
+func writeFileDiffs(w io.Writer, diffs []domain.FileDiff) int {
+	changed := 0
+	for _, diff := range diffs {
+		fmt.Fprintf(w, "%s: %s\n", diff.Change, diff.Path)
+		if diff.Change == domain.ChangeUnchanged {
+			continue
+		}
+		changed++
+		fmt.Fprint(w, diff.Diff)
+	}
+	return changed
+}
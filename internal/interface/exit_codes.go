@@ -0,0 +1,138 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// Process exit codes returned by proto-sync. They are part of the CLI's
+// interface and must not be renumbered.
+const (
+	ExitOK = 0
+	// ExitFailure is returned for failures without a more specific code
+	ExitFailure = 1
+	// ExitUsage is returned for invalid flags or configuration
+	ExitUsage = 2
+	// ExitDownloadFailed is returned when modules could not be downloaded,
+	// including modules or versions that don't exist
+	ExitDownloadFailed = 3
+	// ExitPartialFailure is returned when some repositories synced and
+	// others failed
+	ExitPartialFailure = 4
+	// ExitNothingToSync is returned when no repository or proto file was
+	// found to sync
+	ExitNothingToSync = 5
+	// ExitChangesPending is returned by --dry-run and diff when files would
+	// be created or modified
+	ExitChangesPending = 6
+	// ExitUpdatesAvailable is returned by check-updates when a repository
+	// is behind its newest version
+	ExitUpdatesAvailable = 7
+	// ExitManifestDrift is returned by verify-manifest when target files
+	// were edited or deleted since the manifest was written
+	ExitManifestDrift = 8
+	// ExitSourceDirMissing is returned when a module lacks the source path
+	ExitSourceDirMissing = 9
+	// ExitCopyFailed is returned when protos could not be written to a target
+	ExitCopyFailed = 10
+)
+
+// ExitError carries a specific exit code out of a command. A nil Err means
+// the code is the whole message and nothing should be printed.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode maps an error returned by the root command to a process exit code
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
+
+// failureExitCode picks the exit code for a failure from the typed errors
+// of the domain package
+func failureExitCode(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidConfig):
+		return ExitUsage
+	case errors.Is(err, domain.ErrModuleNotFound), errors.Is(err, domain.ErrDownloadFailed):
+		return ExitDownloadFailed
+	case errors.Is(err, domain.ErrSourceDirMissing):
+		return ExitSourceDirMissing
+	case errors.Is(err, domain.ErrCopyFailed):
+		return ExitCopyFailed
+	default:
+		return ExitFailure
+	}
+}
+
+// usageError marks err as a usage error
+func usageError(err error) error {
+	return &ExitError{Code: ExitUsage, Err: err}
+}
+
+// syncFailure reports failed repositories in results as an ExitError, or
+// nil when every repository synced. Runs where some repositories synced are
+// partial failures; otherwise the first error picks the code.
+func syncFailure(results []domain.SyncResult) error {
+	failed := 0
+	var first error
+	for _, result := range results {
+		if result.Error == nil {
+			continue
+		}
+		failed++
+		if first == nil {
+			first = fmt.Errorf("%s: %w", result.Repository.Name, result.Error)
+		}
+	}
+	if first == nil {
+		return nil
+	}
+
+	code := failureExitCode(first)
+	if failed < len(results) {
+		code = ExitPartialFailure
+	}
+	return &ExitError{
+		Code: code,
+		Err:  fmt.Errorf("%d of %d repositories failed to sync, first: %w", failed, len(results), first),
+	}
+}
+
+// nothingSynced reports whether results contain no repository or no file
+func nothingSynced(results []domain.SyncResult) bool {
+	for _, result := range results {
+		if len(result.FilesUpdated) > 0 || result.ChangesUnknown {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSilentExit reports whether err only carries an exit code
+func IsSilentExit(err error) bool {
+	var exitErr *ExitError
+	return errors.As(err, &exitErr) && exitErr.Err == nil
+}
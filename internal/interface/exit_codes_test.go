@@ -0,0 +1,53 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{domain.WithCode(domain.ErrorCodeInvalidConfig, errors.New("invalid configuration: bad pattern")), ExitUsage},
+		{domain.WithCode(domain.ErrorCodeModuleNotFound, errors.New("unknown revision v9")), ExitDownloadFailed},
+		{fmt.Errorf("sync: %w", domain.WithCode(domain.ErrorCodeDownloadFailed, errors.New("connection reset"))), ExitDownloadFailed},
+		{fmt.Errorf("sync: %w", domain.WithCode(domain.ErrorCodeSourceNotFound, errors.New("source directory not found: proto"))), ExitSourceDirMissing},
+		{domain.WithCode(domain.ErrorCodeCopyFailed, errors.New("disk full")), ExitCopyFailed},
+		{domain.WithCode(domain.ErrorCodeLocalEdits, errors.New("edited")), ExitFailure},
+		{errors.New("boom"), ExitFailure},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, failureExitCode(tt.err), tt.err.Error())
+	}
+}
+
+func TestSyncFailure(t *testing.T) {
+	assert.NoError(t, syncFailure([]domain.SyncResult{{Success: true}}))
+
+	downloadFailed := domain.SyncResult{Repository: domain.Repository{Name: "github.com/example/users"}, Error: domain.WithCode(domain.ErrorCodeDownloadFailed, errors.New("failed to download module: timeout"))}
+	copyFailed := domain.SyncResult{Repository: domain.Repository{Name: "github.com/example/orders"}, Error: domain.WithCode(domain.ErrorCodeCopyFailed, errors.New("disk full"))}
+	synced := domain.SyncResult{Repository: domain.Repository{Name: "github.com/example/api"}, Success: true}
+
+	err := syncFailure([]domain.SyncResult{synced, downloadFailed, copyFailed})
+	require.Error(t, err)
+	assert.Equal(t, ExitPartialFailure, ExitCode(err))
+	assert.EqualError(t, err, "2 of 3 repositories failed to sync, first: github.com/example/users: failed to download module: timeout")
+
+	assert.Equal(t, ExitDownloadFailed, ExitCode(syncFailure([]domain.SyncResult{downloadFailed, copyFailed})))
+	assert.Equal(t, ExitCopyFailed, ExitCode(syncFailure([]domain.SyncResult{copyFailed})))
+}
+
+func TestSyncError(t *testing.T) {
+	assert.Equal(t, ExitNothingToSync, ExitCode(syncError(nil)))
+	assert.Equal(t, ExitNothingToSync, ExitCode(syncError([]domain.SyncResult{{Success: true}})))
+	assert.NoError(t, syncError([]domain.SyncResult{{Success: true, FilesUpdated: []domain.ProtoFile{{Name: "a.proto"}}}}))
+	// A dry run that couldn't compare a module doesn't know it's empty
+	assert.NoError(t, syncError([]domain.SyncResult{{Success: true, ChangesUnknown: true}}))
+}
@@ -0,0 +1,160 @@
+package interfaces
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// initOptions holds the init flags
+type initOptions struct {
+	configPath  string
+	sourcePath  string
+	targetPath  string
+	goModPath   string
+	bufYamlPath string
+	modulePath  string
+	repos       []string
+	bufYaml     bool
+	interactive bool
+	force       bool
+}
+
+func (c *CLIHandler) createInitCommand() *cobra.Command {
+	var opts initOptions
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create a starter proto-sync.yaml and buf.yaml and mark go.mod",
+		Long: `Create a starter proto-sync.yaml, add the '// Protobuf libraries' marker to
+go.mod when it has none, and with --buf-yaml-create write a minimal buf.yaml.
+Existing config and buf.yaml files are only replaced with --force.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if opts.interactive {
+				if err := promptInit(os.Stdin, os.Stderr, &opts); err != nil {
+					return err
+				}
+			}
+			return c.handleInit(cmd.Context(), opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.configPath, "config", defaultConfigFile, "Config file to create")
+	cmd.Flags().StringVarP(&opts.sourcePath, "source", "s", getEnvOrDefault("SOURCE_PATH_IN_REPO", "schemas/api/v1"), "Source path in repository")
+	cmd.Flags().StringVar(&opts.targetPath, "target", "", "Target directory for the config file (default: the buf.yaml module path)")
+	cmd.Flags().StringVarP(&opts.goModPath, "go-mod", "g", getEnvOrDefault("GO_MOD_PATH", "../go.mod"), "Path to go.mod file")
+	cmd.Flags().StringVarP(&opts.bufYamlPath, "buf-yaml", "b", getEnvOrDefault("BUF_YAML_PATH", "buf.yaml"), "Path to buf.yaml file")
+	cmd.Flags().BoolVar(&opts.bufYaml, "buf-yaml-create", false, "Also create a minimal buf.yaml declaring --module-path")
+	cmd.Flags().StringVar(&opts.modulePath, "module-path", "proto", "Module path declared in the created buf.yaml")
+	cmd.Flags().StringArrayVarP(&opts.repos, "repo", "r", nil, "Repository to list in the config file, as NAME or NAME@VERSION (repeatable)")
+	cmd.Flags().BoolVarP(&opts.interactive, "interactive", "i", false, "Prompt for each setting, offering the flag values as defaults")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Overwrite an existing config file and buf.yaml")
+
+	return cmd
+}
+
+func (c *CLIHandler) handleInit(ctx context.Context, opts initOptions) error {
+	cfg := fileConfig{
+		SourcePath:  opts.sourcePath,
+		TargetPath:  opts.targetPath,
+		GoModPath:   opts.goModPath,
+		BufYamlPath: opts.bufYamlPath,
+	}
+	for _, value := range opts.repos {
+		repo, err := parseRepoFlag(value)
+		if err != nil {
+			return usageError(err)
+		}
+		cfg.Repositories = append(cfg.Repositories, fileRepository{Name: repo.Name, Version: repo.Version, TargetPath: repo.TargetPath})
+	}
+
+	data, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return fmt.Errorf("failed to render config file: %w", err)
+	}
+
+	initOpts := &domain.InitOptions{
+		ConfigPath: opts.configPath,
+		Config:     append([]byte("# Defaults for proto-sync flags; flags and environment variables win\n"), data...),
+		GoModPath:  opts.goModPath,
+		ModulePath: opts.modulePath,
+		Force:      opts.force,
+	}
+	if opts.bufYaml {
+		initOpts.BufYamlPath = opts.bufYamlPath
+	}
+
+	results, err := c.service.Init(ctx, initOpts)
+	for _, result := range results {
+		switch result.Action {
+		case "missing":
+			c.logger.Warning("%s not found; run go mod init, then add '// Protobuf libraries' above the proto library requires", result.Path)
+		case "updated":
+			c.logger.Success("Added '// Protobuf libraries' to %s; list the proto library requires right below it", result.Path)
+		default:
+			c.logger.Success("%s %s", strings.ToUpper(result.Action[:1])+result.Action[1:], result.Path)
+		}
+	}
+	return err
+}
+
+// promptInit asks for each init setting on in, keeping the current value
+// when the answer is empty
+func promptInit(in io.Reader, out io.Writer, opts *initOptions) error {
+	scanner := bufio.NewScanner(in)
+	ask := func(question string, value *string) error {
+		fmt.Fprintf(out, "%s [%s]: ", question, *value)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("failed to read answer: %w", err)
+			}
+			return fmt.Errorf("init cancelled: no answer to %q", question)
+		}
+		if answer := strings.TrimSpace(scanner.Text()); answer != "" {
+			*value = answer
+		}
+		return nil
+	}
+
+	for _, prompt := range []struct {
+		question string
+		value    *string
+	}{
+		{"Source path in the proto repositories", &opts.sourcePath},
+		{"Path to go.mod", &opts.goModPath},
+		{"Path to buf.yaml", &opts.bufYamlPath},
+	} {
+		if err := ask(prompt.question, prompt.value); err != nil {
+			return err
+		}
+	}
+
+	createBuf := "n"
+	if opts.bufYaml {
+		createBuf = "y"
+	}
+	if err := ask("Create buf.yaml (y/n)", &createBuf); err != nil {
+		return err
+	}
+	opts.bufYaml = strings.HasPrefix(strings.ToLower(createBuf), "y")
+	if opts.bufYaml {
+		if err := ask("Module path in buf.yaml", &opts.modulePath); err != nil {
+			return err
+		}
+	}
+
+	repos := strings.Join(opts.repos, " ")
+	if err := ask("Repositories as NAME@VERSION, space-separated", &repos); err != nil {
+		return err
+	}
+	opts.repos = strings.Fields(repos)
+	return nil
+}
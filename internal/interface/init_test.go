@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptInit(t *testing.T) {
+	opts := initOptions{sourcePath: "schemas/api/v1", goModPath: "../go.mod", bufYamlPath: "buf.yaml", modulePath: "proto"}
+	answers := strings.Join([]string{"proto", "", "", "y", "api/proto", "github.com/org/a@v1.2.0 github.com/org/b"}, "\n") + "\n"
+
+	var out bytes.Buffer
+	require.NoError(t, promptInit(strings.NewReader(answers), &out, &opts))
+	assert.Equal(t, initOptions{
+		sourcePath:  "proto",
+		goModPath:   "../go.mod",
+		bufYamlPath: "buf.yaml",
+		modulePath:  "api/proto",
+		bufYaml:     true,
+		repos:       []string{"github.com/org/a@v1.2.0", "github.com/org/b"},
+	}, opts)
+	assert.Contains(t, out.String(), "Path to go.mod [../go.mod]: ")
+
+	// Running out of input cancels instead of guessing
+	err := promptInit(strings.NewReader("proto\n"), &out, &opts)
+	assert.ErrorContains(t, err, "init cancelled")
+}
@@ -0,0 +1,162 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+// listVersionsOptions controls which versions list-versions prints
+type listVersionsOptions struct {
+	// desc lists the newest version first
+	desc bool
+	// limit keeps only the newest semantic versions; <= 0 keeps all
+	limit int
+	// prerelease keeps versions such as v1.2.0-rc.1
+	prerelease bool
+	// stableOnly keeps only release tags, dropping pre-releases,
+	// pseudo-versions and tags that aren't semantic versions
+	stableOnly bool
+}
+
+func (c *CLIHandler) createListVersionsCommand(config *domain.SyncConfig) *cobra.Command {
+	var repoFlags []string
+	var opts listVersionsOptions
+	cmd := &cobra.Command{
+		Use:   "list-versions",
+		Short: "List available versions for all repositories",
+		Long:  "List available versions for the given repositories, or for the protobuf libraries listed in go.mod.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyRepoFlags(config, repoFlags, nil); err != nil {
+				return usageError(err)
+			}
+			return c.handleListVersions(cmd.Context(), config, opts)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&repoFlags, "repo", "r", nil, "Repository to list (repeatable, default: auto-detect from go.mod)")
+	cmd.Flags().StringVarP(&config.GoModPath, "go-mod", "g", getEnvOrDefault("GO_MOD_PATH", "../go.mod"), "Path to go.mod file")
+	cmd.Flags().StringVarP(&config.BufYamlPath, "buf-yaml", "b", getEnvOrDefault("BUF_YAML_PATH", "buf.yaml"), "Path to buf.yaml file, read with --source-of-truth buf")
+	cmd.Flags().StringVar(&config.SourceOfTruth, "source-of-truth", domain.SourceOfTruthGoMod, "Where to auto-detect repositories from: gomod or buf")
+	cmd.Flags().BoolVar(&opts.desc, "desc", false, "List the newest version first")
+	cmd.Flags().IntVar(&opts.limit, "limit", 0, "Print only the N newest semantic versions per repository (default: all)")
+	cmd.Flags().BoolVar(&opts.stableOnly, "stable-only", false, "Print only release tags, hiding pre-releases, pseudo-versions and other tags")
+	cmd.Flags().BoolVar(&opts.prerelease, "prerelease", true, "Include pre-releases such as -rc and -beta tags; --prerelease=false hides them")
+	return cmd
+}
+
+func (c *CLIHandler) handleListVersions(ctx context.Context, config *domain.SyncConfig, opts listVersionsOptions) error {
+	if err := c.validateRequiredTools(requiredTools(config)...); err != nil {
+		return err
+	}
+
+	// Get repositories from go.mod if not specified
+	repositories := config.Repositories
+	if len(repositories) == 0 {
+		detected, err := c.service.DetectRepositories(config)
+		if err != nil {
+			return err
+		}
+		if len(detected) == 0 {
+			return fmt.Errorf("no repositories specified. Use --repo flag or ensure %s has a '// Protobuf libraries' section", config.GoModPath)
+		}
+		repositories = detected
+	}
+
+	versions, err := c.service.ListVersions(ctx, repositories)
+	if err != nil {
+		return fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	for repo, versionList := range versions {
+		versions[repo] = selectVersions(versionList, opts)
+	}
+	// The versions are the command's result rather than log output, so they
+	// go to stdout for scripts regardless of --quiet
+	writeVersions(os.Stdout, repositories, versions)
+	return nil
+}
+
+// writeVersions prints the versions of each repository in repositories
+// order; repositories whose versions couldn't be listed are skipped
+func writeVersions(w io.Writer, repositories []domain.Repository, versions map[string][]string) {
+	for _, repo := range repositories {
+		versionList, ok := versions[repo.Name]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "--- Versions for %s ---\n", repo.Name)
+		for _, version := range versionList {
+			fmt.Fprintln(w, version)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// selectVersions sorts versions and applies the --prerelease and
+// --stable-only filters and --limit. Limiting keeps the newest semantic versions and drops other tags,
+// which have no order.
+func selectVersions(versions []string, opts listVersionsOptions) []string {
+	var kept []string
+	for _, version := range versions {
+		if !opts.prerelease && semver.Prerelease(version) != "" {
+			continue
+		}
+		if opts.stableOnly && !domain.IsStableVersion(version) {
+			continue
+		}
+		if opts.limit > 0 && !semver.IsValid(version) {
+			continue
+		}
+		kept = append(kept, version)
+	}
+
+	sorted := sortVersions(kept, opts.desc)
+	if opts.limit <= 0 || len(sorted) <= opts.limit {
+		return sorted
+	}
+	if opts.desc {
+		return sorted[:opts.limit]
+	}
+	return sorted[len(sorted)-opts.limit:]
+}
+
+// sortVersions returns versions without duplicates in semver order, oldest
+// first unless desc is set. Tags that aren't semantic versions follow in
+// lexical order.
+func sortVersions(versions []string, desc bool) []string {
+	seen := make(map[string]bool, len(versions))
+	var semvers, others []string
+	for _, version := range versions {
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if semver.IsValid(version) {
+			semvers = append(semvers, version)
+		} else {
+			others = append(others, version)
+		}
+	}
+
+	sort.Slice(semvers, func(i, j int) bool {
+		cmp := semver.Compare(semvers[i], semvers[j])
+		if cmp == 0 {
+			// v1.2 and v1.2.0 compare equal; keep their order stable
+			cmp = strings.Compare(semvers[i], semvers[j])
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	sort.Strings(others)
+
+	return append(semvers, others...)
+}
@@ -0,0 +1,47 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteVersions(t *testing.T) {
+	var buf bytes.Buffer
+	writeVersions(&buf, []domain.Repository{
+		{Name: "github.com/example/users"},
+		{Name: "github.com/example/broken"},
+		{Name: "github.com/example/api"},
+	}, map[string][]string{
+		"github.com/example/api":   {"v1.0.0"},
+		"github.com/example/users": {"v0.1.0", "v0.2.0"},
+	})
+
+	assert.Equal(t, "--- Versions for github.com/example/users ---\nv0.1.0\nv0.2.0\n\n--- Versions for github.com/example/api ---\nv1.0.0\n\n", buf.String())
+}
+
+func TestSortVersions(t *testing.T) {
+	shuffled := []string{"v1.10.0", "main", "v1.2.0", "v0.9.1", "v1.2.0", "v2.0.0-rc.1", "v1.10.0", "dev", "v2.0.0", "main"}
+
+	assert.Equal(t, []string{"v0.9.1", "v1.2.0", "v1.10.0", "v2.0.0-rc.1", "v2.0.0", "dev", "main"}, sortVersions(shuffled, false))
+	assert.Equal(t, []string{"v2.0.0", "v2.0.0-rc.1", "v1.10.0", "v1.2.0", "v0.9.1", "dev", "main"}, sortVersions(shuffled, true))
+	assert.Empty(t, sortVersions(nil, false))
+}
+
+func TestSelectVersions(t *testing.T) {
+	versions := []string{"v1.2.0", "v1.3.0-rc.1", "main", "v1.0.0", "v1.3.0-beta.2", "v1.1.0", "v1.2.0"}
+
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0-beta.2", "v1.3.0-rc.1", "main"}, selectVersions(versions, listVersionsOptions{prerelease: true}))
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0", "v1.2.0", "main"}, selectVersions(versions, listVersionsOptions{}))
+	assert.Equal(t, []string{"v1.3.0-beta.2", "v1.3.0-rc.1"}, selectVersions(versions, listVersionsOptions{limit: 2, prerelease: true}))
+	assert.Equal(t, []string{"v1.2.0", "v1.1.0"}, selectVersions(versions, listVersionsOptions{limit: 2, desc: true}))
+	assert.Equal(t, []string{"v1.0.0", "v1.1.0", "v1.2.0"}, selectVersions(versions, listVersionsOptions{limit: 10}))
+	assert.Len(t, selectVersions(versions, listVersionsOptions{limit: -1, prerelease: true}), 6)
+}
+
+func TestSelectVersionsStableOnly(t *testing.T) {
+	versions := []string{"v1.2.0", "v0.0.0-20230101000000-abcdef123456", "v1.3.0-rc1", "main", "v1.1.0"}
+	assert.Equal(t, []string{"v1.1.0", "v1.2.0"}, selectVersions(versions, listVersionsOptions{prerelease: true, stableOnly: true}))
+}
@@ -0,0 +1,49 @@
+package interfaces
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// SetLogFile makes the handler write a banner for each run to w, the file
+// main tees the logs into
+func (c *CLIHandler) SetLogFile(w io.Writer) {
+	c.logFile = w
+}
+
+// writeLogBanner delimits a run in the log file with its start time, the
+// command line and the resolved configuration
+func (c *CLIHandler) writeLogBanner(cmd *cobra.Command, config *domain.SyncConfig) {
+	if c.logFile == nil {
+		return
+	}
+
+	fmt.Fprintf(c.logFile, "\n=== %s run started %s ===\n", cmd.CommandPath(), time.Now().Format(time.RFC3339))
+	fmt.Fprintf(c.logFile, "command: %s\n", strings.Join(redactArgs(os.Args), " "))
+	if config != nil {
+		if err := c.printConfig(c.logFile, config); err != nil {
+			c.logger.Warning("Failed to write configuration to log file: %v", err)
+		}
+	}
+}
+
+// redactArgs hides the value of --token in a command line
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i, arg := range redacted {
+		switch {
+		case arg == "--token" && i+1 < len(redacted):
+			redacted[i+1] = "[REDACTED]"
+		case strings.HasPrefix(arg, "--token="):
+			redacted[i] = "--token=[REDACTED]"
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,83 @@
+package interfaces
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// Log formats accepted by --log-format and LOG_FORMAT
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// LogFormat returns the log format requested by --log-format in args or the
+// LOG_FORMAT environment variable. The logger is created before cobra parses
+// flags, so args are scanned directly; the flag is still registered on the
+// root command so cobra accepts it.
+func LogFormat(args []string) (string, error) {
+	format := scanFlag(args, "log-format", getEnvOrDefault("LOG_FORMAT", LogFormatText))
+
+	switch format {
+	case LogFormatText, LogFormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid log format %q: must be %s or %s", format, LogFormatText, LogFormatJSON)
+	}
+}
+
+// LogLevel returns the threshold requested by --log-level in args or the
+// LOG_LEVEL environment variable, scanned like LogFormat. --quiet lowers it
+// to errors only.
+func LogLevel(args []string) (domain.LogLevel, error) {
+	if scanBoolFlag(args, "quiet", "q") {
+		return domain.LogLevelError, nil
+	}
+	return domain.ParseLogLevel(scanFlag(args, "log-level", getEnvOrDefault("LOG_LEVEL", "info")))
+}
+
+// LogFile returns the path given by --log-file in args or the LOG_FILE
+// environment variable, or an empty string when logs only go to stderr
+func LogFile(args []string) string {
+	return scanFlag(args, "log-file", os.Getenv("LOG_FILE"))
+}
+
+// scanBoolFlag reports whether the boolean flag --name, or -short, is set
+// in args
+func scanBoolFlag(args []string, name, short string) bool {
+	set := false
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		switch {
+		case arg == "--"+name || arg == "-"+short:
+			set = true
+		case strings.HasPrefix(arg, "--"+name+"="):
+			set, _ = strconv.ParseBool(strings.TrimPrefix(arg, "--"+name+"="))
+		}
+	}
+	return set
+}
+
+// scanFlag returns the last value given for --name in args, or def
+func scanFlag(args []string, name, def string) string {
+	value := def
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if v, ok := strings.CutPrefix(arg, "--"+name+"="); ok {
+			value = v
+		} else if arg == "--"+name && i+1 < len(args) {
+			value = args[i+1]
+			i++
+		}
+	}
+	return value
+}
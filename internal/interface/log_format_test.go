@@ -0,0 +1,78 @@
+package interfaces
+
+import (
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFormat(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: nil, want: LogFormatText},
+		{args: []string{"--dry-run", "--log-format", "json"}, want: LogFormatJSON},
+		{args: []string{"diff", "--log-format=json"}, want: LogFormatJSON},
+		{args: []string{"--", "--log-format=json"}, want: LogFormatText},
+	}
+	for _, tt := range tests {
+		got, err := LogFormat(tt.args)
+		require.NoError(t, err, tt.args)
+		assert.Equal(t, tt.want, got, tt.args)
+	}
+
+	t.Setenv("LOG_FORMAT", "json")
+	got, err := LogFormat(nil)
+	require.NoError(t, err)
+	assert.Equal(t, LogFormatJSON, got)
+
+	got, err = LogFormat([]string{"--log-format", "text"})
+	require.NoError(t, err)
+	assert.Equal(t, LogFormatText, got)
+
+	_, err = LogFormat([]string{"--log-format", "xml"})
+	assert.Error(t, err)
+}
+
+func TestLogLevel(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+
+	level, err := LogLevel(nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LogLevelInfo, level)
+
+	level, err = LogLevel([]string{"--log-level", "silent"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.LogLevelSilent, level)
+
+	t.Setenv("LOG_LEVEL", "debug")
+	level, err = LogLevel([]string{"--dry-run"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.LogLevelDebug, level)
+
+	_, err = LogLevel([]string{"--log-level=verbose"})
+	assert.Error(t, err)
+}
+
+func TestLogLevelQuiet(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+
+	for _, args := range [][]string{{"--quiet"}, {"-q", "--dry-run"}, {"--quiet=true"}} {
+		level, err := LogLevel(args)
+		require.NoError(t, err)
+		assert.Equal(t, domain.LogLevelError, level, args)
+	}
+
+	level, err := LogLevel([]string{"--quiet=false"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.LogLevelDebug, level)
+
+	level, err = LogLevel([]string{"--", "-q"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.LogLevelDebug, level)
+}
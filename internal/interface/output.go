@@ -0,0 +1,172 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// Output formats accepted by --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// syncReport is the JSON document printed by --output json
+type syncReport struct {
+	Success bool `json:"success"`
+	// TotalBytes is the combined size of the files synced for every
+	// repository
+	TotalBytes   int64              `json:"total_bytes"`
+	Repositories []repositoryReport `json:"repositories"`
+}
+
+// repositoryReport is the serializable form of a domain.SyncResult
+type repositoryReport struct {
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	Success bool         `json:"success"`
+	Error   string       `json:"error,omitempty"`
+	Files   []fileReport `json:"files"`
+	// TotalBytes is the combined size of Files
+	TotalBytes int64 `json:"total_bytes"`
+	// Copied and Skipped count the Files written and those left alone
+	// because every target was already identical
+	Copied   int      `json:"copied"`
+	Skipped  int      `json:"skipped"`
+	Warnings []string `json:"warnings,omitempty"`
+	// Orphaned lists target files no repository provided in this sync
+	Orphaned []string `json:"orphaned,omitempty"`
+	// Pruned lists the orphaned files deleted by --prune
+	Pruned []string `json:"pruned,omitempty"`
+	// LintPassed is set when --lint ran on the repository's targets
+	LintPassed *bool         `json:"lint_passed,omitempty"`
+	Timings    timingsReport `json:"timings"`
+}
+
+// timingsReport is the serializable form of a domain.SyncTimings, in
+// milliseconds
+type timingsReport struct {
+	DownloadMs int64 `json:"download_ms"`
+	CopyMs     int64 `json:"copy_ms"`
+	TotalMs    int64 `json:"total_ms"`
+}
+
+// fileReport describes one synced proto file
+type fileReport struct {
+	Name    string            `json:"name"`
+	Path    string            `json:"path"`
+	Size    int64             `json:"size"`
+	Change  domain.ChangeType `json:"change,omitempty"`
+	Targets []string          `json:"targets,omitempty"`
+}
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputText, outputJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid --output %q: must be %s or %s", format, outputText, outputJSON)
+	}
+}
+
+func buildSyncReport(results []domain.SyncResult) syncReport {
+	report := syncReport{
+		Success:      true,
+		TotalBytes:   domain.TotalBytes(results),
+		Repositories: make([]repositoryReport, 0, len(results)),
+	}
+
+	for _, result := range results {
+		repo := repositoryReport{
+			Name:       result.Repository.Name,
+			Version:    result.Repository.Version,
+			Success:    result.Success,
+			Files:      make([]fileReport, 0, len(result.FilesUpdated)),
+			TotalBytes: result.TotalBytes(),
+			Copied:     len(result.FilesUpdated) - result.SkippedFiles(),
+			Skipped:    result.SkippedFiles(),
+			Warnings:   result.Warnings,
+			LintPassed: result.LintPassed,
+			Timings: timingsReport{
+				DownloadMs: result.Timings.Download.Milliseconds(),
+				CopyMs:     result.Timings.Copy.Milliseconds(),
+				TotalMs:    result.Timings.Total.Milliseconds(),
+			},
+		}
+		if result.Error != nil {
+			repo.Error = result.Error.Error()
+		}
+		for _, file := range result.FilesUpdated {
+			repo.Files = append(repo.Files, fileReport{
+				Name:    file.Name,
+				Path:    file.Path,
+				Size:    file.Size,
+				Change:  file.Change,
+				Targets: file.Targets,
+			})
+		}
+
+		for _, file := range result.OrphanedFiles {
+			repo.Orphaned = append(repo.Orphaned, file.Path)
+		}
+		for _, file := range result.PrunedFiles {
+			repo.Pruned = append(repo.Pruned, file.Path)
+		}
+
+		report.Success = report.Success && result.Success
+		report.Repositories = append(report.Repositories, repo)
+	}
+
+	return report
+}
+
+func writeSyncReport(w io.Writer, results []domain.SyncResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(buildSyncReport(results)); err != nil {
+		return fmt.Errorf("failed to write JSON summary: %w", err)
+	}
+	return nil
+}
+
+// errorReport is one entry of the --json-errors-only report
+type errorReport struct {
+	Repo    string           `json:"repo,omitempty"`
+	Version string           `json:"version,omitempty"`
+	Code    domain.ErrorCode `json:"code"`
+	Message string           `json:"message"`
+}
+
+func buildErrorReport(results []domain.SyncResult) []errorReport {
+	var reports []errorReport
+	for _, result := range results {
+		if result.Error == nil {
+			continue
+		}
+		reports = append(reports, errorReport{
+			Repo:    result.Repository.Name,
+			Version: result.Repository.Version,
+			Code:    domain.CodeOf(result.Error),
+			Message: result.Error.Error(),
+		})
+	}
+	return reports
+}
+
+// writeErrorReport prints reports as a JSON array, writing nothing when
+// there are no errors
+func writeErrorReport(w io.Writer, reports []errorReport) error {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(reports); err != nil {
+		return fmt.Errorf("failed to write JSON error report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package interfaces
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSyncReport(t *testing.T) {
+	results := []domain.SyncResult{
+		{
+			Repository: domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"},
+			Success:    true,
+			Timings:    domain.SyncTimings{Download: 1500 * time.Millisecond, Copy: 20 * time.Millisecond, Total: 1530 * time.Millisecond},
+			FilesUpdated: []domain.ProtoFile{
+				{Name: "orders.proto", Path: "proto/orders.proto", Size: 42, Change: domain.ChangeAdded},
+				{Name: "users.proto", Path: "proto/users.proto", Size: 8, Change: domain.ChangeUnchanged, Skipped: true},
+			},
+		},
+		{
+			Repository: domain.Repository{Name: "github.com/example/users", Version: "v2.0.0"},
+			Error:      errors.New("failed to download module"),
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSyncReport(&buf, results))
+	assert.JSONEq(t, `{
+		"success": false,
+		"total_bytes": 50,
+		"repositories": [
+			{
+				"name": "github.com/example/api",
+				"version": "v1.0.0",
+				"success": true,
+				"files": [
+					{"name": "orders.proto", "path": "proto/orders.proto", "size": 42, "change": "added"},
+					{"name": "users.proto", "path": "proto/users.proto", "size": 8, "change": "unchanged"}
+				],
+				"total_bytes": 50,
+				"copied": 1,
+				"skipped": 1,
+				"timings": {"download_ms": 1500, "copy_ms": 20, "total_ms": 1530}
+			},
+			{
+				"name": "github.com/example/users",
+				"version": "v2.0.0",
+				"success": false,
+				"error": "failed to download module",
+				"files": [],
+				"total_bytes": 0,
+				"copied": 0,
+				"skipped": 0,
+				"timings": {"download_ms": 0, "copy_ms": 0, "total_ms": 0}
+			}
+		]
+	}`, buf.String())
+}
+
+func TestWriteSyncReportLint(t *testing.T) {
+	passed := false
+	results := []domain.SyncResult{{
+		Repository: domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"},
+		Success:    true,
+		LintPassed: &passed,
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSyncReport(&buf, results))
+	assert.Contains(t, buf.String(), `"lint_passed": false`)
+	assert.Error(t, lintError(results))
+	assert.NoError(t, lintError(results[:0]))
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	assert.NoError(t, validateOutputFormat("text"))
+	assert.NoError(t, validateOutputFormat("json"))
+	assert.Error(t, validateOutputFormat("yaml"))
+}
+
+func TestBuildErrorReport(t *testing.T) {
+	results := []domain.SyncResult{
+		{Repository: domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"}, Success: true},
+		{
+			Repository: domain.Repository{Name: "github.com/example/users", Version: "v2.0.0"},
+			Error:      domain.WithCode(domain.ErrorCodeDownloadFailed, errors.New("failed to download module")),
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeErrorReport(&buf, buildErrorReport(results[:1])))
+	assert.Empty(t, buf.String())
+
+	require.NoError(t, writeErrorReport(&buf, buildErrorReport(results)))
+	assert.JSONEq(t, `[{"repo": "github.com/example/users", "version": "v2.0.0", "code": "download_failed", "message": "failed to download module"}]`, buf.String())
+}
+
+func TestFormatTimings(t *testing.T) {
+	result := domain.SyncResult{
+		Repository: domain.Repository{Name: "github.com/example/api", Version: "v1.0.0"},
+		Timings:    domain.SyncTimings{Download: 1234567 * time.Microsecond, Copy: 8 * time.Millisecond, Total: 1250 * time.Millisecond},
+	}
+	assert.Equal(t, "github.com/example/api@v1.0.0 took 1.25s (download 1.235s, copy 8ms)", formatTimings(result))
+}
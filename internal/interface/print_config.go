@@ -0,0 +1,59 @@
+package interfaces
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// printConfig writes the effective configuration, including environment
+// derived values such as GOMODCACHE, to help debug path resolution issues
+func (c *CLIHandler) printConfig(w io.Writer, config *domain.SyncConfig) error {
+	modCache, err := c.service.ModuleCacheDir()
+	if err != nil {
+		modCache = fmt.Sprintf("<unavailable: %v>", err)
+	}
+
+	repositories := "auto-detect from " + config.GoModPath
+	if len(config.Repositories) > 0 {
+		names := make([]string, len(config.Repositories))
+		for i, repo := range config.Repositories {
+			var overrides []string
+			if repo.SourcePath != "" {
+				overrides = append(overrides, "source: "+repo.SourcePath)
+			}
+			if repo.TargetPath != "" {
+				overrides = append(overrides, "target: "+repo.TargetPath)
+			}
+			names[i] = repo.Name
+			if repo.Version != "" {
+				names[i] += "@" + repo.Version
+			}
+			if len(overrides) > 0 {
+				names[i] += " (" + strings.Join(overrides, ", ") + ")"
+			}
+		}
+		repositories = strings.Join(names, ", ")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "go.mod:\t%s\n", config.GoModPath)
+	fmt.Fprintf(tw, "buf.yaml:\t%s\n", config.BufYamlPath)
+	fmt.Fprintf(tw, "source path:\t%s\n", config.SourcePath)
+	fmt.Fprintf(tw, "repositories:\t%s\n", repositories)
+	if config.SpecifiedVersion != "" {
+		fmt.Fprintf(tw, "version:\t%s\n", config.SpecifiedVersion)
+	}
+	if config.Netrc != "" {
+		fmt.Fprintf(tw, "netrc:\t%s\n", config.Netrc)
+	}
+	if config.Token != "" {
+		fmt.Fprintf(tw, "token:\t[REDACTED]\n")
+	}
+	fmt.Fprintf(tw, "GOMODCACHE:\t%s\n", modCache)
+
+	return tw.Flush()
+}
@@ -0,0 +1,94 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+)
+
+// jsonProgressReporter streams progress events as newline-delimited JSON.
+// Write errors are ignored so a closed dashboard pipe never fails a sync.
+type jsonProgressReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newJSONProgressReporter(w io.Writer) *jsonProgressReporter {
+	return &jsonProgressReporter{enc: json.NewEncoder(w)}
+}
+
+func (r *jsonProgressReporter) Report(event domain.ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(event)
+}
+
+// openProgressReporter opens the --progress-file or --progress-fd
+// destination. The returned close function must be called when the sync
+// finishes; both are nil when progress reporting is disabled.
+func (c *CLIHandler) openProgressReporter() (domain.ProgressReporter, func() error, error) {
+	switch {
+	case c.output.progressFile != "" && c.output.progressFD != 0:
+		return nil, nil, fmt.Errorf("--progress-file and --progress-fd are mutually exclusive")
+	case c.output.progressFile != "":
+		file, err := os.Create(c.output.progressFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open progress file: %w", err)
+		}
+		return newJSONProgressReporter(file), file.Close, nil
+	case c.output.progressFD != 0:
+		if c.output.progressFD < 0 {
+			return nil, nil, fmt.Errorf("invalid --progress-fd %d", c.output.progressFD)
+		}
+		file := os.NewFile(uintptr(c.output.progressFD), "progress")
+		if file == nil {
+			return nil, nil, fmt.Errorf("invalid --progress-fd %d", c.output.progressFD)
+		}
+		return newJSONProgressReporter(file), file.Close, nil
+	default:
+		return nil, nil, nil
+	}
+}
+
+// multiProgressReporter forwards events to several reporters
+type multiProgressReporter []domain.ProgressReporter
+
+func (m multiProgressReporter) Report(event domain.ProgressEvent) {
+	for _, r := range m {
+		r.Report(event)
+	}
+}
+
+// SetTerminalProgress sets the live progress display used while syncing
+// unless --no-progress is given; main only sets it when stderr is a terminal
+func (c *CLIHandler) SetTerminalProgress(reporter domain.ProgressReporter) {
+	c.terminalProgress = reporter
+}
+
+// progressReporters combines the JSON progress stream with the terminal
+// display, returning nil when neither is enabled
+func (c *CLIHandler) progressReporters(stream domain.ProgressReporter, dryRun bool) (domain.ProgressReporter, bool) {
+	var reporters multiProgressReporter
+	if stream != nil {
+		reporters = append(reporters, stream)
+	}
+
+	// Dry runs log their preview and copy nothing
+	bar := c.terminalProgress != nil && !c.output.noProgress && !dryRun
+	if bar {
+		reporters = append(reporters, c.terminalProgress)
+	}
+
+	switch len(reporters) {
+	case 0:
+		return nil, false
+	case 1:
+		return reporters[0], bar
+	default:
+		return reporters, bar
+	}
+}
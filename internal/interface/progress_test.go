@@ -0,0 +1,64 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONProgressReporter(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := newJSONProgressReporter(&buf)
+
+	success := true
+	reporter.Report(domain.ProgressEvent{Event: domain.ProgressRepoStart, Repo: "github.com/example/api", Version: "v1.0.0"})
+	reporter.Report(domain.ProgressEvent{Event: domain.ProgressFileCopied, Name: "orders.proto", Change: domain.ChangeAdded})
+	reporter.Report(domain.ProgressEvent{Event: domain.ProgressRepoDone, Repo: "github.com/example/api", Success: &success})
+
+	assert.Equal(t, `{"event":"repo_start","repo":"github.com/example/api","version":"v1.0.0"}
+{"event":"file_copied","name":"orders.proto","change":"added"}
+{"event":"repo_done","repo":"github.com/example/api","success":true}
+`, buf.String())
+}
+
+func TestOpenProgressReporterExclusive(t *testing.T) {
+	handler := &CLIHandler{output: outputOptions{progressFile: "progress.jsonl", progressFD: 3}}
+	_, _, err := handler.openProgressReporter()
+	assert.Error(t, err)
+}
+
+// recordingReporter collects the events it receives
+type recordingReporter struct {
+	events []domain.ProgressEvent
+}
+
+func (r *recordingReporter) Report(event domain.ProgressEvent) {
+	r.events = append(r.events, event)
+}
+
+func TestProgressReporters(t *testing.T) {
+	stream, terminal := &recordingReporter{}, &recordingReporter{}
+	handler := &CLIHandler{}
+
+	reporter, bar := handler.progressReporters(nil, false)
+	assert.Nil(t, reporter)
+	assert.False(t, bar)
+
+	handler.SetTerminalProgress(terminal)
+	reporter, bar = handler.progressReporters(stream, false)
+	assert.True(t, bar)
+	reporter.Report(domain.ProgressEvent{Event: domain.ProgressFileCopied, Copied: 1, Total: 1})
+	assert.Len(t, stream.events, 1)
+	assert.Len(t, terminal.events, 1)
+
+	reporter, bar = handler.progressReporters(stream, true)
+	assert.Equal(t, stream, reporter)
+	assert.False(t, bar)
+
+	handler.output.noProgress = true
+	reporter, bar = handler.progressReporters(nil, false)
+	assert.Nil(t, reporter)
+	assert.False(t, bar)
+}
@@ -0,0 +1,45 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (c *CLIHandler) createRollbackCommand() *cobra.Command {
+	var config domain.SyncConfig
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore the target protos from the most recent --backup",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			c.writeLogBanner(cmd, nil)
+			return c.handleRollback(cmd.Context(), &config)
+		},
+	}
+	cmd.Flags().StringVarP(&config.BufYamlPath, "buf-yaml", "b", getEnvOrDefault("BUF_YAML_PATH", "buf.yaml"), "Path to buf.yaml file")
+	cmd.Flags().StringArrayVar(&config.Targets, "target", nil, "Target directory to restore, overriding buf.yaml (repeatable)")
+	cmd.Flags().StringVar(&config.Module, "module", "", "buf.yaml module (name or path) to restore")
+	cmd.Flags().StringVar(&config.DefaultModulePath, "default-module-path", "", "Target path for buf v1 files without modules (default: the buf.yaml directory)")
+	cmd.Flags().StringVar(&config.BackupDir, "backup-dir", "", "Directory holding timestamped backups (default: <target>/.proto-sync-backup)")
+
+	return cmd
+}
+
+func (c *CLIHandler) handleRollback(ctx context.Context, config *domain.SyncConfig) error {
+	results, err := c.service.Rollback(ctx, config)
+	if err != nil {
+		c.logger.Error("Rollback failed: %v", err)
+		return err
+	}
+
+	for _, result := range results {
+		c.logger.Plain("Restored %d file(s) into %s from %s:", len(result.Files), result.Target, result.BackupDir)
+		for _, file := range result.Files {
+			c.logger.Plain("  - %s", file)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package interfaces
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sinceUnits are the duration units --since accepts on top of
+// time.ParseDuration's
+var sinceUnits = map[byte]time.Duration{
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseSince turns a --since value into the cutoff time. It accepts an
+// RFC3339 timestamp, a date in UTC or a duration before now, where a
+// leading count of days (d) or weeks (w) may precede a Go duration, as in
+// 1d12h.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	ago, err := parseSinceDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: want an RFC3339 time, a date such as 2006-01-02 or a duration such as 24h or 7d", value)
+	}
+	if ago <= 0 {
+		return time.Time{}, fmt.Errorf("invalid --since %q: the duration must be positive", value)
+	}
+	return now.Add(-ago), nil
+}
+
+// parseSinceDuration parses an optional days or weeks count followed by an
+// optional time.ParseDuration remainder
+func parseSinceDuration(value string) (time.Duration, error) {
+	digits := len(value) - len(strings.TrimLeft(value, "0123456789"))
+	if digits == 0 || digits == len(value) {
+		return time.ParseDuration(value)
+	}
+
+	unit, ok := sinceUnits[value[digits]]
+	if !ok {
+		return time.ParseDuration(value)
+	}
+	count, err := strconv.ParseInt(value[:digits], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if count > int64(time.Duration(1<<63-1)/unit) {
+		return 0, fmt.Errorf("duration %q is too large", value)
+	}
+
+	ago := time.Duration(count) * unit
+	if rest := value[digits+1:]; rest != "" {
+		extra, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, err
+		}
+		if extra < 0 || ago > time.Duration(1<<63-1)-extra {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		ago += extra
+	}
+	return ago, nil
+}
@@ -0,0 +1,36 @@
+package interfaces
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-05-01T08:30:00+02:00", time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+		{"1d12h", now.Add(-36 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		require.NoError(t, err, tt.value)
+		assert.True(t, tt.want.Equal(got), "%s: got %s", tt.value, got)
+	}
+
+	for _, value := range []string{"", "yesterday", "7", "-24h", "0s", "3x", "1d-2h", "99999999999w"} {
+		_, err := parseSince(value, now)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,65 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+func (c *CLIHandler) createValidateCommand() *cobra.Command {
+	var config domain.SyncConfig
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Download the source protos and check their syntax without touching the targets",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return c.handleValidate(cmd.Context(), &config)
+		},
+	}
+	c.addFlags(cmd, &config)
+
+	return cmd
+}
+
+func (c *CLIHandler) handleValidate(ctx context.Context, config *domain.SyncConfig) error {
+	if err := c.validateRequiredTools("go"); err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, config)
+	defer cancel()
+
+	validations, err := c.service.Validate(ctx, config)
+	if err != nil {
+		c.logger.Error("Validation failed: %v", err)
+		return err
+	}
+
+	failed := writeValidations(os.Stdout, validations)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d proto file(s) failed validation", failed, len(validations))
+	}
+
+	c.logger.Success("All %d proto file(s) passed validation", len(validations))
+	return nil
+}
+
+// writeValidations prints a pass/fail line per file, returning the number
+// of failed files
+func writeValidations(w io.Writer, validations []domain.FileValidation) int {
+	failed := 0
+	for _, validation := range validations {
+		if validation.Error != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: %v\n", validation.Path, validation.Error)
+			continue
+		}
+		fmt.Fprintf(w, "ok   %s\n", validation.Path)
+	}
+	return failed
+}
@@ -0,0 +1,65 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// defaultManifestFile is the manifest verify-manifest reads by default
+const defaultManifestFile = "proto-sync.manifest.json"
+
+func (c *CLIHandler) createVerifyManifestCommand() *cobra.Command {
+	manifestPath := defaultManifestFile
+
+	cmd := &cobra.Command{
+		Use:   "verify-manifest",
+		Short: "Check target files against a --manifest; exits 8 when any were edited or deleted",
+		Long:  "Recompute the SHA-256 of every target file recorded in a manifest written by --manifest and report files that were edited or deleted since that sync.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return c.handleVerifyManifest(cmd.Context(), manifestPath)
+		},
+	}
+	cmd.Flags().StringVar(&manifestPath, "manifest", defaultManifestFile, "Manifest file written by a previous sync with --manifest")
+
+	return cmd
+}
+
+func (c *CLIHandler) handleVerifyManifest(ctx context.Context, manifestPath string) error {
+	drift, err := c.service.VerifyManifest(ctx, manifestPath)
+	if err != nil {
+		c.logger.Error("Verifying manifest failed: %v", err)
+		return err
+	}
+
+	if len(drift) == 0 {
+		c.logger.Success("All target files match %s", manifestPath)
+		return nil
+	}
+
+	if err := writeManifestDrift(os.Stdout, drift); err != nil {
+		return err
+	}
+	return &ExitError{Code: ExitManifestDrift, Err: fmt.Errorf("%d target file(s) drifted from %s", len(drift), manifestPath)}
+}
+
+// writeManifestDrift prints one table row per drifted file
+func writeManifestDrift(w io.Writer, drift []domain.ManifestDrift) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tFILE\tREPOSITORY")
+	for _, file := range drift {
+		status := "modified"
+		if file.Missing {
+			status = "missing"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", status, file.Path, file.Repository)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Francouer/proto-sync/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteManifestDrift(t *testing.T) {
+	drift := []domain.ManifestDrift{
+		{Repository: "github.com/example/api", Path: "proto/orders.proto", Expected: "aa", Actual: "bb"},
+		{Repository: "github.com/example/api", Path: "proto/v1/users.proto", Missing: true, Expected: "cc"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeManifestDrift(&buf, drift))
+	assert.Equal(t, `STATUS    FILE                  REPOSITORY
+modified  proto/orders.proto    github.com/example/api
+missing   proto/v1/users.proto  github.com/example/api
+`, buf.String())
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ChangeWorkDir switches to the directory given by --work-dir in args,
+// before anything resolves a relative path, and returns its absolute form.
+// Like LogFormat it scans args directly so --log-file is resolved against
+// the new directory too. It returns an empty string without --work-dir.
+func ChangeWorkDir(args []string) (string, error) {
+	dir := scanFlag(args, "work-dir", "")
+	if dir == "" {
+		return "", nil
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		return "", fmt.Errorf("invalid --work-dir: %w", err)
+	}
+	absDir, err := filepath.Abs(".")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve --work-dir %s: %w", dir, err)
+	}
+	return absDir, nil
+}
@@ -0,0 +1,36 @@
+package interfaces
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeWorkDir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	dir, err := ChangeWorkDir([]string{"--dry-run"})
+	require.NoError(t, err)
+	assert.Empty(t, dir)
+
+	target, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(target, "service"), 0o755))
+
+	dir, err = ChangeWorkDir([]string{"--work-dir", filepath.Join(target, "service")})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(target, "service"), dir)
+
+	// Relative paths resolve against the current directory
+	dir, err = ChangeWorkDir([]string{"--work-dir=.."})
+	require.NoError(t, err)
+	assert.Equal(t, target, dir)
+
+	_, err = ChangeWorkDir([]string{"--work-dir", filepath.Join(target, "missing")})
+	assert.ErrorContains(t, err, "invalid --work-dir")
+}